@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// promptProcessor processes prompts, e.g. the agent
+type promptProcessor interface {
+	ProcessPrompt(prompt string) (string, error)
+}
+
+// batchSettings holds the output settings of a batch run
+type batchSettings struct {
+	rawOutput     bool // Write the responses as is instead of rendering them as markdown
+	quiet         bool
+	maxIterations int
+	maxBytes      int // Truncate the responses longer than this number of bytes, 0 for no limit
+}
+
+// readBatch reads the prompts of the batch file, one per line, skipping blank lines
+func readBatch(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("batch file %s not found", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var prompts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if prompt := strings.TrimSpace(scanner.Text()); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s: %v", path, err)
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompt in batch file %s", path)
+	}
+
+	return prompts, nil
+}
+
+// runBatch processes the prompts one after the other, writing a separator with the prompt then the response
+// of each one to out, and the errors to errOut. A failed prompt does not stop the batch, the number of failed
+// prompts is returned
+func runBatch(p promptProcessor, prompts []string, out, errOut io.Writer, settings batchSettings) int {
+	failed := 0
+	for i, prompt := range prompts {
+		if settings.quiet {
+			fmt.Fprintf(out, "==> [%d/%d] %s\n", i+1, len(prompts), prompt)
+		} else {
+			fmt.Fprintln(out, resultHeaderStyle.Render(fmt.Sprintf("📨 [%d/%d] %s", i+1, len(prompts), prompt)))
+		}
+
+		response, err := p.ProcessPrompt(prompt)
+		if err != nil {
+			failed++
+			fmt.Fprintf(errOut, "❌ Error processing prompt %d: %s\n", i+1, promptErrorMessage(err, settings.maxIterations))
+			continue
+		}
+
+		writeResponse(out, truncateResponse(response, settings.maxBytes), settings.rawOutput)
+	}
+
+	if !settings.quiet {
+		fmt.Fprintf(errOut, "📋 Batch completed: %d of %d prompts succeeded\n", len(prompts)-failed, len(prompts))
+	}
+	return failed
+}
+
+// writeResponse writes the response to w, as is or rendered as markdown
+func writeResponse(w io.Writer, response string, rawOutput bool) {
+	if rawOutput {
+		writeRawResponse(w, response)
+		return
+	}
+
+	renderedResponse, err := renderMarkdown(response)
+	if err != nil {
+		// Fall back to plain text if rendering fails
+		fmt.Fprintln(w, "📄 "+response)
+		return
+	}
+	fmt.Fprintln(w, lipgloss.NewStyle().MarginLeft(1).Render(renderedResponse))
+}
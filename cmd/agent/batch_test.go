@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeProcessor records the prompts it processes and fails on the given ones
+type fakeProcessor struct {
+	prompts []string
+	failing map[string]bool
+}
+
+func (p *fakeProcessor) ProcessPrompt(prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if p.failing[prompt] {
+		return "", errors.New("error running agent executor: boom")
+	}
+	return "Found 1 employees:\n\n1. John Doe", nil
+}
+
+func TestBatch(t *testing.T) {
+	batchPath := filepath.Join(t.TempDir(), "prompts.txt")
+	if err := os.WriteFile(batchPath, []byte("Who is Jane Roe?\n\n  Who is John Doe?  \n"), 0644); err != nil {
+		t.Fatalf("Error writing batch file: %v", err)
+	}
+
+	prompts, err := readBatch(batchPath)
+	if err != nil {
+		t.Fatalf("Error reading batch file: %v", err)
+	}
+	if strings.Join(prompts, "|") != "Who is Jane Roe?|Who is John Doe?" {
+		t.Fatalf("Expected 2 prompts, got %q", prompts)
+	}
+
+	// The first prompt fails, the second one is still processed
+	processor := &fakeProcessor{failing: map[string]bool{"Who is Jane Roe?": true}}
+	var stdout, stderr bytes.Buffer
+	failed := runBatch(processor, prompts, &stdout, &stderr, batchSettings{rawOutput: true, quiet: true})
+
+	if len(processor.prompts) != 2 {
+		t.Errorf("Expected both prompts to be attempted, got %q", processor.prompts)
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 failed prompt, got %d", failed)
+	}
+	expected := "==> [1/2] Who is Jane Roe?\n==> [2/2] Who is John Doe?\nFound 1 employees:\n\n1. John Doe\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Error processing prompt 1: error running agent executor: boom") {
+		t.Errorf("Expected the error of the first prompt, got %q", stderr.String())
+	}
+
+	if _, err := readBatch(filepath.Join(t.TempDir(), "missing.txt")); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
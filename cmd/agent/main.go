@@ -2,15 +2,34 @@ package main
 
 import (
 	"bufio"
+	"cmp"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/asaintsever/ama-employees-ai-agent/pkg/agent"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/misc"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+	jsonquery "github.com/asaintsever/ama-employees-ai-agent/pkg/tools/json"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/tools/slack"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/version"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+	"golang.org/x/text/language"
 )
 
 // Define styles for the terminal UI
@@ -68,27 +87,368 @@ var boxStyle = lipgloss.NewStyle().
 	MarginTop(1).
 	MarginBottom(1)
 
+// colorDisabled is set when the colors and the styling are disabled, see disableColor
+var colorDisabled bool
+
+// shouldDisableColor tells whether to disable the colors: with -no-color, with the NO_COLOR environment variable
+// (see https://no-color.org) or when stdout is not a terminal (e.g. piped to a log file)
+func shouldDisableColor(noColor bool, getenv func(string) string, isTerminal bool) bool {
+	return noColor || getenv("NO_COLOR") != "" || !isTerminal
+}
+
+// slackTokens returns the Slack token listing the users (SLACK_BOT_TOKEN) and the one of the operations needing a user
+// token (SLACK_USER_TOKEN), i.e. the admin API and the presence. SLACK_TOKEN is the fallback of both, and either one
+// falls back to the other when only one is set. found is false when no token is set
+func slackTokens(getenv func(string) string) (botToken, userToken string, found bool) {
+	botToken = cmp.Or(getenv("SLACK_BOT_TOKEN"), getenv("SLACK_TOKEN"))
+	userToken = cmp.Or(getenv("SLACK_USER_TOKEN"), getenv("SLACK_TOKEN"))
+	if botToken == "" && userToken == "" {
+		return "", "", false
+	}
+	return cmp.Or(botToken, userToken), cmp.Or(userToken, botToken), true
+}
+
+// disableColor renders plain strings: no colors nor escape sequences, and no boxes around the messages
+// which keep their emoji prefix (e.g. "❌ ERROR:"). Markdown is rendered with the style of glamour for non terminals
+func disableColor() {
+	colorDisabled = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	plain := lipgloss.NewStyle()
+	titleStyle, subtitleStyle, highlightStyle, successStyle = plain, plain, plain, plain
+	errorStyle, warningStyle, promptStyle = plain, plain, plain
+	resultHeaderStyle, boxStyle = plain, plain
+}
+
+// defaultEmptyResultHint is shown in interactive mode when a query returns no employees
+const defaultEmptyResultHint = "💡 Try a broader filter, check the spelling of names, or ask for fresh employees data."
+
 func main() {
 	// Define command-line flags
-	promptFlag := flag.String("prompt", "", "Prompt to process (non-interactive mode)")
+	promptFlag := flag.String("prompt", "", "Prompt to process (non-interactive mode), \"-\" to read it from stdin")
+	outFlag := flag.String("out", "", "Write the response of -prompt, -prompt-file or -query to the file at this path instead of stdout, in the -output format (markdown source for markdown)")
+	batchFlag := flag.String("batch", "", "File of prompts to process one after the other, one per line (non-interactive mode)")
+	promptFileFlag := flag.String("prompt-file", "", "File of the prompt to process (non-interactive mode), e.g. for multi-line prompts")
+	versionFlag := flag.Bool("version", false, "Print the version, git commit and build date, then exit")
+	checkFlag := flag.Bool("check", false, "Check that the Slack token is valid and that the LLM can be invoked, then exit, with an error if any check failed (readiness probe, the employees are not fetched)")
 	quietFlag := flag.Bool("quiet", false, "Minimal output, only show response (for scripting)")
+	noColorFlag := flag.Bool("no-color", false, "Disable colors and styling, also disabled with the NO_COLOR environment variable or when stdout is not a terminal")
+	outputFlag := flag.String("output", string(agent.OutputMarkdown), "Format of the responses: markdown (rendered), plain (not rendered), json or csv (results as returned by the JSON query tool, with -quiet only the response is written to stdout), or xlsx (results written to the Excel file given with -out, numbered after the first prompt)")
+	llmFlag := flag.String("llm", string(agent.ProviderBedrock), "Provider of the LLM: bedrock, openai (with OPENAI_API_KEY) or ollama")
+	modelFlag := flag.String("model", "", "Model of the LLM provider (default \""+agent.ModelID+"\" for bedrock, \""+agent.DefaultModels[agent.ProviderOpenAI]+"\" for openai, \""+agent.DefaultModels[agent.ProviderOllama]+"\" for ollama)")
+	awsRegionFlag := flag.String("aws-region", "", "AWS region of Bedrock (e.g. eu-west-3), the region of the AWS configuration by default")
+	promptTemplateFlag := flag.String("prompt-template", "", "File of the Go template replacing the beginning of the agent prompt (tone and instructions), with the {{.tool_descriptions}} placeholder and optionally {{.today}}")
+	temperatureFlag := flag.Float64("temperature", -1, "Temperature of the LLM between 0 and 1, e.g. 0 for deterministic scripted runs (negative for the temperature of the model)")
+	maxTokensFlag := flag.Int("max-tokens", 0, "Maximum number of tokens generated by the LLM per call, e.g. higher for large listings (0 for the maximum of the model)")
+	maxIterationsFlag := flag.Int("max-iterations", agent.DefaultMaxIterations, "Maximum number of reasoning steps of the agent for a prompt, raise it for complex multi-step prompts (at least 1)")
+	timeoutFlag := flag.Duration("timeout", agent.DefaultTimeout, "Maximum duration of the processing of a prompt, LLM calls and Slack fetches included (0 for no timeout)")
+	streamFlag := flag.Bool("stream", false, "Print the responses as they are generated, without markdown rendering")
+	noMemoryFlag := flag.Bool("no-memory", false, "Process each prompt of the interactive mode without the previous ones (for scripted use)")
 	debugFlag := flag.Bool("debug", false, "Enable debug output to see agent's decision-making process")
+	exportSQLiteFlag := flag.String("export-sqlite", "", "Export query results to the SQLite database at this path")
+	exportSQLiteAppendFlag := flag.Bool("export-sqlite-append", false, "Append to the existing SQLite employees table instead of replacing it")
+	emptyHintFlag := flag.String("empty-hint", defaultEmptyResultHint, "Hint shown in interactive mode when a query returns no employees (empty to disable)")
+	redactPathsFlag := flag.Bool("redact-paths", false, "Return data file paths relative to the working directory instead of absolute paths")
+	dataDirFlag := flag.String("data-dir", os.Getenv("AMA_EMPLOYEES_DATA_DIR"), "Directory of the employees data files fetched from Slack (default $AMA_EMPLOYEES_DATA_DIR, or ama-employees under the cache directory of the user)")
+	retentionFlag := flag.Duration("retention", slack.DefaultRetention, "Age after which the employees data files are removed when a new one is written (0 to keep them, e.g. for the headcount trend)")
+	maxDataFilesFlag := flag.Int("max-data-files", 0, "Maximum number of employees data files kept per filter type (all, active, deactivated), 0 for no limit")
+	dropScrubbedFlag := flag.Bool("drop-scrubbed", false, "Drop deactivated employees without email from the results")
+	seniorityLevelsFlag := flag.String("seniority-levels", "", "Comma-separated seniority levels from most junior to most senior (default \"junior,mid,senior,staff,principal,lead\")")
+	jsonFieldsFlag := flag.String("json-fields", "", "Comma-separated employee fields written by the JSON and NDJSON outputs (e.g. \"first_name,last_name,title\"), all fields by default")
+	jsonExcludeFieldsFlag := flag.String("json-exclude-fields", "", "Comma-separated employee fields omitted from the JSON and NDJSON outputs (e.g. \"email\")")
+	jsonCompactFlag := flag.Bool("json-compact", false, "Write the JSON outputs compacted on a single line instead of indented (for logging and piping)")
+	nameParticlesFlag := flag.String("name-particles", "", "Comma-separated particles kept with the last name when splitting full names (default \"van,von,der,den,de,la,le,del,della,da,di,du,bin,ibn\")")
+	imageSizeFlag := flag.Int("image-size", slack.DefaultImageSize, "Size in pixels of the profile pictures written to the data files: 24, 32, 48, 72, 192 or 512")
+	progressBarFlag := flag.Bool("progress-bar", true, "Show a progress bar instead of a spinner while fetching users, when their number is known from a previous fetch (terminal only)")
+	enterpriseFlag := flag.Bool("enterprise", false, "List the users of all the workspaces of the Enterprise Grid organization (requires an org-level token)")
+	cacheTTLFlag := flag.Duration("cache-ttl", slack.DefaultCacheTTL, "Age under which the data file of a previous Slack search with the same filter is reused instead of fetching the employees again (0 to disable)")
+	pageSizeFlag := flag.Int("page-size", slack.DefaultPageSize, "Number of users fetched per page from Slack")
+	maxPagesFlag := flag.Int("max-pages", slack.DefaultMaxPages, "Maximum number of pages of users fetched from Slack, users beyond are missing from the results")
+	maxResponseBytesFlag := flag.Int("max-response-bytes", 0, "Truncate responses longer than this number of bytes before rendering them (0 for no limit)")
+	summaryFlag := flag.Bool("summary", false, "Print a one-line summary of the run to stderr in non-interactive mode")
+	dateFormatFlag := flag.String("date-format", model.DefaultDateFormat, "Go layout of the deactivation dates (e.g. \"02/01/2006\" or \"2006-01-02T15:04:05Z07:00\" for RFC3339)")
+	timezoneFlag := flag.String("timezone", "", "IANA time zone of the current date for relative queries such as \"this month\" (e.g. Europe/Paris), the local one by default")
+	collationLocaleFlag := flag.String("collation-locale", "", "Locale used to sort employee names alphabetically (e.g. sv, de), locale neutral by default")
+	queryFlag := flag.String("query", "", "Query to run directly on the employees data file given with -file, without the LLM nor Slack (e.g. \"deactivated in 2023\")")
+	fileFlag := flag.String("file", "", "Employees data file queried by -query, or returned by the Slack tool with -dry-run (e.g. ~/.cache/ama-employees/employees-all-20240501-103000.json)")
+	dryRunFlag := flag.Bool("dry-run", false, "Show the tool calls planned by the LLM without calling Slack, the Slack tool returning the data file given with -file (a sample dataset by default). The LLM is still called, with its credentials and costs")
+	strictJSONFlag := flag.Bool("strict-json", false, "Reject the employees data files with fields unknown to this version instead of ignoring them (e.g. in CI)")
+	staleAfterFlag := flag.Duration("stale-after", jsonquery.DefaultStaleAfter, "Age after which employees data files are considered stale and a warning is shown (0 to disable, never shown in quiet mode)")
+	fuzzyMaxDistanceFlag := flag.Int("fuzzy-max-distance", jsonquery.DefaultFuzzyMaxDistance, "Maximum edit distance of the approximate name matches when no employee matches a name exactly (0 to disable)")
+	exportCSVFlag := flag.String("export-csv", "", "Export query results as CSV to the file at this path")
 
 	// Parse command-line flags
 	flag.Parse()
 
-	// Get Slack token from environment
-	slackToken := os.Getenv("SLACK_TOKEN")
-	if slackToken == "" {
+	// Print the build metadata without initializing anything, no Slack token nor AWS credentials needed
+	if *versionFlag {
+		fmt.Println(version.String())
+		os.Exit(0)
+	}
+
+	if shouldDisableColor(*noColorFlag, os.Getenv, term.IsTerminal(int(os.Stdout.Fd()))) {
+		disableColor()
+	}
+
+	// No spinners nor progress bars in quiet mode, their control characters would corrupt captured output
+	if *quietFlag {
+		misc.SetQuiet(true)
+	}
+
+	// Read the prompt from a file or from stdin if asked to
+	if *promptFileFlag != "" || *promptFlag == "-" {
+		prompt, err := readPrompt(*promptFlag, *promptFileFlag, os.Stdin)
+		if err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid prompt:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+		*promptFlag = prompt
+	}
+
+	// Read the prompts of the batch file, processed independently from each other
+	var batchPrompts []string
+	if *batchFlag != "" {
+		var err error
+		if *promptFlag != "" {
+			err = errors.New("-batch cannot be used with -prompt nor -prompt-file")
+		} else {
+			batchPrompts, err = readBatch(*batchFlag)
+		}
+		if err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid batch:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+	}
+
+	if *outFlag != "" && (*promptFlag == "" && *queryFlag == "" || *batchFlag != "") {
+		errorMsg := errorStyle.Render("❌ ERROR: invalid output file:") + "\n" + "-out requires -prompt, -prompt-file or -query"
+		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+		fmt.Fprintln(os.Stderr, errorBox)
+		os.Exit(1)
+	}
+
+	// Reject malformed Bedrock model IDs
+	if agent.Provider(*llmFlag) == agent.ProviderBedrock && *modelFlag != "" {
+		if err := agent.ValidateBedrockModelID(*modelFlag); err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid model:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+	}
+
+	// Read the template replacing the beginning of the agent prompt, rejected early if invalid
+	promptTemplate := ""
+	if *promptTemplateFlag != "" {
+		data, err := os.ReadFile(*promptTemplateFlag)
+		if err == nil {
+			promptTemplate = string(data)
+			err = agent.ValidatePromptTemplate(promptTemplate)
+		}
+		if err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid prompt template:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+	}
+
+	// Reject generation settings out of range, a negative temperature selecting the one of the model
+	if *temperatureFlag > 1 || *maxTokensFlag < 0 {
+		errorMsg := errorStyle.Render("❌ ERROR: invalid generation settings:") + "\n" +
+			fmt.Sprintf("-temperature (%v) must be between 0 and 1 and -max-tokens (%d) must not be negative", *temperatureFlag, *maxTokensFlag)
+		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+		fmt.Fprintln(os.Stderr, errorBox)
+		os.Exit(1)
+	}
+
+	// The xlsx output is written by the JSON query tool to the -out workbook, the responses being shown in plain text
+	xlsxOut := ""
+	if strings.EqualFold(*outputFlag, "xlsx") {
+		if !strings.EqualFold(filepath.Ext(*outFlag), ".xlsx") {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid output file:") + "\n" + "-output xlsx requires -out with an .xlsx file, e.g. -out employees.xlsx"
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+		xlsxOut, *outFlag, *outputFlag = *outFlag, "", string(agent.OutputPlain)
+	}
+
+	outputFormat := agent.OutputFormat(strings.ToLower(*outputFlag))
+	if !slices.Contains(agent.OutputFormats, outputFormat) {
+		errorMsg := errorStyle.Render("❌ ERROR: invalid output format:") + "\n" +
+			fmt.Sprintf("%q is not one of markdown, plain, json, csv or xlsx", *outputFlag)
+		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+		fmt.Fprintln(os.Stderr, errorBox)
+		os.Exit(1)
+	}
+
+	// Keep stdout clean for scripts (e.g. piping JSON into jq): only the responses are written to it,
+	// the messages of the agent go to stderr (the progress messages of the tools are not written in quiet mode)
+	rawOutput := outputFormat != agent.OutputMarkdown
+	stdout := os.Stdout
+	logOutput := io.Writer(os.Stdout)
+	if *quietFlag && rawOutput {
+		logOutput = os.Stderr
+	}
+
+	// Store the data files outside of the working directory unless told otherwise
+	dataDir := *dataDirFlag
+	if dataDir == "" {
+		dataDir = slack.DefaultDataDir()
+	}
+
+	// Collect the JSON query tool options from flags
+	queryOpts := []jsonquery.Option{jsonquery.WithDataDir(dataDir)}
+	if outputFormat == agent.OutputJSON || outputFormat == agent.OutputCSV {
+		queryOpts = append(queryOpts, jsonquery.WithDefaultFormat(string(outputFormat)))
+	}
+	if *debugFlag {
+		queryOpts = append(queryOpts, jsonquery.WithTimings(true))
+	}
+
+	if *exportSQLiteFlag != "" {
+		queryOpts = append(queryOpts, jsonquery.WithSQLiteExport(*exportSQLiteFlag, *exportSQLiteAppendFlag))
+	}
+
+	if *quietFlag {
+		queryOpts = append(queryOpts, jsonquery.WithStaleAfter(0), jsonquery.WithLogOutput(io.Discard))
+	} else {
+		queryOpts = append(queryOpts, jsonquery.WithStaleAfter(*staleAfterFlag), jsonquery.WithLogOutput(logOutput))
+	}
+
+	if *exportCSVFlag != "" {
+		queryOpts = append(queryOpts, jsonquery.WithCSVExport(*exportCSVFlag))
+	}
+
+	if xlsxOut != "" {
+		queryOpts = append(queryOpts, jsonquery.WithXLSXExport(xlsxOut))
+	}
+
+	if *dropScrubbedFlag {
+		queryOpts = append(queryOpts, jsonquery.WithDropScrubbed(true))
+	}
+
+	if *seniorityLevelsFlag != "" {
+		queryOpts = append(queryOpts, jsonquery.WithSeniorityLevels(strings.Split(*seniorityLevelsFlag, ",")))
+	}
+
+	if *collationLocaleFlag != "" {
+		locale, err := language.Parse(*collationLocaleFlag)
+		if err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid collation locale:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+		queryOpts = append(queryOpts, jsonquery.WithCollationLocale(locale))
+	}
+
+	if *dateFormatFlag != model.DefaultDateFormat {
+		if err := jsonquery.ValidateDateFormat(*dateFormatFlag); err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid date format:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+		queryOpts = append(queryOpts, jsonquery.WithDateFormat(*dateFormatFlag))
+	}
+
+	// Time zone of the current date, for the relative date ranges of the queries and the date given to the LLM
+	var location *time.Location
+	if *timezoneFlag != "" {
+		var err error
+		if location, err = time.LoadLocation(*timezoneFlag); err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid time zone:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+		queryOpts = append(queryOpts, jsonquery.WithLocation(location))
+	}
+
+	if *strictJSONFlag {
+		queryOpts = append(queryOpts, jsonquery.WithStrictDecoding(true))
+	}
+
+	if *fuzzyMaxDistanceFlag != jsonquery.DefaultFuzzyMaxDistance {
+		queryOpts = append(queryOpts, jsonquery.WithFuzzyMaxDistance(*fuzzyMaxDistanceFlag))
+	}
+
+	if *jsonCompactFlag {
+		queryOpts = append(queryOpts, jsonquery.WithCompactJSON(true))
+	}
+
+	if *jsonFieldsFlag != "" || *jsonExcludeFieldsFlag != "" {
+		include, exclude := splitFields(*jsonFieldsFlag), splitFields(*jsonExcludeFieldsFlag)
+		if err := jsonquery.ValidateJSONFields(append(include, exclude...)); err != nil {
+			errorMsg := errorStyle.Render("❌ ERROR: invalid JSON fields:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+		queryOpts = append(queryOpts, jsonquery.WithJSONFields(include, exclude))
+	}
+
+	// Split wide markdown tables so they fit the terminal, unless written to a file
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 && *outFlag == "" {
+		queryOpts = append(queryOpts, jsonquery.WithMaxTableWidth(width))
+	}
+
+	// Standalone query mode: run a single query on a data file without the LLM nor Slack, then exit
+	if *queryFlag != "" {
+		if *fileFlag == "" {
+			fmt.Fprintln(os.Stderr, errorStyle.Render("❌ ERROR: -query requires an employees data file given with -file"))
+			os.Exit(1)
+		}
+
+		response, err := runQuery(*fileFlag, *queryFlag, queryOpts...)
+		if err != nil {
+			errorMsg := errorStyle.Render("❌ Error processing query:") + "\n" + err.Error()
+			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+			fmt.Fprintln(os.Stderr, errorBox)
+			os.Exit(1)
+		}
+
+		if *outFlag != "" {
+			saveResponse(stdout, *outFlag, response, *quietFlag)
+			os.Exit(0)
+		}
+
+		writeRawResponse(stdout, response)
+		os.Exit(0)
+	}
+
+	// Self-check mode: verify that Slack and the LLM are reachable, without fetching the employees nor running a prompt
+	if *checkFlag {
+		slackToken, _, _ := slackTokens(os.Getenv)
+		results := agent.SelfCheck(context.Background(), slackToken,
+			agent.WithLLM(agent.Provider(*llmFlag), *modelFlag),
+			agent.WithAWSRegion(*awsRegionFlag),
+			agent.WithTimeout(*timeoutFlag),
+		)
+		if !writeCheckResults(stdout, results) {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Get Slack tokens from environment
+	slackToken, slackUserToken, found := slackTokens(os.Getenv)
+	if !found && !*dryRunFlag {
 		errorMsg := errorStyle.Render("❌ ERROR: SLACK_TOKEN environment variable not set") + "\n" +
-			"🔑 Please set it with your Slack OAuth token"
+			"🔑 Please set it with your Slack OAuth token, or set SLACK_BOT_TOKEN and SLACK_USER_TOKEN"
 		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
 		fmt.Fprintln(os.Stderr, errorBox)
 		os.Exit(1)
 	}
 
-	// Check for AWS credentials (except in quiet mode)
-	if os.Getenv("AWS_ACCESS_KEY_ID") == "" && !*quietFlag {
+	// Check for AWS credentials with Bedrock (except in quiet mode)
+	if agent.Provider(*llmFlag) == agent.ProviderBedrock && os.Getenv("AWS_ACCESS_KEY_ID") == "" && !*quietFlag {
 		warningMsg := warningStyle.Render("⚠️ Warning: No AWS credentials found") + "\n" +
 			"🔄 Please run 'aws sso login' followed by 'aws configure export-credentials --format=env' before starting this agent\n" +
 			"🔐 AWS credentials are required for Bedrock API access to Claude"
@@ -103,15 +463,134 @@ func main() {
 		time.Sleep(300 * time.Millisecond)
 	}
 
-	agent, err := agent.NewAgent(slackToken, *debugFlag)
+	// Collect the Slack tool options from flags
+	slackOpts := []slack.Option{slack.WithDataDir(dataDir), slack.WithRetention(*retentionFlag), slack.WithMaxDataFiles(*maxDataFilesFlag)}
+	if *redactPathsFlag {
+		slackOpts = append(slackOpts, slack.WithPathRedaction(true))
+	}
+
+	if *enterpriseFlag {
+		slackOpts = append(slackOpts, slack.WithEnterprise(true))
+	}
+
+	if *quietFlag {
+		slackOpts = append(slackOpts, slack.WithQuiet(true), slack.WithLogOutput(io.Discard))
+	} else {
+		slackOpts = append(slackOpts, slack.WithLogOutput(logOutput))
+	}
+
+	if *dateFormatFlag != model.DefaultDateFormat {
+		slackOpts = append(slackOpts, slack.WithDateFormat(*dateFormatFlag))
+	}
+
+	if slackUserToken != slackToken {
+		slackOpts = append(slackOpts, slack.WithUserToken(slackUserToken))
+	}
+
+	// Custom profile field holding the department, its ID varies per workspace
+	if departmentFieldID := os.Getenv("SLACK_DEPARTMENT_FIELD_ID"); departmentFieldID != "" {
+		slackOpts = append(slackOpts, slack.WithDepartmentField(departmentFieldID))
+	}
+
+	if *nameParticlesFlag != "" {
+		slackOpts = append(slackOpts, slack.WithNameParticles(strings.Split(*nameParticlesFlag, ",")))
+	}
+
+	if *imageSizeFlag != slack.DefaultImageSize {
+		slackOpts = append(slackOpts, slack.WithImageSize(*imageSizeFlag))
+	}
+
+	if *progressBarFlag {
+		slackOpts = append(slackOpts, slack.WithProgressBar(true))
+	}
+
+	if *pageSizeFlag < 1 || *maxPagesFlag < 1 {
+		errorMsg := errorStyle.Render("❌ ERROR: invalid pagination:") + "\n" +
+			fmt.Sprintf("-page-size (%d) and -max-pages (%d) must be at least 1", *pageSizeFlag, *maxPagesFlag)
+		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+		fmt.Fprintln(os.Stderr, errorBox)
+		os.Exit(1)
+	}
+	slackOpts = append(slackOpts, slack.WithPageSize(*pageSizeFlag), slack.WithMaxPages(*maxPagesFlag))
+
+	if *cacheTTLFlag != slack.DefaultCacheTTL {
+		slackOpts = append(slackOpts, slack.WithCacheTTL(*cacheTTLFlag))
+	}
+
+	// Collect the agent options
+	agentOpts := []agent.Option{
+		agent.WithQueryOptions(queryOpts...),
+		agent.WithSlackOptions(slackOpts...),
+		agent.WithLLM(agent.Provider(*llmFlag), *modelFlag),
+		agent.WithAWSRegion(*awsRegionFlag),
+		agent.WithMaxIterations(*maxIterationsFlag),
+		agent.WithTimeout(*timeoutFlag),
+		agent.WithOutputFormat(outputFormat),
+		agent.WithLogOutput(logOutput),
+	}
+	if promptTemplate != "" {
+		agentOpts = append(agentOpts, agent.WithPromptTemplate(promptTemplate))
+	}
+	if *dateFormatFlag != model.DefaultDateFormat {
+		agentOpts = append(agentOpts, agent.WithDateFormat(*dateFormatFlag))
+	}
+	if location != nil {
+		agentOpts = append(agentOpts, agent.WithLocation(location))
+	}
+	if *temperatureFlag >= 0 {
+		agentOpts = append(agentOpts, agent.WithTemperature(*temperatureFlag))
+	}
+	if *maxTokensFlag > 0 {
+		agentOpts = append(agentOpts, agent.WithMaxTokens(*maxTokensFlag))
+	}
+	if *noMemoryFlag || batchPrompts != nil {
+		agentOpts = append(agentOpts, agent.WithoutMemory())
+	}
+	if *streamFlag && *outFlag == "" {
+		agentOpts = append(agentOpts, agent.WithStreaming())
+	}
+	if *dryRunFlag {
+		if *fileFlag != "" {
+			if _, err := os.Stat(*fileFlag); err != nil {
+				errorMsg := errorStyle.Render("❌ ERROR: invalid dry-run data file:") + "\n" + err.Error()
+				errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+				fmt.Fprintln(os.Stderr, errorBox)
+				os.Exit(1)
+			}
+		}
+		agentOpts = append(agentOpts, agent.WithDryRun(*fileFlag))
+	}
+	// Check the scopes of the Slack token at startup, skipped in quiet mode to save a Slack call when scripting
+	if !*quietFlag {
+		agentOpts = append(agentOpts, agent.WithScopeCheck())
+	}
+
+	agent, err := agent.NewAgent(slackToken, *debugFlag, agentOpts...)
 
 	if err != nil {
 		errorMsg := errorStyle.Render("❌ Error initializing agent:") + "\n" + err.Error()
+		if errors.Is(err, slack.ErrMissingScopes) {
+			errorMsg += "\nAdd the missing scopes to the Slack app of the token, then reinstall it to the workspace."
+		}
 		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
 		fmt.Fprintln(os.Stderr, errorBox)
 		os.Exit(1)
 	}
 
+	// Batch mode: process the prompts of the batch file and exit, with an error if any prompt failed
+	if batchPrompts != nil {
+		failed := runBatch(agent, batchPrompts, stdout, os.Stderr, batchSettings{
+			rawOutput:     rawOutput,
+			quiet:         *quietFlag,
+			maxIterations: *maxIterationsFlag,
+			maxBytes:      *maxResponseBytesFlag,
+		})
+		if failed > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Non-interactive mode: process a single prompt and exit
 	if *promptFlag != "" {
 		if !*quietFlag {
@@ -119,17 +598,47 @@ func main() {
 		}
 
 		// Process the prompt
-		response, err := agent.ProcessPrompt(*promptFlag)
+		startTime := time.Now()
+		response, streamed, err := processPrompt(agent, *promptFlag, stdout, *quietFlag)
+		elapsedTime := time.Since(startTime)
 
 		// No need for spinner cleanup
 
-		if err != nil {
-			errorMsg := errorStyle.Render("❌ Error processing prompt:") + "\n" + err.Error()
-			errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
-			fmt.Fprintln(os.Stderr, errorBox)
+		// Print the error or the summary to stderr so that they do not pollute the response on stdout
+		var summary func(w io.Writer)
+		if *summaryFlag {
+			summary = func(w io.Writer) {
+				count, known := agent.LastResultCount()
+				writeSummary(w, *promptFlag, count, known, elapsedTime, agent.Model())
+			}
+		}
+		if writePromptOutcome(os.Stderr, err, *maxIterationsFlag, summary) {
 			os.Exit(1)
 		}
 
+		// The streamed response is already printed
+		if streamed {
+			os.Exit(0)
+		}
+
+		// Truncate very long responses before rendering so that the marker is visible
+		response = truncateResponse(response, *maxResponseBytesFlag)
+
+		// Write the response to the output file instead of stdout
+		if *outFlag != "" {
+			saveResponse(stdout, *outFlag, response, *quietFlag)
+			os.Exit(0)
+		}
+
+		// Print the other formats as is, for scripts
+		if rawOutput {
+			if !*quietFlag {
+				fmt.Println(resultHeaderStyle.Render("📊 Results"))
+			}
+			writeRawResponse(stdout, response)
+			os.Exit(0)
+		}
+
 		// Render markdown response in the terminal
 		renderedResponse, err := renderMarkdown(response)
 		if err != nil {
@@ -156,6 +665,9 @@ func main() {
 		title := titleStyle.Render("👤 AMA Employees Agent")
 		subtitle := subtitleStyle.Render("🔍 This Agent provides identities of employees")
 		instructions := "💡 " + highlightStyle.Render("Type 'exit' to quit")
+		if !*noMemoryFlag {
+			instructions += "\n💡 " + highlightStyle.Render("Type 'clear' or 'reset' to forget the previous prompts")
+		}
 
 		welcomeContent := title + "\n\n" +
 			subtitle + "\n" +
@@ -205,8 +717,15 @@ func main() {
 			break
 		}
 
+		// Forget the previous prompts, follow-up prompts start over
+		if command := strings.ToLower(input); command == "clear" || command == "reset" {
+			resetConversation(agent, os.Stdout, os.Stderr, *quietFlag)
+			continue
+		}
+
 		// Process the prompt with or without visual feedback
 		var response string
+		var streamed bool
 		var err error
 
 		if !*quietFlag {
@@ -215,11 +734,11 @@ func main() {
 
 			// Process the prompt
 			startTime := time.Now()
-			response, err = agent.ProcessPrompt(input)
+			response, streamed, err = processPrompt(agent, input, stdout, *quietFlag)
 			elapsedTime := time.Since(startTime)
 
 			if err != nil {
-				errorMsg := errorStyle.Render("❌ Error:") + "\n" + err.Error()
+				errorMsg := errorStyle.Render("❌ Error:") + "\n" + promptErrorMessage(err, *maxIterationsFlag)
 				errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
 				fmt.Fprintln(os.Stderr, errorBox)
 				continue
@@ -231,31 +750,48 @@ func main() {
 				highlightStyle.Render(elapsedTime.Round(time.Millisecond).String()))
 		} else {
 			// Quiet mode - just process without spinner
-			response, err = agent.ProcessPrompt(input)
+			response, streamed, err = processPrompt(agent, input, stdout, *quietFlag)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "❌ Error: %s\n", promptErrorMessage(err, *maxIterationsFlag))
 				continue
 			}
 		}
 
-		// Render markdown response in the terminal
-		renderedResponse, err := renderMarkdown(response)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, warningStyle.Render("⚠️ Error rendering markdown: %v\n"), err)
-			// Fall back to plain text if rendering fails
-			plainTextMsg := "📄 " + response
-			fmt.Println(boxStyle.BorderForeground(secondaryColor).Render(plainTextMsg))
-		} else {
-			// Show results in a nice box
-			resultHeader := resultHeaderStyle.Render("📊 Results")
-			fmt.Println(resultHeader)
-			// Add a small margin to the rendered response for better alignment
-			formattedResponse := lipgloss.NewStyle().
-				MarginLeft(1).
-				MarginTop(1).
-				Render(renderedResponse)
-			fmt.Print(formattedResponse)
+		// Truncate very long responses before rendering so that the marker is visible
+		response = truncateResponse(response, *maxResponseBytesFlag)
+
+		// Render markdown response in the terminal, unless already printed while streamed
+		// The other formats are printed as is
+		if !streamed && rawOutput {
+			if !*quietFlag {
+				fmt.Println(resultHeaderStyle.Render("📊 Results"))
+			}
+			writeRawResponse(stdout, response)
+		} else if !streamed {
+			renderedResponse, err := renderMarkdown(response)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, warningStyle.Render("⚠️ Error rendering markdown: %v\n"), err)
+				// Fall back to plain text if rendering fails
+				plainTextMsg := "📄 " + response
+				fmt.Println(boxStyle.BorderForeground(secondaryColor).Render(plainTextMsg))
+			} else {
+				// Show results in a nice box
+				resultHeader := resultHeaderStyle.Render("📊 Results")
+				fmt.Println(resultHeader)
+				// Add a small margin to the rendered response for better alignment
+				formattedResponse := lipgloss.NewStyle().
+					MarginLeft(1).
+					MarginTop(1).
+					Render(renderedResponse)
+				fmt.Print(formattedResponse)
+			}
 		}
+
+		// Suggest next steps when nothing was found
+		if hint := emptyResultHint(response, *emptyHintFlag, *quietFlag); hint != "" {
+			fmt.Println(warningStyle.Render(hint))
+		}
+
 		if !*quietFlag {
 			fmt.Println()
 		}
@@ -281,11 +817,238 @@ func main() {
 	}
 }
 
+// readPrompt returns the prompt read from the prompt file, or from stdin when the prompt is "-"
+// The trailing whitespace is trimmed, the newlines of multi-line prompts are kept
+func readPrompt(prompt, promptFile string, stdin io.Reader) (string, error) {
+	if promptFile != "" && prompt != "" {
+		return "", errors.New("-prompt and -prompt-file cannot be used together")
+	}
+
+	var data []byte
+	var err error
+	if promptFile != "" {
+		data, err = os.ReadFile(promptFile)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("prompt file %s not found", promptFile)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file %s: %v", promptFile, err)
+		}
+	} else {
+		data, err = io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt from stdin: %v", err)
+		}
+	}
+
+	text := strings.TrimRightFunc(string(data), unicode.IsSpace)
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("empty prompt")
+	}
+	return text, nil
+}
+
+// runQuery runs the query on the employees data file at path, without the LLM
+func runQuery(path, query string, opts ...jsonquery.Option) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read employees data file %s: %v", path, err)
+	}
+
+	return jsonquery.NewJSONQuery(opts...).ProcessQuery(data, query)
+}
+
+// processPrompt processes the prompt, printing the response as it is generated when the agent streams it
+// It reports whether the response was streamed, the other responses are to be rendered as usual
+// (e.g. without -stream or when the LLM does not stream its answers)
+func processPrompt(a *agent.Agent, prompt string, out io.Writer, quiet bool) (string, bool, error) {
+	streamed := false
+	response, err := a.ProcessPromptStream(prompt, func(chunk string) {
+		if !streamed {
+			streamed = true
+			if !quiet {
+				fmt.Println(resultHeaderStyle.Render("📊 Results"))
+			}
+		}
+		fmt.Fprint(out, chunk)
+	})
+	if streamed {
+		fmt.Fprintln(out)
+	}
+
+	return response, streamed, err
+}
+
+// writeRawResponse writes the response as is to w, without the code fences the LLM may have wrapped it in
+func writeRawResponse(w io.Writer, response string) {
+	fmt.Fprintln(w, stripCodeFence(strings.TrimSpace(response)))
+}
+
+// saveResponse writes the response to the output file at path, then confirms it on stdout unless quiet
+// It exits with an error if the file cannot be written
+func saveResponse(stdout io.Writer, path, response string, quiet bool) {
+	written, err := writeOutputFile(path, response)
+	if err != nil {
+		errorMsg := errorStyle.Render("❌ Error writing output file:") + "\n" + err.Error()
+		errorBox := boxStyle.BorderForeground(accentColor).Render(errorMsg)
+		fmt.Fprintln(os.Stderr, errorBox)
+		os.Exit(1)
+	}
+
+	if !quiet {
+		fmt.Fprintf(stdout, "💾 Wrote %d bytes to %s\n", written, path)
+	}
+}
+
+// writeOutputFile writes the response as is to the file at path, creating its parent directories as needed
+// Markdown responses are written unrendered, without terminal escape sequences. The number of bytes written is returned
+// The directories and the file are readable by their owner only, as the response holds employees PII
+func writeOutputFile(path, response string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, fmt.Errorf("failed to create directory of %s: %v", path, err)
+	}
+
+	content := stripCodeFence(strings.TrimSpace(response)) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return len(content), nil
+}
+
+// stripCodeFence removes the markdown code fence around the response, if any (e.g. "```json\n[...]\n```")
+func stripCodeFence(response string) string {
+	if !strings.HasPrefix(response, "```") || !strings.HasSuffix(response, "```") {
+		return response
+	}
+
+	// The opening fence and its info string (e.g. "json") take the whole first line
+	newline := strings.IndexByte(response, '\n')
+	if newline < 0 {
+		return response
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(response[newline+1:], "```"))
+}
+
+// memoryResetter forgets the previous prompts, e.g. the agent
+type memoryResetter interface {
+	ResetMemory() error
+}
+
+// resetConversation forgets the previous prompts of the interactive mode, for the "clear" and "reset" commands
+// A failure is reported as is rather than with promptErrorMessage, as no prompt was processed
+func resetConversation(r memoryResetter, out, errOut io.Writer, quiet bool) {
+	if err := r.ResetMemory(); err != nil {
+		fmt.Fprintf(errOut, "❌ Error: %v\n", err)
+	} else if !quiet {
+		fmt.Fprintln(out, successStyle.Render("🧹 Conversation cleared"))
+	}
+}
+
+// promptErrorMessage returns the message of an error processing a prompt
+// Hitting the maximum number of iterations or the timeout is not a failure of the agent, the message tells how to raise it
+func promptErrorMessage(err error, maxIterations int) string {
+	if errors.Is(err, agent.ErrTimeout) {
+		return fmt.Sprintf("⏱️ %v.\nRerun with a higher -timeout if the LLM or Slack are just slow.", err)
+	}
+	if errors.Is(err, agent.ErrMaxIterations) {
+		return fmt.Sprintf("⏱️ The agent reached its maximum number of iterations (%d) before a final answer, the response would be incomplete.\n"+
+			"Rerun with a higher -max-iterations (e.g. -max-iterations %d) for complex multi-step prompts.", maxIterations, maxIterations*2)
+	}
+	if errors.Is(err, slack.ErrNoUsers) {
+		return fmt.Sprintf("🔑 %v.\nAdd the missing scopes to the Slack app of the token, then reinstall it to the workspace.", slack.ErrNoUsers)
+	}
+	return err.Error()
+}
+
+// emptyResultHint returns the hint to show in interactive mode after a response without employees
+// No hint is returned in quiet mode or when the hint is disabled (empty)
+func emptyResultHint(response, hint string, quiet bool) string {
+	if quiet || hint == "" || !strings.Contains(response, jsonquery.NoResultsMessage) {
+		return ""
+	}
+	return hint
+}
+
+// writePromptOutcome writes the error of a failed non-interactive run to w, or the summary of a successful one when
+// summary is not nil, so that a failed run never reports a result count. It returns whether the run failed
+func writePromptOutcome(w io.Writer, err error, maxIterations int, summary func(w io.Writer)) bool {
+	if err != nil {
+		errorMsg := errorStyle.Render("❌ Error processing prompt:") + "\n" + promptErrorMessage(err, maxIterations)
+		fmt.Fprintln(w, boxStyle.BorderForeground(accentColor).Render(errorMsg))
+		return true
+	}
+
+	if summary != nil {
+		summary(w)
+	}
+	return false
+}
+
+// writeSummary writes the one-line summary of a non-interactive run to w
+// The result count is reported as "unknown" when the query layer did not list employees
+func writeSummary(w io.Writer, prompt string, count int, known bool, duration time.Duration, model string) {
+	results := "unknown"
+	if known {
+		results = strconv.Itoa(count)
+	}
+	fmt.Fprintf(w, "summary: prompt=%q results=%s duration=%s model=%s\n",
+		prompt, results, duration.Round(time.Millisecond), model)
+}
+
+// writeCheckResults writes one line per check of the self-check to w, OK or FAIL with the error
+// It returns whether all the checks passed
+func writeCheckResults(w io.Writer, results []agent.CheckResult) bool {
+	passed := true
+	for _, result := range results {
+		if result.Err != nil {
+			passed = false
+			fmt.Fprintf(w, "❌ %s: FAIL (%v)\n", result.Name, result.Err)
+			continue
+		}
+		fmt.Fprintf(w, "✅ %s: OK\n", result.Name)
+	}
+	return passed
+}
+
+// truncatedMarker is appended to the responses truncated by truncateResponse
+const truncatedMarker = "\n\n...(truncated)"
+
+// truncateResponse keeps at most maxBytes bytes of the response, cut on a rune boundary, followed by a marker
+// The response is returned as is when it fits or when maxBytes is 0 or less
+func truncateResponse(response string, maxBytes int) string {
+	if maxBytes <= 0 || len(response) <= maxBytes {
+		return response
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(response[cut]) {
+		cut--
+	}
+
+	return response[:cut] + truncatedMarker
+}
+
+// splitFields splits a comma-separated list of fields, ignoring empty entries
+func splitFields(list string) []string {
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
 // renderMarkdown renders markdown text as formatted terminal output
 func renderMarkdown(markdown string) (string, error) {
-	// Create a new renderer with dark theme and emoji support
+	// Create a new renderer with dark theme and emoji support, without styling if the colors are disabled
+	style := glamour.WithAutoStyle()
+	if colorDisabled {
+		style = glamour.WithStandardStyle(styles.NoTTYStyle)
+	}
 	r, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		style,
 		glamour.WithWordWrap(100),
 		glamour.WithEmoji(),
 	)
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/agent"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+	jsonquery "github.com/asaintsever/ama-employees-ai-agent/pkg/tools/json"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/tools/slack"
+)
+
+func TestEmptyResultHint(t *testing.T) {
+	const hint = "Try again"
+	emptyResponse := "Final result: " + jsonquery.NoResultsMessage
+
+	tests := []struct {
+		name     string
+		response string
+		hint     string
+		quiet    bool
+		expected string
+	}{
+		{"interactive with no results", emptyResponse, hint, false, hint},
+		{"quiet mode", emptyResponse, hint, true, ""},
+		{"results found", "Found 2 employees:\n\n1. John Doe\n2. Jane Doe\n", hint, false, ""},
+		{"hint disabled", emptyResponse, "", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := emptyResultHint(tt.response, tt.hint, tt.quiet); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	var stderr bytes.Buffer
+	writeSummary(&stderr, `Who are the "latest" deactivated employees?`, 3, true, 1234567*time.Microsecond, "some-model")
+
+	expected := `summary: prompt="Who are the \"latest\" deactivated employees?" results=3 duration=1.235s model=some-model` + "\n"
+	if got := stderr.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	stderr.Reset()
+	writeSummary(&stderr, "Show the headcount trend", 0, false, 2*time.Second, "some-model")
+
+	expected = `summary: prompt="Show the headcount trend" results=unknown duration=2s model=some-model` + "\n"
+	if got := stderr.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestWritePromptOutcome(t *testing.T) {
+	summary := func(w io.Writer) {
+		writeSummary(w, "Who left?", 2, true, time.Second, "some-model")
+	}
+
+	var stderr bytes.Buffer
+	if writePromptOutcome(&stderr, nil, 10, summary) {
+		t.Errorf("Expected a successful run")
+	}
+	if !strings.Contains(stderr.String(), "summary: prompt=\"Who left?\" results=2") {
+		t.Errorf("Expected the summary of the successful run, got %q", stderr.String())
+	}
+
+	// No summary on failure, only the error
+	stderr.Reset()
+	if !writePromptOutcome(&stderr, errors.New("LLM unavailable"), 10, summary) {
+		t.Errorf("Expected a failed run")
+	}
+	if !strings.Contains(stderr.String(), "LLM unavailable") || strings.Contains(stderr.String(), "summary:") {
+		t.Errorf("Expected the error without summary, got %q", stderr.String())
+	}
+
+	// No summary when disabled
+	stderr.Reset()
+	if writePromptOutcome(&stderr, nil, 10, nil) || stderr.Len() != 0 {
+		t.Errorf("Expected nothing written without summary, got %q", stderr.String())
+	}
+}
+
+// fakeResetter fails to forget the previous prompts with the given error
+type fakeResetter struct {
+	err error
+}
+
+func (r fakeResetter) ResetMemory() error {
+	return r.err
+}
+
+func TestResetConversation(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	resetConversation(fakeResetter{}, &stdout, &stderr, false)
+	if !strings.Contains(stdout.String(), "Conversation cleared") || stderr.Len() != 0 {
+		t.Errorf("Expected the conversation to be cleared, got %q and %q", stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	resetConversation(fakeResetter{}, &stdout, &stderr, true)
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing written in quiet mode, got %q", stdout.String())
+	}
+
+	// The error is not a prompt error, it is reported as is
+	resetConversation(fakeResetter{err: agent.ErrMaxIterations}, &stdout, &stderr, false)
+	if expected := "❌ Error: " + agent.ErrMaxIterations.Error() + "\n"; stderr.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, stderr.String())
+	}
+}
+
+func TestWriteCheckResults(t *testing.T) {
+	var stdout bytes.Buffer
+	passed := writeCheckResults(&stdout, []agent.CheckResult{
+		{Name: "Slack", Err: errors.New("SLACK_TOKEN environment variable not set")},
+		{Name: "LLM (bedrock)"},
+	})
+
+	expected := "❌ Slack: FAIL (SLACK_TOKEN environment variable not set)\n✅ LLM (bedrock): OK\n"
+	if passed || stdout.String() != expected {
+		t.Errorf("Expected a failed check and %q, got %v and %q", expected, passed, stdout.String())
+	}
+
+	stdout.Reset()
+	if !writeCheckResults(&stdout, []agent.CheckResult{{Name: "Slack"}, {Name: "LLM (bedrock)"}}) {
+		t.Errorf("Expected all the checks to pass, got %q", stdout.String())
+	}
+}
+
+func TestPromptErrorMessage(t *testing.T) {
+	err := fmt.Errorf("%w after 5 iterations", agent.ErrMaxIterations)
+	if msg := promptErrorMessage(err, 5); !strings.Contains(msg, "maximum number of iterations (5)") || !strings.Contains(msg, "-max-iterations 10") {
+		t.Errorf("Expected the iteration limit message, got %q", msg)
+	}
+
+	err = fmt.Errorf("%w after 1m0s", agent.ErrTimeout)
+	if msg := promptErrorMessage(err, 5); !strings.Contains(msg, "request timed out after 1m0s") || !strings.Contains(msg, "-timeout") {
+		t.Errorf("Expected the timeout message, got %q", msg)
+	}
+
+	err = fmt.Errorf("error running agent executor: %w", fmt.Errorf("error searching for employees information: %w", slack.ErrNoUsers))
+	if msg := promptErrorMessage(err, 5); !strings.HasPrefix(msg, "🔑 no users returned by Slack") || !strings.Contains(msg, "users:read scope") {
+		t.Errorf("Expected the missing scope message, got %q", msg)
+	}
+
+	err = errors.New("error running agent executor: boom")
+	if msg := promptErrorMessage(err, 5); msg != err.Error() {
+		t.Errorf("Expected %q, got %q", err.Error(), msg)
+	}
+}
+
+func TestTruncateResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		maxBytes int
+		expected string
+	}{
+		{"below the limit", "héllo", 10, "héllo"},
+		{"at the limit", "héllo", 6, "héllo"},
+		{"above the limit", "hello world", 5, "hello" + truncatedMarker},
+		// "é" is 2 bytes, "👤" is 4 bytes: never cut in the middle of a rune
+		{"multibyte rune at the limit", "héllo", 2, "h" + truncatedMarker},
+		{"multibyte rune just fitting", "héllo", 3, "hé" + truncatedMarker},
+		{"emoji across the limit", "ab👤cd", 4, "ab" + truncatedMarker},
+		{"emoji fitting the limit", "ab👤cd", 6, "ab👤" + truncatedMarker},
+		{"no limit", "hello world", 0, "hello world"},
+	}
+
+	for _, tt := range tests {
+		got := truncateResponse(tt.response, tt.maxBytes)
+		if got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: invalid UTF-8 in %q", tt.name, got)
+		}
+	}
+}
+
+func TestSlackTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		botToken  string
+		userToken string
+		found     bool
+	}{
+		{"single token", map[string]string{"SLACK_TOKEN": "xoxp-token"}, "xoxp-token", "xoxp-token", true},
+		{"separate tokens", map[string]string{"SLACK_BOT_TOKEN": "xoxb-bot", "SLACK_USER_TOKEN": "xoxp-user", "SLACK_TOKEN": "xoxp-token"}, "xoxb-bot", "xoxp-user", true},
+		{"bot token and fallback", map[string]string{"SLACK_BOT_TOKEN": "xoxb-bot", "SLACK_TOKEN": "xoxp-token"}, "xoxb-bot", "xoxp-token", true},
+		{"bot token only", map[string]string{"SLACK_BOT_TOKEN": "xoxb-bot"}, "xoxb-bot", "xoxb-bot", true},
+		{"user token only", map[string]string{"SLACK_USER_TOKEN": "xoxp-user"}, "xoxp-user", "xoxp-user", true},
+		{"no token", map[string]string{}, "", "", false},
+	}
+
+	for _, tt := range tests {
+		botToken, userToken, found := slackTokens(func(key string) string { return tt.env[key] })
+		if botToken != tt.botToken || userToken != tt.userToken || found != tt.found {
+			t.Errorf("%s: expected %q, %q, %v, got %q, %q, %v", tt.name, tt.botToken, tt.userToken, tt.found, botToken, userToken, found)
+		}
+	}
+}
+
+func TestRunQueryWithCSVExport(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Deactivated: true, DeactivatedDate: "2022-11-02"},
+		{FirstName: "Max", LastName: "Poe", Email: "max.poe@example.com", Title: "Designer"},
+	}
+	data, err := json.Marshal(employees)
+	if err != nil {
+		t.Fatalf("Error marshaling employees: %v", err)
+	}
+
+	dir := t.TempDir()
+	dataPath := filepath.Join(dir, "employees.json")
+	if err := os.WriteFile(dataPath, data, 0644); err != nil {
+		t.Fatalf("Error writing employees data file: %v", err)
+	}
+	csvPath := filepath.Join(dir, "report.csv")
+
+	response, err := runQuery(dataPath, "deactivated in 2023", jsonquery.WithCSVExport(csvPath))
+	if err != nil {
+		t.Fatalf("Error running query: %v", err)
+	}
+	if !strings.Contains(response, "Exported 1 employees to CSV file: "+csvPath) {
+		t.Errorf("Expected the CSV export note in the response, got:\n%s", response)
+	}
+
+	content, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Error reading CSV file: %v", err)
+	}
+	expected := "First Name,Last Name,Email,Title,Status,Deactivation Date\n" +
+		"John,Doe,john.doe@example.com,Engineer,Deactivated,2023-03-15\n"
+	if string(content) != expected {
+		t.Errorf("Expected CSV file:\n%s\ngot:\n%s", expected, content)
+	}
+
+	if _, err := runQuery(filepath.Join(dir, "missing.json"), "deactivated in 2023"); err == nil {
+		t.Error("Expected an error for a missing data file")
+	}
+}
+
+func TestRawOutput(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Max", LastName: "Poe", Email: "max.poe@example.com", Title: "Designer"},
+	}
+	data, err := json.Marshal(employees)
+	if err != nil {
+		t.Fatalf("Error marshaling employees: %v", err)
+	}
+	dataPath := filepath.Join(t.TempDir(), "employees.json")
+	if err := os.WriteFile(dataPath, data, 0644); err != nil {
+		t.Fatalf("Error writing employees data file: %v", err)
+	}
+
+	// Plain: the response is written as is, without rendering
+	response, err := runQuery(dataPath, "deactivated employees")
+	if err != nil {
+		t.Fatalf("Error running query: %v", err)
+	}
+	var stdout bytes.Buffer
+	writeRawResponse(&stdout, response)
+	if stdout.String() != strings.TrimSpace(response)+"\n" {
+		t.Errorf("Expected the response as is, got:\n%s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "\x1b[") {
+		t.Errorf("Expected no terminal escape sequences, got %q", stdout.String())
+	}
+
+	// JSON: the output can be piped into JSON tools, even when the LLM wrapped it in a code fence
+	response, err = runQuery(dataPath, "deactivated employees", jsonquery.WithDefaultFormat("json"))
+	if err != nil {
+		t.Fatalf("Error running query: %v", err)
+	}
+	stdout.Reset()
+	writeRawResponse(&stdout, "```json\n"+response+"\n```")
+	var results []model.EmployeeInfo
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, stdout.String())
+	}
+	if len(results) != 1 || results[0].LastName != "Doe" {
+		t.Errorf("Expected John Doe only, got %+v", results)
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		response string
+		expected string
+	}{
+		{"```json\n[{\"first_name\": \"John\"}]\n```", "[{\"first_name\": \"John\"}]"},
+		{"```\nFirst Name,Last Name\nJohn,Doe\n```", "First Name,Last Name\nJohn,Doe"},
+		{"[{\"first_name\": \"John\"}]", "[{\"first_name\": \"John\"}]"},
+		{"```not closed", "```not closed"},
+	}
+
+	for _, tt := range tests {
+		if got := stripCodeFence(tt.response); got != tt.expected {
+			t.Errorf("stripCodeFence(%q): expected %q, got %q", tt.response, tt.expected, got)
+		}
+	}
+}
+
+func TestReadPrompt(t *testing.T) {
+	dir := t.TempDir()
+	promptPath := filepath.Join(dir, "prompt.txt")
+	if err := os.WriteFile(promptPath, []byte("Who are the latest deactivated employees?\n  Show them as a table.\n\n"), 0644); err != nil {
+		t.Fatalf("Error writing prompt file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		prompt     string
+		promptFile string
+		stdin      string
+		expected   string
+		err        string
+	}{
+		{"file", "", promptPath, "", "Who are the latest deactivated employees?\n  Show them as a table.", ""},
+		{"stdin", "-", "", "Who is John Doe?\r\n", "Who is John Doe?", ""},
+		{"both", "Who is John Doe?", promptPath, "", "", "cannot be used together"},
+		{"missing file", "", filepath.Join(dir, "missing.txt"), "", "", "not found"},
+		{"empty stdin", "-", "", " \n\t\n", "", "empty prompt"},
+	}
+
+	for _, tt := range tests {
+		prompt, err := readPrompt(tt.prompt, tt.promptFile, strings.NewReader(tt.stdin))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: error reading prompt: %v", tt.name, err)
+		}
+		if prompt != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, prompt)
+		}
+	}
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reports", "2024", "deactivated.md")
+
+	response := "| First Name | Last Name |\n|---|---|\n| John | Doe |\n"
+	written, err := writeOutputFile(path, response)
+	if err != nil {
+		t.Fatalf("Error writing output file: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading output file: %v", err)
+	}
+	if string(content) != response || written != len(response) {
+		t.Errorf("Expected %d bytes:\n%s\ngot %d bytes:\n%s", len(response), response, written, content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the output file to be written with permissions 0600, got %v, %v", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "reports")); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected the directories to be created with permissions 0700, got %v, %v", info, err)
+	}
+
+	// The parent directory cannot be created over a file
+	if _, err := writeOutputFile(filepath.Join(path, "report.md"), response); err == nil {
+		t.Error("Expected an error writing under a file")
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if !shouldDisableColor(false, os.Getenv, true) {
+		t.Fatal("Expected the colors to be disabled with NO_COLOR")
+	}
+	if shouldDisableColor(false, func(string) string { return "" }, true) {
+		t.Error("Expected the colors to be enabled on a terminal")
+	}
+	if !shouldDisableColor(false, func(string) string { return "" }, false) || !shouldDisableColor(true, func(string) string { return "" }, true) {
+		t.Error("Expected the colors to be disabled with -no-color or without terminal")
+	}
+
+	// Colors forced as on a terminal, then disabled, the global styles being restored for the other tests
+	profile, disabled := lipgloss.ColorProfile(), colorDisabled
+	styles := []*lipgloss.Style{&titleStyle, &subtitleStyle, &highlightStyle, &successStyle, &errorStyle, &warningStyle,
+		&promptStyle, &resultHeaderStyle, &boxStyle}
+	saved := make([]lipgloss.Style, len(styles))
+	for i, style := range styles {
+		saved[i] = *style
+	}
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		colorDisabled = disabled
+		for i, style := range styles {
+			*style = saved[i]
+		}
+	})
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	disableColor()
+
+	errorBox := boxStyle.BorderForeground(accentColor).Render(errorStyle.Render("❌ ERROR: invalid model:") + "\nmalformed model ID")
+	if !strings.HasPrefix(errorBox, "❌ ERROR: invalid model:\nmalformed model ID") || strings.ContainsAny(errorBox, "\x1b╭│") {
+		t.Errorf("Expected the plain error message, got %q", errorBox)
+	}
+	if header := resultHeaderStyle.Render("📊 Results"); header != "📊 Results" {
+		t.Errorf("Expected the plain header, got %q", header)
+	}
+
+	rendered, err := renderMarkdown("# Deactivated employees\n\n| First Name | Last Name |\n|---|---|\n| **John** | Doe |\n")
+	if err != nil {
+		t.Fatalf("Error rendering markdown: %v", err)
+	}
+	if strings.Contains(rendered, "\x1b") || !strings.Contains(rendered, "John") {
+		t.Errorf("Expected markdown without escape sequences, got %q", rendered)
+	}
+}
@@ -2,51 +2,296 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/chains"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/bedrock"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/tmc/langchaingo/memory"
 	"github.com/tmc/langchaingo/tools"
 
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
 	"github.com/asaintsever/ama-employees-ai-agent/pkg/tools/json"
 	"github.com/asaintsever/ama-employees-ai-agent/pkg/tools/slack"
 )
 
+// ModelID is the Bedrock model used by the agent by default
+const ModelID = "anthropic.claude-3-5-sonnet-20241022-v2:0"
+
+// DefaultMaxIterations is the default maximum number of reasoning steps of the agent for a prompt
+const DefaultMaxIterations = 5
+
+// DefaultTimeout is the default maximum duration of the processing of a prompt
+const DefaultTimeout = 60 * time.Second
+
+// ErrTimeout is returned when the processing of a prompt takes longer than the timeout of the agent
+var ErrTimeout = errors.New("request timed out")
+
+// ErrMaxIterations is returned when the agent stops before a final answer, after its maximum number of iterations
+var ErrMaxIterations = errors.New("agent stopped before a final answer")
+
+// Provider is a provider of the LLM used by the agent
+type Provider string
+
+const (
+	// ProviderBedrock uses AWS Bedrock, with the credentials of the AWS SDK (default)
+	ProviderBedrock Provider = "bedrock"
+	// ProviderOpenAI uses OpenAI, with the API key of the OPENAI_API_KEY environment variable
+	ProviderOpenAI Provider = "openai"
+	// ProviderOllama uses a local Ollama server
+	ProviderOllama Provider = "ollama"
+)
+
+// Providers lists the supported LLM providers
+var Providers = []Provider{ProviderBedrock, ProviderOpenAI, ProviderOllama}
+
+// DefaultModels are the models used when none is given for a provider
+var DefaultModels = map[Provider]string{
+	ProviderBedrock: ModelID,
+	ProviderOpenAI:  "gpt-4o",
+	ProviderOllama:  "llama3.1",
+}
+
+// OutputFormat is the format of the responses of the agent
+type OutputFormat string
+
+const (
+	// OutputMarkdown asks for markdown responses, rendered in the terminal (default)
+	OutputMarkdown OutputFormat = "markdown"
+	// OutputPlain asks for plain text responses, without markdown formatting
+	OutputPlain OutputFormat = "plain"
+	// OutputJSON asks for the results as returned by the JSON query tool in JSON format
+	OutputJSON OutputFormat = "json"
+	// OutputCSV asks for the results as returned by the JSON query tool in CSV format
+	OutputCSV OutputFormat = "csv"
+)
+
+// OutputFormats lists the supported output formats
+var OutputFormats = []OutputFormat{OutputMarkdown, OutputPlain, OutputJSON, OutputCSV}
+
+// bedrockModelIDPattern matches the Bedrock model IDs, e.g. "anthropic.claude-3-haiku-20240307-v1:0",
+// optionally prefixed with the region of an inference profile (e.g. "us."), and the ARNs of models and profiles
+var bedrockModelIDPattern = regexp.MustCompile(`^(?:arn:aws[a-z-]*:bedrock:[a-z0-9-]*:[0-9]*:[a-z-]+/.+|(?:[a-z]{2,4}\.)?[a-z0-9-]+\.[a-z0-9][a-z0-9._-]*(?::[0-9]+(?::[0-9]+k)?)?)$`)
+
+// ValidateBedrockModelID returns an error if the model ID is obviously not a Bedrock model ID
+func ValidateBedrockModelID(modelID string) error {
+	if !bedrockModelIDPattern.MatchString(modelID) {
+		return fmt.Errorf("malformed Bedrock model ID %q, expected e.g. %q", modelID, ModelID)
+	}
+	return nil
+}
+
 // Agent represents the AMA Employees Agent
 type Agent struct {
-	bedrockClient *bedrockruntime.Client
+	bedrockClient *bedrockruntime.Client // Only set with the Bedrock provider
 	llm           llms.Model
+	model         string
+	maxIterations int
+	timeout       time.Duration
+	outputFormat  OutputFormat
+	dateFormat    string
+	location      *time.Location
+	now           func() time.Time
+	stream        *streamHandler             // Streams the final answers, nil when streaming is disabled
+	memory        *memory.ConversationBuffer // Previous prompts and responses of the session, nil when disabled
 	agentExecutor *agents.Executor
 	slackTool     *slack.SlackAMAEmployeesTool
 	jsonQueryTool *json.JSONQueryTool
 }
 
+// Option configures the Agent
+type Option func(*options)
+
+// options holds the optional settings of the Agent
+type options struct {
+	queryOptions   []json.Option
+	slackOptions   []slack.Option
+	provider       Provider
+	model          string
+	awsRegion      string
+	noMemory       bool
+	stream         bool
+	checkScopes    bool
+	dryRun         bool
+	maxIterations  int
+	timeout        time.Duration
+	outputFormat   OutputFormat
+	temperature    *float64         // Temperature of the model if nil
+	maxTokens      int              // Maximum of the model if 0
+	promptTemplate string           // Beginning of the agent prompt, the built-in one if empty
+	dateFormat     string           // Layout of the current date given to the LLM
+	location       *time.Location   // Time zone of the current date given to the LLM
+	now            func() time.Time // Clock of the current date, time.Now except in tests
+	llm            llms.Model       // Used instead of the LLM of the provider if set (tests)
+	logOutput      io.Writer        // Messages of the agent, see WithLogOutput
+}
+
+// WithMaxIterations sets the maximum number of reasoning steps of the agent for a prompt (at least 1)
+// Complex multi-step prompts may need more than the default
+func WithMaxIterations(iterations int) Option {
+	return func(o *options) {
+		o.maxIterations = max(iterations, 1)
+	}
+}
+
+// WithOutputFormat sets the format of the responses asked to the LLM
+// The JSON query tool should be configured accordingly for the JSON and CSV formats (see json.WithDefaultFormat)
+func WithOutputFormat(format OutputFormat) Option {
+	return func(o *options) {
+		o.outputFormat = format
+	}
+}
+
+// WithTimeout sets the maximum duration of the processing of a prompt, LLM calls and Slack fetches included
+// There is no timeout if it is 0 or less
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithoutMemory disables the conversation memory, each prompt being processed without the previous ones
+// (e.g. for scripted use)
+func WithoutMemory() Option {
+	return func(o *options) {
+		o.noMemory = true
+	}
+}
+
+// WithScopeCheck makes NewAgent verify that the Slack token has the scopes required to list the users,
+// failing with the missing scopes instead of returning empty results later. It costs one Slack call at startup
+func WithScopeCheck() Option {
+	return func(o *options) {
+		o.checkScopes = true
+	}
+}
+
+// WithDryRun makes the Slack tool return the fixture file, or a sample dataset if empty, instead of calling Slack
+// (see slack.WithDryRun). The scopes of the token are not checked, the LLM still plans the tool calls
+func WithDryRun(fixture string) Option {
+	return func(o *options) {
+		o.dryRun = true
+		o.slackOptions = append(o.slackOptions, slack.WithDryRun(fixture))
+	}
+}
+
+// withModel makes the agent use the given LLM instead of the one of the provider
+func withModel(llm llms.Model) Option {
+	return func(o *options) {
+		o.llm = llm
+	}
+}
+
+// WithAWSRegion sets the AWS region of Bedrock, the region of the default AWS configuration if empty
+func WithAWSRegion(region string) Option {
+	return func(o *options) {
+		o.awsRegion = region
+	}
+}
+
+// WithLLM selects the provider of the LLM and its model, the default model of the provider if model is empty
+func WithLLM(provider Provider, model string) Option {
+	return func(o *options) {
+		o.provider = provider
+		o.model = model
+	}
+}
+
+// WithQueryOptions passes options to the JSON query tool
+func WithQueryOptions(opts ...json.Option) Option {
+	return func(o *options) {
+		o.queryOptions = append(o.queryOptions, opts...)
+	}
+}
+
+// WithSlackOptions passes options to the Slack tool
+func WithSlackOptions(opts ...slack.Option) Option {
+	return func(o *options) {
+		o.slackOptions = append(o.slackOptions, opts...)
+	}
+}
+
 // NewAgent creates a new instance of the AMA Employees Agent
-func NewAgent(slackToken string, debug bool) (*Agent, error) {
-	// Configure AWS SDK to use SSO login
-	cfg, err := config.LoadDefaultConfig(context.Background())
+func NewAgent(slackToken string, debug bool, opts ...Option) (*Agent, error) {
+	settings := &options{
+		provider:      ProviderBedrock,
+		maxIterations: DefaultMaxIterations,
+		timeout:       DefaultTimeout,
+		outputFormat:  OutputMarkdown,
+		dateFormat:    model.DefaultDateFormat,
+		location:      time.Local,
+		now:           time.Now,
+		logOutput:     os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(settings)
+	}
+
+	resultsInstructions, err := outputInstructions(settings.outputFormat)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load AWS SDK config: %v", err)
+		return nil, err
+	}
+
+	callOptions, err := generationOptions(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.promptTemplate != "" {
+		if err := ValidatePromptTemplate(settings.promptTemplate); err != nil {
+			return nil, fmt.Errorf("invalid prompt template: %w", err)
+		}
 	}
 
-	// Create a Bedrock client for Claude
-	bedrockClient := bedrockruntime.NewFromConfig(cfg)
+	model := settings.model
+	if model == "" {
+		model = DefaultModels[settings.provider]
+	}
 
 	// Initialize tools
-	slackTool := slack.NewSlackAMAEmployeesTool(slackToken)
-	jsonQueryTool := json.NewJSONQueryTool()
+	slackTool := slack.NewSlackAMAEmployeesTool(slackToken, settings.slackOptions...)
+	jsonQueryTool := json.NewJSONQueryTool(settings.queryOptions...)
 
-	// Create a bedrock LLM for the agent
-	llm, err := bedrock.New(
-		bedrock.WithClient(bedrockClient),
-		bedrock.WithModel("anthropic.claude-3-5-sonnet-20241022-v2:0"),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Bedrock LLM: %v", err)
+	if settings.checkScopes && !settings.dryRun {
+		ctx := context.Background()
+		if settings.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, settings.timeout)
+			defer cancel()
+		}
+		scopes, err := slackTool.CheckScopes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if debug && scopes != nil {
+			fmt.Fprintf(settings.logOutput, "🔑 Slack token scopes: %s\n", strings.Join(scopes, ", "))
+		}
+	}
+
+	// Create the LLM of the agent
+	var bedrockClient *bedrockruntime.Client
+	llm := settings.llm
+	if llm == nil {
+		var err error
+		llm, bedrockClient, err = newLLM(settings, model)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(callOptions) > 0 {
+		llm = generationModel{Model: llm, options: callOptions}
 	}
 
 	// Create tools array
@@ -55,34 +300,61 @@ func NewAgent(slackToken string, debug bool) (*Agent, error) {
 		jsonQueryTool,
 	}
 
-	// Initialize the agent executor with custom prompt
+	// Initialize the agent executor with custom prompt, unless replaced by a template
 	// IMPORTANT: we MUST prepend the response with "Final Answer: " to avoid parsing errors (see https://github.com/tmc/langchaingo/blob/v0.1.13/agents/mrkl.go#L135)
-	agentPrompt := `Today is {{.today}}.
+	agentPrompt := settings.promptTemplate
+	if agentPrompt == "" {
+		agentPrompt = `Today is {{.today}}.
 You are the AMA Employees Agent, designed to provide information about employees.
 Focus only on providing the requested information about employees as asked.
 Adopt a neutral tone and be super concise, do not share thoughts or reasoning.
 
-Do not summarize the results, just provide the results as is in markdown format.
+` + resultsInstructions + `
 Always prepend the response with "Final Answer: ".
 
 You have access to the following tools:
 	
 {{.tool_descriptions}}`
+	}
 
 	// Create a Zero-Shot ReAct agent
 	// Prepare agent options
-	agentOpts := []agents.Option{agents.WithPromptPrefix(agentPrompt)}
+	agentOpts := []agents.Option{agents.WithPromptPrefix(currentDatePrompt(agentPrompt))}
+
+	// Keep the previous prompts and responses of the session for follow-up prompts (e.g. "among them")
+	// Each agent has its own memory, nothing is shared between agents
+	var conversation *memory.ConversationBuffer
+	if !settings.noMemory {
+		conversation = memory.NewConversationBuffer(memory.WithInputKey("input"), memory.WithOutputKey("output"))
+		agentOpts = append(agentOpts, agents.WithPromptSuffix(memorySuffix))
+	}
+
+	// Stream the final answers, the LLM is called in streaming mode when the agent has a callbacks handler
+	var stream *streamHandler
+	var agentHandlers []callbacks.Handler
+	if settings.stream {
+		stream = &streamHandler{}
+		agentHandlers = append(agentHandlers, stream)
+	}
 
 	// Add debug logging if debug mode is enabled
 	if debug {
-		fmt.Println("🔍 Debug mode enabled - detailed agent operations will be logged")
-		var logHandler callbacks.Handler = callbacks.LogHandler{}
+		fmt.Fprintln(settings.logOutput, "🔍 Debug mode enabled - detailed agent operations will be logged")
+		var logHandler callbacks.Handler = debugHandler{w: settings.logOutput}
 
-		agentOpts = append(agentOpts, agents.WithCallbacksHandler(logHandler))
+		agentHandlers = append(agentHandlers, logHandler)
 		slackTool.CallbacksHandler = logHandler
 		jsonQueryTool.CallbacksHandler = logHandler
 	}
 
+	switch len(agentHandlers) {
+	case 0:
+	case 1:
+		agentOpts = append(agentOpts, agents.WithCallbacksHandler(agentHandlers[0]))
+	default:
+		agentOpts = append(agentOpts, agents.WithCallbacksHandler(callbacks.CombiningHandler{Callbacks: agentHandlers}))
+	}
+
 	// Create the agent with options
 	zeroShotAgent := agents.NewOneShotAgent(
 		llm,
@@ -91,34 +363,174 @@ You have access to the following tools:
 	)
 
 	// Create the executor with the agent
-	agentExecutor := agents.NewExecutor(
-		zeroShotAgent,
-		agents.WithMaxIterations(5),
-	)
+	executorOpts := []agents.Option{agents.WithMaxIterations(settings.maxIterations)}
+	if conversation != nil {
+		executorOpts = append(executorOpts, agents.WithMemory(conversation))
+	}
+	agentExecutor := agents.NewExecutor(zeroShotAgent, executorOpts...)
 	// No error handling needed here as NewOneShotAgent and NewExecutor don't return errors
 
 	return &Agent{
 		bedrockClient: bedrockClient,
 		llm:           llm,
+		model:         model,
+		maxIterations: settings.maxIterations,
+		timeout:       settings.timeout,
+		outputFormat:  settings.outputFormat,
+		dateFormat:    settings.dateFormat,
+		location:      settings.location,
+		now:           settings.now,
+		stream:        stream,
+		memory:        conversation,
 		agentExecutor: agentExecutor,
 		slackTool:     slackTool,
 		jsonQueryTool: jsonQueryTool,
 	}, nil
 }
 
+// awsConfigOptions returns the options of the AWS configuration, overriding its region if one is set
+func awsConfigOptions(region string) []func(*config.LoadOptions) error {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	return opts
+}
+
+// newLLM creates the LLM of the configured provider with the given model
+// The Bedrock client is also returned with the Bedrock provider
+func newLLM(settings *options, model string) (llms.Model, *bedrockruntime.Client, error) {
+	switch settings.provider {
+	case ProviderBedrock:
+		if err := ValidateBedrockModelID(model); err != nil {
+			return nil, nil, err
+		}
+
+		// Configure AWS SDK to use SSO login
+		cfg, err := config.LoadDefaultConfig(context.Background(), awsConfigOptions(settings.awsRegion)...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to load AWS SDK config: %v", err)
+		}
+
+		// Create a Bedrock client for Claude
+		bedrockClient := bedrockruntime.NewFromConfig(cfg)
+
+		llm, err := bedrock.New(
+			bedrock.WithClient(bedrockClient),
+			bedrock.WithModel(model),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize Bedrock LLM: %v", err)
+		}
+		return llm, bedrockClient, nil
+	case ProviderOpenAI:
+		llm, err := openai.New(openai.WithModel(model))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize OpenAI LLM: %v", err)
+		}
+		return llm, nil, nil
+	case ProviderOllama:
+		llm, err := ollama.New(ollama.WithModel(model))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize Ollama LLM: %v", err)
+		}
+		return llm, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown LLM provider %q (valid providers: %s)", settings.provider, joinProviders())
+	}
+}
+
+// outputInstructions returns the instructions of the agent prompt on the format of the results
+func outputInstructions(format OutputFormat) (string, error) {
+	switch format {
+	case OutputMarkdown:
+		return "Do not summarize the results, just provide the results as is in markdown format.", nil
+	case OutputPlain:
+		return "Do not summarize the results, just provide the results as is in plain text, without any markdown formatting.", nil
+	case OutputJSON, OutputCSV:
+		return fmt.Sprintf("Do not summarize the results, just provide the results exactly as returned by the tool in %s format, "+
+			"without code fences nor any other text.", strings.ToUpper(string(format))), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (valid formats: %s)", format, joinOutputFormats())
+	}
+}
+
+// joinOutputFormats returns the comma-separated list of the supported output formats
+func joinOutputFormats() string {
+	names := make([]string, len(OutputFormats))
+	for i, format := range OutputFormats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// joinProviders returns the comma-separated list of the supported providers
+func joinProviders() string {
+	names := make([]string, len(Providers))
+	for i, provider := range Providers {
+		names[i] = string(provider)
+	}
+	return strings.Join(names, ", ")
+}
+
+// memorySuffix is the suffix of the agent prompt with the previous prompts and responses of the session
+const memorySuffix = `Previous conversation, for follow-up questions:
+{{.history}}
+
+Begin!
+
+Question: {{.input}}
+{{.agent_scratchpad}}`
+
+// ResetMemory forgets the previous prompts and responses of the session
+func (a *Agent) ResetMemory() error {
+	if a.memory == nil {
+		return nil
+	}
+	return a.memory.Clear(context.Background())
+}
+
 // ProcessPrompt processes user prompts and returns responses
 func (a *Agent) ProcessPrompt(prompt string) (string, error) {
-	ctx := context.Background()
+	return a.ProcessPromptContext(context.Background(), prompt)
+}
+
+// ProcessPromptContext processes the prompt like ProcessPrompt, stopping when the context is done
+// or when the timeout of the agent expires
+func (a *Agent) ProcessPromptContext(ctx context.Context, prompt string) (string, error) {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
 
-	// Run the agent executor
-	result, err := a.agentExecutor.Call(
+	// Run the agent executor, through chains.Call so that the memory is loaded and saved
+	result, err := chains.Call(
 		ctx,
-		map[string]any{"input": prompt},
+		a.agentExecutor,
+		map[string]any{"input": prompt, currentDateKey: a.currentDate()},
 	)
 
+	// A hung LLM call or a slow Slack fetch
+	if err != nil && a.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%w after %s", ErrTimeout, a.timeout)
+	}
+
+	// The LLM forgot the final answer prefix, its answer is returned rather than the error of the parser
+	if errors.Is(err, agents.ErrUnableToParseOutput) {
+		if answer, ok := a.unformattedAnswer(ctx, prompt, err); ok {
+			return answer, nil
+		}
+	}
+
+	// The agent gave up before a final answer, the maximum number of iterations may be too low for the prompt
+	if errors.Is(err, agents.ErrNotFinished) {
+		return "", fmt.Errorf("%w after %d iterations, increase the maximum number of iterations for this prompt", ErrMaxIterations, a.maxIterations)
+	}
+
 	// Check for parsing errors in the LangChain executor
 	if err != nil {
-		return "", fmt.Errorf("error running agent executor: %v", err)
+		return "", fmt.Errorf("error running agent executor: %w", err)
 	}
 
 	// Extract the output from the result
@@ -134,3 +546,42 @@ func (a *Agent) ProcessPrompt(prompt string) (string, error) {
 
 	return output, nil
 }
+
+// unformattedAnswerNote follows the answers of the LLM returned although they lack the final answer prefix
+const unformattedAnswerNote = "Note: the model did not format its answer as expected, it is shown as returned."
+
+// unformattedAnswer returns the answer of the LLM found in the error of the parser, when the answer lacks the final
+// answer prefix. A note follows it, unless the results are asked for in JSON or CSV format which it would corrupt
+// The answer is saved to the conversation memory, for follow-up prompts. It returns false when there is no answer
+func (a *Agent) unformattedAnswer(ctx context.Context, prompt string, err error) (string, bool) {
+	message := err.Error()
+	prefix := agents.ErrUnableToParseOutput.Error() + ":"
+	index := strings.Index(message, prefix)
+	if index < 0 {
+		return "", false
+	}
+	answer := strings.TrimSpace(message[index+len(prefix):])
+	if answer == "" {
+		return "", false
+	}
+
+	if a.memory != nil {
+		// The answer is returned even if it cannot be saved, only follow-up prompts would miss it
+		_ = a.memory.SaveContext(ctx, map[string]any{"input": prompt}, map[string]any{"output": answer})
+	}
+
+	if a.outputFormat == OutputJSON || a.outputFormat == OutputCSV {
+		return answer, true
+	}
+	return answer + "\n\n" + unformattedAnswerNote, true
+}
+
+// Model returns the identifier of the model used by the agent
+func (a *Agent) Model() string {
+	return a.model
+}
+
+// LastResultCount returns the number of employees returned by the last query, if known
+func (a *Agent) LastResultCount() (int, bool) {
+	return a.jsonQueryTool.LastResultCount()
+}
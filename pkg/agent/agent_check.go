@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/tools/slack"
+)
+
+// CheckResult is the outcome of a check of SelfCheck
+type CheckResult struct {
+	Name string // What was checked, e.g. "Slack" or "LLM (bedrock)"
+	Err  error  // Why the check failed, nil when it passed
+}
+
+// SelfCheck verifies that the agent can reach its services without fetching the employees nor running a prompt:
+// the Slack token is validated with the auth.test method (scopes included), and the LLM is asked for a single token
+// It returns the result of every check, in order, failed checks not stopping the next ones
+func SelfCheck(ctx context.Context, slackToken string, opts ...Option) []CheckResult {
+	settings := &options{provider: ProviderBedrock, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(settings)
+	}
+
+	return []CheckResult{
+		{Name: "Slack", Err: checkWithTimeout(ctx, settings.timeout, func(ctx context.Context) error {
+			return checkSlack(ctx, slackToken, settings.slackOptions)
+		})},
+		{Name: fmt.Sprintf("LLM (%s)", settings.provider), Err: checkWithTimeout(ctx, settings.timeout, func(ctx context.Context) error {
+			return checkLLM(ctx, settings)
+		})},
+	}
+}
+
+// checkWithTimeout runs the check with the timeout of the agent, if any
+func checkWithTimeout(ctx context.Context, timeout time.Duration, check func(context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return check(ctx)
+}
+
+// checkSlack validates the Slack token and its scopes
+func checkSlack(ctx context.Context, slackToken string, opts []slack.Option) error {
+	if slackToken == "" {
+		return errors.New("SLACK_TOKEN environment variable not set")
+	}
+	_, err := slack.NewSlackTool(slackToken, opts...).CheckScopes(ctx)
+	return err
+}
+
+// checkLLM creates the LLM of the agent and invokes it with a minimal prompt, checking the credentials and the model
+func checkLLM(ctx context.Context, settings *options) error {
+	model := settings.model
+	if model == "" {
+		model = DefaultModels[settings.provider]
+	}
+
+	llm := settings.llm
+	if llm == nil {
+		var err error
+		if llm, _, err = newLLM(settings, model); err != nil {
+			return err
+		}
+	}
+
+	if _, err := llms.GenerateFromSinglePrompt(ctx, llm, "Reply with OK.", llms.WithMaxTokens(1)); err != nil {
+		return fmt.Errorf("invocation of model %s failed: %w", model, err)
+	}
+	return nil
+}
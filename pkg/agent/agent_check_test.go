@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// okLLM answers every prompt with "OK"
+type okLLM struct{}
+
+func (okLLM) GenerateContent(_ context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "OK"}}}, nil
+}
+
+func (l okLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+func TestSelfCheckMissingToken(t *testing.T) {
+	results := SelfCheck(context.Background(), "", withModel(okLLM{}))
+	if len(results) != 2 {
+		t.Fatalf("Expected the Slack and LLM checks, got %v", results)
+	}
+
+	if results[0].Name != "Slack" || results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "SLACK_TOKEN environment variable not set") {
+		t.Errorf("Expected the Slack check to fail on the missing token, got %s: %v", results[0].Name, results[0].Err)
+	}
+	if results[1].Name != "LLM (bedrock)" || results[1].Err != nil {
+		t.Errorf("Expected the LLM check to pass despite the failed Slack check, got %s: %v", results[1].Name, results[1].Err)
+	}
+}
+
+func TestSelfCheckLLMTimeout(t *testing.T) {
+	start := time.Now()
+	results := SelfCheck(context.Background(), "", withModel(hangingLLM{}), WithTimeout(50*time.Millisecond))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the check to time out promptly, took %s", elapsed)
+	}
+
+	if err := results[1].Err; err == nil || !strings.Contains(err.Error(), "invocation of model "+ModelID+" failed") {
+		t.Errorf("Expected the LLM check to fail, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"regexp"
+	"time"
+)
+
+// currentDateKey is the input of the agent prompt holding the current date, as LangChain Go overrides the "today"
+// input with its own format, in the local time zone
+const currentDateKey = "current_date"
+
+// todayPlaceholder matches the placeholder of the current date in the agent prompt
+var todayPlaceholder = regexp.MustCompile(`\{\{(-?\s*)\.today(\s*-?)\}\}`)
+
+// WithDateFormat sets the Go layout of the current date given to the LLM, e.g. "02/01/2006" or time.RFC1123
+// The ISO format of model.DefaultDateFormat is used if empty
+func WithDateFormat(layout string) Option {
+	return func(o *options) {
+		if layout != "" {
+			o.dateFormat = layout
+		}
+	}
+}
+
+// WithLocation sets the time zone of the current date given to the LLM, the local time zone if nil
+// The day depends on it, e.g. for "deactivated in the last week" around midnight
+func WithLocation(location *time.Location) Option {
+	return func(o *options) {
+		if location != nil {
+			o.location = location
+		}
+	}
+}
+
+// withClock makes the agent read the current time from now instead of the system clock
+func withClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// currentDatePrompt makes the placeholder of the current date of the prompt use the date given by the agent
+func currentDatePrompt(prompt string) string {
+	return todayPlaceholder.ReplaceAllString(prompt, "{{${1}."+currentDateKey+"${2}}}")
+}
+
+// currentDate returns the current date in the format and the time zone of the agent
+func (a *Agent) currentDate() string {
+	return a.now().In(a.location).Format(a.dateFormat)
+}
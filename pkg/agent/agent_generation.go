@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// WithTemperature sets the temperature of the LLM, from 0 (deterministic, e.g. for scripted runs) to 1
+// The temperature of the model is used if not set
+func WithTemperature(temperature float64) Option {
+	return func(o *options) {
+		o.temperature = &temperature
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens generated by the LLM per call, e.g. higher for large listings
+// The maximum of the model is used if 0 or less
+func WithMaxTokens(tokens int) Option {
+	return func(o *options) {
+		o.maxTokens = tokens
+	}
+}
+
+// generationOptions returns the options of the calls of the LLM set with WithTemperature and WithMaxTokens
+func generationOptions(settings *options) ([]llms.CallOption, error) {
+	var opts []llms.CallOption
+	if settings.temperature != nil {
+		if *settings.temperature < 0 || *settings.temperature > 1 {
+			return nil, fmt.Errorf("invalid temperature %v, expected between 0 and 1", *settings.temperature)
+		}
+		opts = append(opts, llms.WithTemperature(*settings.temperature))
+	}
+	if settings.maxTokens > 0 {
+		opts = append(opts, llms.WithMaxTokens(settings.maxTokens))
+	}
+	return opts, nil
+}
+
+// generationModel passes the generation options of the agent to every call of the LLM, as the agents of
+// LangChain Go do not take call options. The options of the calls (e.g. stop words) are applied after them
+type generationModel struct {
+	llms.Model
+	options []llms.CallOption
+}
+
+// GenerateContent calls the LLM with the generation options of the agent
+func (m generationModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	return m.Model.GenerateContent(ctx, messages, append(slices.Clone(m.options), options...)...)
+}
+
+// Call calls the LLM with a single prompt and the generation options of the agent
+func (m generationModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// WithLogOutput sets where the messages of the agent are written (e.g. the debug logs), the standard output by default
+// A nil writer discards them. The messages of the tools are set with their own options (see WithQueryOptions)
+func WithLogOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.logOutput = w
+		if w == nil {
+			o.logOutput = io.Discard
+		}
+	}
+}
+
+// debugHandler logs the operations of the agent in debug mode, like callbacks.LogHandler but to the given writer
+type debugHandler struct {
+	callbacks.SimpleHandler
+	w io.Writer
+}
+
+var _ callbacks.Handler = debugHandler{}
+
+// HandleLLMGenerateContentStart logs the text of the messages sent to the LLM
+func (h debugHandler) HandleLLMGenerateContentStart(_ context.Context, messages []llms.MessageContent) {
+	fmt.Fprintln(h.w, "Entering LLM with messages:")
+	for _, message := range messages {
+		var text strings.Builder
+		for _, part := range message.Parts {
+			if part, ok := part.(llms.TextContent); ok {
+				text.WriteString(part.Text)
+			}
+		}
+		fmt.Fprintln(h.w, "Role:", message.Role)
+		fmt.Fprintln(h.w, "Text:", text.String())
+	}
+}
+
+// HandleLLMGenerateContentEnd logs the response of the LLM
+func (h debugHandler) HandleLLMGenerateContentEnd(_ context.Context, response *llms.ContentResponse) {
+	fmt.Fprintln(h.w, "Exiting LLM with response:")
+	for _, choice := range response.Choices {
+		if choice.Content != "" {
+			fmt.Fprintln(h.w, "Content:", choice.Content)
+		}
+		if choice.StopReason != "" {
+			fmt.Fprintln(h.w, "StopReason:", choice.StopReason)
+		}
+	}
+}
+
+// HandleLLMError logs the errors of the LLM
+func (h debugHandler) HandleLLMError(_ context.Context, err error) {
+	fmt.Fprintln(h.w, "Exiting LLM with error:", err)
+}
+
+// HandleText logs the texts of the chains
+func (h debugHandler) HandleText(_ context.Context, text string) {
+	fmt.Fprintln(h.w, text)
+}
+
+// HandleChainStart logs the inputs of the chains, e.g. the prompt and the previous steps of the agent
+func (h debugHandler) HandleChainStart(_ context.Context, inputs map[string]any) {
+	fmt.Fprintln(h.w, "Entering chain with inputs:", formatChainValues(inputs))
+}
+
+// HandleChainEnd logs the outputs of the chains, e.g. the text generated by the LLM
+func (h debugHandler) HandleChainEnd(_ context.Context, outputs map[string]any) {
+	fmt.Fprintln(h.w, "Exiting chain with outputs:", formatChainValues(outputs))
+}
+
+// HandleChainError logs the errors of the chains
+func (h debugHandler) HandleChainError(_ context.Context, err error) {
+	fmt.Fprintln(h.w, "Exiting chain with error:", err)
+}
+
+// HandleToolStart logs the inputs of the tools
+func (h debugHandler) HandleToolStart(_ context.Context, input string) {
+	fmt.Fprintln(h.w, "Entering tool with input:", singleLine(input))
+}
+
+// HandleToolEnd logs the outputs of the tools
+func (h debugHandler) HandleToolEnd(_ context.Context, output string) {
+	fmt.Fprintln(h.w, "Exiting tool with output:", singleLine(output))
+}
+
+// HandleToolError logs the errors of the tools
+func (h debugHandler) HandleToolError(_ context.Context, err error) {
+	fmt.Fprintln(h.w, "Exiting tool with error:", err)
+}
+
+// HandleAgentAction logs the tools selected by the agent
+func (h debugHandler) HandleAgentAction(_ context.Context, action schema.AgentAction) {
+	fmt.Fprintf(h.w, "Agent selected action: %q with input %q\n", singleLine(action.Tool), singleLine(action.ToolInput))
+}
+
+// HandleAgentFinish logs the final answer of the agent
+func (h debugHandler) HandleAgentFinish(_ context.Context, finish schema.AgentFinish) {
+	fmt.Fprintf(h.w, "Agent finish: %v\n", finish)
+}
+
+// formatChainValues formats the inputs or outputs of a chain on a single line
+func formatChainValues(values map[string]any) string {
+	var formatted strings.Builder
+	for key, value := range values {
+		fmt.Fprintf(&formatted, "%q: %q, ", singleLine(key), singleLine(fmt.Sprint(value)))
+	}
+	return formatted.String()
+}
+
+// singleLine replaces the newlines of s by spaces, so that each log entry fits on a line
+func singleLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
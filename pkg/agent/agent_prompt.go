@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"text/template"
+)
+
+// toolDescriptionsPlaceholder matches the placeholder of the descriptions of the tools in a prompt template
+var toolDescriptionsPlaceholder = regexp.MustCompile(`\{\{-?\s*\.tool_descriptions\s*-?\}\}`)
+
+// WithPromptTemplate replaces the beginning of the agent prompt, its tone and instructions, with the given Go template
+// The template may use the {{.today}} and {{.tool_descriptions}} placeholders, the latter being required (see
+// ValidatePromptTemplate). The instructions on the format of the reasoning steps and of the final answer follow it
+func WithPromptTemplate(prompt string) Option {
+	return func(o *options) {
+		o.promptTemplate = prompt
+	}
+}
+
+// ValidatePromptTemplate returns an error if the prompt template is not a valid Go template or lacks the
+// {{.tool_descriptions}} placeholder, without which the LLM would not know the tools
+func ValidatePromptTemplate(prompt string) error {
+	if _, err := template.New("prompt").Parse(prompt); err != nil {
+		return fmt.Errorf("not a valid Go template: %v", err)
+	}
+	if !toolDescriptionsPlaceholder.MatchString(prompt) {
+		return errors.New("the {{.tool_descriptions}} placeholder is missing")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tmc/langchaingo/callbacks"
+)
+
+// finalAnswerPrefix precedes the final answer in the outputs of the LLM, see the agent prompt
+const finalAnswerPrefix = "Final Answer:"
+
+// streamHandler passes the chunks of the final answer to the streaming function of the current prompt
+// The chunks of the reasoning steps (thoughts, actions) are not passed
+type streamHandler struct {
+	callbacks.SimpleHandler
+	onChunk func(chunk string) // Streaming function of the current prompt, nil when not streaming
+	pending string             // Output of the current LLM call, until the final answer prefix is found
+	final   bool               // The final answer prefix was found, the next chunks are part of the final answer
+}
+
+var _ callbacks.Handler = &streamHandler{}
+
+// HandleChainStart starts over for each call of the LLM by the agent
+func (h *streamHandler) HandleChainStart(_ context.Context, _ map[string]any) {
+	h.pending = ""
+	h.final = false
+}
+
+// HandleStreamingFunc passes the chunks following the final answer prefix to the streaming function
+// The prefix may be split across several chunks
+func (h *streamHandler) HandleStreamingFunc(_ context.Context, chunk []byte) {
+	if h.onChunk == nil {
+		return
+	}
+	if h.final {
+		h.onChunk(string(chunk))
+		return
+	}
+
+	h.pending += string(chunk)
+	index := strings.Index(h.pending, finalAnswerPrefix)
+	if index < 0 {
+		return
+	}
+
+	h.final = true
+	rest := strings.TrimLeft(h.pending[index+len(finalAnswerPrefix):], " ")
+	h.pending = ""
+	if rest != "" {
+		h.onChunk(rest)
+	}
+}
+
+// WithStreaming makes the agent stream the final answers of the LLM to the function given to ProcessPromptStream
+// The LLM is then called in streaming mode, the answers of LLMs without streaming support are not streamed
+func WithStreaming() Option {
+	return func(o *options) {
+		o.stream = true
+	}
+}
+
+// ProcessPromptStream processes the prompt like ProcessPrompt, calling onChunk with the chunks of the response
+// as they are generated. onChunk is never called without WithStreaming or if the LLM does not stream its answers,
+// the whole response is returned in all cases
+func (a *Agent) ProcessPromptStream(prompt string, onChunk func(chunk string)) (string, error) {
+	if a.stream == nil {
+		return a.ProcessPrompt(prompt)
+	}
+
+	a.stream.onChunk = onChunk
+	defer func() { a.stream.onChunk = nil }()
+
+	return a.ProcessPrompt(prompt)
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	// Pure-Go SQLite driver (no cgo required)
+	_ "modernc.org/sqlite"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// SQLiteTable is the name of the table employees are exported to
+const SQLiteTable = "employees"
+
+const createEmployeesTable = `CREATE TABLE IF NOT EXISTS ` + SQLiteTable + ` (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name       TEXT NOT NULL,
+	last_name        TEXT NOT NULL,
+	email            TEXT,
+	title            TEXT,
+	deactivated      INTEGER NOT NULL,
+	deactivated_date TEXT
+)`
+
+// ToSQLite writes the employees to the employees table of the SQLite database at path
+// If appendRows is false, any existing employees table is replaced, otherwise rows are appended to it
+// It returns the absolute path of the database file
+func ToSQLite(path string, employees []model.EmployeeInfo, appendRows bool) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path // Fall back to given path if absolute fails
+	}
+
+	db, err := sql.Open("sqlite", absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open SQLite database %s: %v", absPath, err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to start SQLite transaction: %v", err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	if !appendRows {
+		if _, err := tx.Exec("DROP TABLE IF EXISTS " + SQLiteTable); err != nil {
+			return "", fmt.Errorf("failed to drop existing %s table: %v", SQLiteTable, err)
+		}
+	}
+
+	if _, err := tx.Exec(createEmployeesTable); err != nil {
+		return "", fmt.Errorf("failed to create %s table: %v", SQLiteTable, err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO ` + SQLiteTable +
+		` (first_name, last_name, email, title, deactivated, deactivated_date) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare insert statement: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, emp := range employees {
+		if _, err := stmt.Exec(emp.FirstName, emp.LastName, emp.Email, emp.Title, emp.Deactivated, emp.DeactivatedDate); err != nil {
+			return "", fmt.Errorf("failed to insert employee %s %s: %v", emp.FirstName, emp.LastName, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit SQLite transaction: %v", err)
+	}
+
+	return absPath, nil
+}
@@ -0,0 +1,79 @@
+package export_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/export"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestToSQLite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "employees.db")
+
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Software Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Doe", Email: "jane.doe@example.com", Title: "Marketing Manager"},
+	}
+
+	countRows := func(t *testing.T) int {
+		t.Helper()
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatalf("Error opening database: %v", err)
+		}
+		defer db.Close()
+
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + export.SQLiteTable).Scan(&count); err != nil {
+			t.Fatalf("Error counting rows: %v", err)
+		}
+		return count
+	}
+
+	path, err := export.ToSQLite(dbPath, employees, false)
+	if err != nil {
+		t.Fatalf("Error exporting to SQLite: %v", err)
+	}
+	if path != dbPath {
+		t.Errorf("Expected path %q, got %q", dbPath, path)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Error opening database: %v", err)
+	}
+	defer db.Close()
+
+	var email, date string
+	var deactivated bool
+	err = db.QueryRow("SELECT email, deactivated, deactivated_date FROM employees WHERE first_name = ? AND last_name = ?", "John", "Doe").
+		Scan(&email, &deactivated, &date)
+	if err != nil {
+		t.Fatalf("Error querying inserted row: %v", err)
+	}
+	if email != "john.doe@example.com" || !deactivated || date != "2023-03-15" {
+		t.Errorf("Unexpected row: email=%q deactivated=%v date=%q", email, deactivated, date)
+	}
+
+	if count := countRows(t); count != 2 {
+		t.Errorf("Expected 2 rows, got %d", count)
+	}
+
+	// Appending keeps existing rows
+	if _, err := export.ToSQLite(dbPath, employees, true); err != nil {
+		t.Fatalf("Error appending to SQLite: %v", err)
+	}
+	if count := countRows(t); count != 4 {
+		t.Errorf("Expected 4 rows after append, got %d", count)
+	}
+
+	// Replacing drops existing rows
+	if _, err := export.ToSQLite(dbPath, employees[:1], false); err != nil {
+		t.Fatalf("Error replacing SQLite table: %v", err)
+	}
+	if count := countRows(t); count != 1 {
+		t.Errorf("Expected 1 row after replace, got %d", count)
+	}
+}
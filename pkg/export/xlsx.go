@@ -0,0 +1,176 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XLSXSheet is the name of the sheet employees are exported to
+const XLSXSheet = "Employees"
+
+// excelEpoch is the day 0 of the Excel dates (1900 date system, shifted by the leap year bug of Excel)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Styles of the cells, indexes of the cellXfs of xlsxStyles
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleHeader = 2
+)
+
+// The parts of the workbook other than the sheet, the minimum a spreadsheet application needs to open it
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + XLSXSheet + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// The cell styles: default, ISO dates and bold headers
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+)
+
+// ToXLSX writes a sheet with a bold header row and one row per entry of rows to the Excel workbook at path,
+// replacing it if it exists, readable by its owner only as it holds employee data. The values of the rows are either strings or time.Time, written as Excel dates
+// (the zero time being an empty cell). It returns the absolute path of the workbook file
+func ToXLSX(path string, headers []string, rows [][]any) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path // Fall back to given path if absolute fails
+	}
+
+	var content bytes.Buffer
+	archive := zip.NewWriter(&content)
+	parts := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(headers, rows)},
+	}
+	for _, part := range parts {
+		w, err := archive.Create(part.name)
+		if err != nil {
+			return "", fmt.Errorf("failed to create Excel workbook: %v", err)
+		}
+		if _, err := w.Write([]byte(part.data)); err != nil {
+			return "", fmt.Errorf("failed to create Excel workbook: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("failed to create Excel workbook: %v", err)
+	}
+
+	if err := os.WriteFile(absPath, content.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("failed to write Excel file %s: %v", absPath, err)
+	}
+
+	return absPath, nil
+}
+
+// xlsxSheet returns the XML of the sheet, the header row being frozen
+func xlsxSheet(headers []string, rows [][]any) string {
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	sheet.WriteString("<sheetData>")
+
+	header := make([]any, len(headers))
+	for i, h := range headers {
+		header[i] = h
+	}
+	writeXLSXRow(&sheet, 1, header, xlsxStyleHeader)
+	for i, row := range rows {
+		writeXLSXRow(&sheet, i+2, row, 0)
+	}
+
+	sheet.WriteString("</sheetData></worksheet>")
+	return sheet.String()
+}
+
+// writeXLSXRow writes a row of cells, the strings inline with the given style and the dates as numbers
+func writeXLSXRow(sheet *strings.Builder, number int, values []any, style int) {
+	sheet.WriteString(`<row r="` + strconv.Itoa(number) + `">`)
+	for i, value := range values {
+		ref := xlsxColumn(i) + strconv.Itoa(number)
+
+		switch v := value.(type) {
+		case time.Time:
+			if v.IsZero() {
+				continue
+			}
+			sheet.WriteString(fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, excelDate(v)))
+		default:
+			text := fmt.Sprint(v)
+			if text == "" {
+				continue
+			}
+			sheet.WriteString(`<c r="` + ref + `" t="inlineStr"`)
+			if style != 0 {
+				sheet.WriteString(` s="` + strconv.Itoa(style) + `"`)
+			}
+			sheet.WriteString(`><is><t xml:space="preserve">`)
+			xml.EscapeText(sheet, []byte(text)) // Never fails on a strings.Builder
+			sheet.WriteString("</t></is></c>")
+		}
+	}
+	sheet.WriteString("</row>")
+}
+
+// xlsxColumn returns the letters of the column at the given index, e.g. "A" for 0 or "AA" for 26
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// excelDate returns the serial number of the day of t in the Excel 1900 date system, e.g. "45000" for 2023-03-15
+func excelDate(t time.Time) string {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return strconv.Itoa(int(day.Sub(excelEpoch).Hours() / 24))
+}
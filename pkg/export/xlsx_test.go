@@ -0,0 +1,117 @@
+package export_test
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/export"
+)
+
+// xlsxCell is a cell of a sheet, with its value either inline or as a number
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Style  string `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+// readXLSXSheet reads the cells of the first sheet of the workbook at path, row by row
+func readXLSXSheet(t *testing.T, path string) [][]xlsxCell {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Error opening workbook: %v", err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("Error opening sheet: %v", err)
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Error reading sheet: %v", err)
+		}
+
+		var sheet struct {
+			Rows []struct {
+				Cells []xlsxCell `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		if err := xml.Unmarshal(data, &sheet); err != nil {
+			t.Fatalf("Error decoding sheet: %v", err)
+		}
+		rows := make([][]xlsxCell, len(sheet.Rows))
+		for i, row := range sheet.Rows {
+			rows[i] = row.Cells
+		}
+		return rows
+	}
+
+	t.Fatal("No sheet in workbook")
+	return nil
+}
+
+func TestToXLSX(t *testing.T) {
+	xlsxPath := filepath.Join(t.TempDir(), "employees.xlsx")
+
+	headers := []string{"Name", "Title", "Status", "Deactivation Date"}
+	rows := [][]any{
+		{"John Doe", "R&D <Lead>", "Deactivated", time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"Jane Doe", "", "Active", ""},
+		{"Max Poe", "Engineer", "Deactivated", "sometime in 2022"},
+	}
+
+	path, err := export.ToXLSX(xlsxPath, headers, rows)
+	if err != nil {
+		t.Fatalf("Error exporting to Excel: %v", err)
+	}
+	if path != xlsxPath {
+		t.Errorf("Expected path %q, got %q", xlsxPath, path)
+	}
+	// Employee data is only readable by its owner
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the workbook to be written with permissions 0600, got %v, %v", info, err)
+	}
+
+	expected := [][]xlsxCell{
+		{
+			{Ref: "A1", Type: "inlineStr", Style: "2", Inline: "Name"},
+			{Ref: "B1", Type: "inlineStr", Style: "2", Inline: "Title"},
+			{Ref: "C1", Type: "inlineStr", Style: "2", Inline: "Status"},
+			{Ref: "D1", Type: "inlineStr", Style: "2", Inline: "Deactivation Date"},
+		},
+		{
+			{Ref: "A2", Type: "inlineStr", Inline: "John Doe"},
+			{Ref: "B2", Type: "inlineStr", Inline: "R&D <Lead>"},
+			{Ref: "C2", Type: "inlineStr", Inline: "Deactivated"},
+			{Ref: "D2", Style: "1", Value: "45000"}, // Excel date of 2023-03-15
+		},
+		{
+			// Empty cells are omitted
+			{Ref: "A3", Type: "inlineStr", Inline: "Jane Doe"},
+			{Ref: "C3", Type: "inlineStr", Inline: "Active"},
+		},
+		{
+			{Ref: "A4", Type: "inlineStr", Inline: "Max Poe"},
+			{Ref: "B4", Type: "inlineStr", Inline: "Engineer"},
+			{Ref: "C4", Type: "inlineStr", Inline: "Deactivated"},
+			{Ref: "D4", Type: "inlineStr", Inline: "sometime in 2022"},
+		},
+	}
+	if got := readXLSXSheet(t, path); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the rows:\n%v\ngot:\n%v", expected, got)
+	}
+}
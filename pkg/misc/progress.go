@@ -0,0 +1,78 @@
+package misc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// progressBarWidth is the number of cells of the progress bar
+const progressBarWidth = 30
+
+var (
+	progressFilledStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")) // Purple
+	progressEmptyStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5C5C5C")) // Grey
+)
+
+// ProgressBar is a determinate progress bar, for long operations whose total is known or estimated
+type ProgressBar struct {
+	out     io.Writer
+	message string
+	total   int
+}
+
+// StartProgressBar starts a progress bar with the given message and estimated total
+// It returns nil when the total is unknown (0 or less), when quiet (see SetQuiet) or when the standard output
+// is not a terminal, so that callers can fall back to a spinner
+// Usage:
+//
+//	p := StartProgressBar("Fetching users", 1200)
+//	if p == nil {
+//		// use StartSpinner instead
+//	}
+//	p.Update(500)
+//	p.Stop()
+func StartProgressBar(message string, total int) *ProgressBar {
+	if total <= 0 || quiet.Load() || !isTerminal(os.Stdout) {
+		return nil
+	}
+	p := &ProgressBar{out: os.Stdout, message: message, total: total}
+	p.Update(0)
+	return p
+}
+
+// Update renders the progress bar for the given current count
+func (p *ProgressBar) Update(current int) {
+	fmt.Fprintf(p.out, "\r\033[K%s %s", p.message, renderProgressBar(current, p.total, progressBarWidth))
+}
+
+// Stop clears the progress bar line
+func (p *ProgressBar) Stop() {
+	fmt.Fprint(p.out, "\r\033[K")
+}
+
+// progressRatio returns the completed ratio, between 0 and 1
+// The total is an estimate, the ratio is capped at 1 when the current count exceeds it
+func progressRatio(current, total int) float64 {
+	if total <= 0 || current <= 0 {
+		return 0
+	}
+	if current >= total {
+		return 1
+	}
+	return float64(current) / float64(total)
+}
+
+// renderProgressBar renders a progress bar of width cells followed by the counts, e.g. "█████░░░░░ 120 of ~500 (24%)"
+func renderProgressBar(current, total, width int) string {
+	ratio := progressRatio(current, total)
+	filled := int(ratio * float64(width))
+
+	return fmt.Sprintf("%s%s %d of ~%d (%d%%)",
+		progressFilledStyle.Render(strings.Repeat("█", filled)),
+		progressEmptyStyle.Render(strings.Repeat("░", width-filled)),
+		current, total, int(ratio*100))
+}
@@ -0,0 +1,54 @@
+package misc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgressRatio(t *testing.T) {
+	tests := []struct {
+		current  int
+		total    int
+		expected float64
+	}{
+		{0, 100, 0},
+		{25, 100, 0.25},
+		{100, 100, 1},
+		{150, 100, 1}, // the total is only an estimate
+		{10, 0, 0},
+		{-5, 100, 0},
+	}
+
+	for _, tt := range tests {
+		if got := progressRatio(tt.current, tt.total); got != tt.expected {
+			t.Errorf("progressRatio(%d, %d): expected %v, got %v", tt.current, tt.total, tt.expected, got)
+		}
+	}
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	tests := []struct {
+		current int
+		total   int
+		filled  int
+		counts  string
+	}{
+		{0, 500, 0, "0 of ~500 (0%)"},
+		{120, 500, 2, "120 of ~500 (24%)"},
+		{500, 500, 10, "500 of ~500 (100%)"},
+		{650, 500, 10, "650 of ~500 (100%)"},
+	}
+
+	for _, tt := range tests {
+		bar := renderProgressBar(tt.current, tt.total, 10)
+		if filled := strings.Count(bar, "█"); filled != tt.filled {
+			t.Errorf("%d of %d: expected %d filled cells, got %d in %q", tt.current, tt.total, tt.filled, filled, bar)
+		}
+		if empty := strings.Count(bar, "░"); empty != 10-tt.filled {
+			t.Errorf("%d of %d: expected %d empty cells, got %d in %q", tt.current, tt.total, 10-tt.filled, empty, bar)
+		}
+		if !strings.HasSuffix(bar, " "+tt.counts) {
+			t.Errorf("%d of %d: expected counts %q in %q", tt.current, tt.total, tt.counts, bar)
+		}
+	}
+}
@@ -1,16 +1,30 @@
 package misc
 
 import (
+	"io"
+	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"golang.org/x/term"
 )
 
 // Spinner represents a spinner instance
 type Spinner = *spinner.Spinner
 
+// quiet suppresses the spinners and progress bars, see SetQuiet
+var quiet atomic.Bool
+
+// SetQuiet suppresses the spinners and progress bars when enabled, e.g. in quiet mode so that captured output
+// has no control characters
+func SetQuiet(enabled bool) {
+	quiet.Store(enabled)
+}
+
 // StartSpinner starts a spinner animation with the given message
-// It returns a Spinner that can be stopped using StopSpinner
+// It returns a Spinner that can be stopped using StopSpinner, a nil stub without animation when quiet (see SetQuiet)
+// or when the standard output is not a terminal (e.g. redirected to a log file)
 // Usage:
 //
 //	s := StartSpinner("Processing")
@@ -18,16 +32,34 @@ type Spinner = *spinner.Spinner
 //	StopSpinner(s)
 //	// Print your success message here
 func StartSpinner(message string) Spinner {
+	return startSpinner(os.Stdout, message)
+}
+
+// startSpinner starts a spinner animation written to w, the nil stub when quiet or when w is not a terminal
+func startSpinner(w io.Writer, message string) Spinner {
+	if quiet.Load() || !isTerminal(w) {
+		return nil
+	}
+
 	// Create a new spinner with dot style and 100ms update frequency
-	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(w))
 	s.Suffix = " " + message
 	s.Start()
 	return s
 }
 
-// StopSpinner stops the spinner animation
+// isTerminal determines if w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// StopSpinner stops the spinner animation, doing nothing for the stub returned when the spinner is suppressed
 // This is a blocking call that ensures the spinner is fully stopped
 // before returning
 func StopSpinner(s Spinner) {
+	if s == nil {
+		return
+	}
 	s.Stop()
 }
@@ -0,0 +1,38 @@
+package misc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSpinnerNotTerminal(t *testing.T) {
+	var out bytes.Buffer
+	s := startSpinner(&out, "Processing")
+	if s != nil {
+		t.Error("Expected the stub spinner for a writer that is not a terminal")
+	}
+
+	// Longer than a few frames of the animation
+	time.Sleep(300 * time.Millisecond)
+	StopSpinner(s)
+
+	if out.Len() != 0 {
+		t.Errorf("Expected no animation output, got %q", out.String())
+	}
+}
+
+func TestSpinnerQuiet(t *testing.T) {
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	s := StartSpinner("Processing")
+	if s != nil {
+		t.Error("Expected the stub spinner when quiet")
+	}
+	StopSpinner(s)
+
+	if p := StartProgressBar("Fetching users", 100); p != nil {
+		t.Error("Expected no progress bar when quiet")
+	}
+}
@@ -1,11 +1,47 @@
 package model
 
+// Sources of the deactivation dates
+const (
+	// DateSourceEstimated is used when the deactivation date is estimated from the last profile update
+	DateSourceEstimated = "estimated"
+	// DateSourceVerified is used when the deactivation date is reported by Slack
+	DateSourceVerified = "verified"
+)
+
+// DefaultDateFormat is the default Go layout of the deactivation dates (ISO 8601)
+const DefaultDateFormat = "2006-01-02"
+
+// PresenceWords are the words of the queries on the presence of the employees, shared by the Slack tool fetching the
+// presence and the JSON query tool filtering on it. "online" stands for the "active" presence, the others for "away"
+var PresenceWords = []string{"online", "offline", "away"}
+
 // EmployeeInfo contains information about an employee
+// The YAML keys are the JSON ones, so that both outputs share the same fields
 type EmployeeInfo struct {
-	FirstName       string `json:"first_name"`
-	LastName        string `json:"last_name"`
-	Email           string `json:"email"`
-	Title           string `json:"title"`
-	Deactivated     bool   `json:"deactivated"`
-	DeactivatedDate string `json:"deactivated_date,omitempty"`
+	// SlackID is the stable ID of the Slack user (e.g. "U012ABC"), empty in older data files
+	SlackID   string `json:"slack_id,omitempty" yaml:"slack_id,omitempty"`
+	FirstName string `json:"first_name" yaml:"first_name"`
+	// MiddleName holds the middle names found in the full name, e.g. "Jane" for "Mary Jane Watson-Parker"
+	MiddleName string `json:"middle_name,omitempty" yaml:"middle_name,omitempty"`
+	LastName   string `json:"last_name" yaml:"last_name"`
+	Email      string `json:"email" yaml:"email"`
+	Title      string `json:"title" yaml:"title"`
+	// Department is read from a custom field of the Slack profiles, empty when not configured
+	Department string `json:"department,omitempty" yaml:"department,omitempty"`
+	Phone      string `json:"phone,omitempty" yaml:"phone,omitempty"`
+	// Timezone is the IANA name of the timezone of the user, e.g. "Europe/Paris"
+	Timezone   string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	StatusText string `json:"status_text,omitempty" yaml:"status_text,omitempty"`
+	// ImageURL is the URL of the profile picture, empty when the user has none (e.g. some deactivated users)
+	ImageURL string `json:"image_url,omitempty" yaml:"image_url,omitempty"`
+	// Presence is "active" or "away" for the active users, only fetched on demand (empty when unknown)
+	Presence        string `json:"presence,omitempty" yaml:"presence,omitempty"`
+	Deactivated     bool   `json:"deactivated" yaml:"deactivated"`
+	DeactivatedDate string `json:"deactivated_date,omitempty" yaml:"deactivated_date,omitempty"`
+	// DeactivatedDateSource is either DateSourceEstimated or DateSourceVerified, empty when unknown (older data files)
+	DeactivatedDateSource string `json:"deactivated_date_source,omitempty" yaml:"deactivated_date_source,omitempty"`
+	// IsBot is true for the bot and service accounts, only fetched when explicitly asked for
+	IsBot bool `json:"is_bot,omitempty" yaml:"is_bot,omitempty"`
+	// Has2FA is nil when the two-factor status is not visible to the token (requires admin)
+	Has2FA *bool `json:"has_2fa,omitempty" yaml:"has_2fa,omitempty"`
 }
@@ -1,166 +1,1249 @@
 package json
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/export"
 	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
-	"github.com/thedevsaddam/gojsonq/v2"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
+// NoResultsMessage is returned when no employee matches a query
+const NoResultsMessage = "No employees found matching the criteria."
+
+// NoEmployeesMessage is returned when the data holds no employees at all
+const NoEmployeesMessage = "No employees in file."
+
+// Permissions of the data directory and of the exported files, readable by their owner only as they hold employees PII
+const (
+	dataDirPerm  = 0700
+	dataFilePerm = 0600
+)
+
+var (
+	// errEmptyInput is returned for empty data files
+	errEmptyInput = errors.New("file is empty")
+	// errNotEmployeesArray is returned for data files that are not a JSON array of employees
+	errNotEmployeesArray = errors.New("input file is not a JSON array of employees")
+	// errNoEmployees is returned for data files holding an empty JSON array
+	errNoEmployees = errors.New("no employees in file")
+)
+
+// ErrEmptyFilterKeyword is returned when registering a custom filter with an empty or blank keyword
+var ErrEmptyFilterKeyword = errors.New("custom filter keyword is empty")
+
 // JSONQuery provides functionality for querying and manipulating JSON data
-type JSONQuery struct{}
+type JSONQuery struct {
+	sqlitePath       string
+	sqliteAppend     bool
+	csvPath          string
+	xlsxPath         string
+	xlsxExports      int // Number of Excel workbooks written, numbering the next ones
+	dataDir          string
+	maxTableWidth    int
+	collator         *collate.Collator
+	dropScrubbed     bool
+	seniorityLevels  []string
+	customFilters    []customFilter
+	lastResultCount  int
+	jsonFields       []string
+	fuzzyMaxDistance int
+	dateFormat       string
+	strictDecoding   bool // Rejects the fields unknown to model.EmployeeInfo, see WithStrictDecoding
+	compactJSON      bool
+	defaultFormat    string
+	staleAfter       time.Duration
+	now              func() time.Time
+	location         *time.Location // Time zone of today for the relative date ranges, see WithLocation
+	logOutput        io.Writer      // Progress messages, see WithLogOutput
+
+	timingsEnabled   bool
+	lastTimings      Timings
+	lastTimingsKnown bool
+}
+
+// customFilter is a filter registered by an integrator, applied when its keyword appears in a query
+type customFilter struct {
+	keyword string
+	pattern *regexp.Regexp
+	fn      func(model.EmployeeInfo) bool
+}
+
+// DefaultSeniorityLevels is the default seniority ordering, from the most junior to the most senior
+var DefaultSeniorityLevels = []string{"junior", "mid", "senior", "staff", "principal", "lead"}
+
+// Option configures a JSONQuery
+type Option func(*JSONQuery)
+
+// validateEmployeesJSON checks that the data is a JSON array of objects, telling empty data and empty arrays apart
+func validateEmployeesJSON(jsonData []byte) error {
+	if len(bytes.TrimSpace(jsonData)) == 0 {
+		return errEmptyInput
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(jsonData, &items); err != nil {
+		return fmt.Errorf("%w: %v", errNotEmployeesArray, err)
+	}
+	if len(items) == 0 {
+		return errNoEmployees
+	}
+
+	for i, item := range items {
+		if !bytes.HasPrefix(bytes.TrimSpace(item), []byte("{")) {
+			return fmt.Errorf("%w: item %d is not an object", errNotEmployeesArray, i+1)
+		}
+	}
+	return nil
+}
+
+// WithSQLiteExport exports the results of each query to the SQLite database at path
+// If appendRows is false, the employees table is replaced on each export
+func WithSQLiteExport(path string, appendRows bool) Option {
+	return func(q *JSONQuery) {
+		q.sqlitePath = path
+		q.sqliteAppend = appendRows
+	}
+}
+
+// WithCSVExport writes the results of each query as CSV to the file at path, replacing it if it exists
+func WithCSVExport(path string) Option {
+	return func(q *JSONQuery) {
+		q.csvPath = path
+	}
+}
+
+// WithDataDir sets the directory of the employees data files, where relative file paths not found
+// from the working directory are looked up (e.g. the file names returned when path redaction is enabled)
+func WithDataDir(dir string) Option {
+	return func(q *JSONQuery) {
+		q.dataDir = dir
+	}
+}
+
+// WithDefaultFormat formats the lists of employees as "json" or "csv" when the query does not ask for a format
+// Other formats are ignored, the lists are then formatted as text
+func WithDefaultFormat(format string) Option {
+	return func(q *JSONQuery) {
+		q.defaultFormat = strings.ToLower(format)
+	}
+}
+
+// WithMaxTableWidth splits markdown tables wider than width characters into several tables
+// A width of 0 disables splitting
+func WithMaxTableWidth(width int) Option {
+	return func(q *JSONQuery) {
+		q.maxTableWidth = width
+	}
+}
+
+// WithCollationLocale sorts names following the collation rules of the given locale (e.g. Swedish, German)
+func WithCollationLocale(locale language.Tag) Option {
+	return func(q *JSONQuery) {
+		q.collator = collate.New(locale, collate.IgnoreCase)
+	}
+}
+
+// WithDropScrubbed drops deactivated employees whose email has been scrubbed from the results
+func WithDropScrubbed(drop bool) Option {
+	return func(q *JSONQuery) {
+		q.dropScrubbed = drop
+	}
+}
+
+// WithSeniorityLevels sets the seniority ordering used by seniority queries, from the most junior to the most senior
+// A "+" after a level in a query (e.g. "staff+ engineers") matches that level and above
+func WithSeniorityLevels(levels []string) Option {
+	return func(q *JSONQuery) {
+		q.seniorityLevels = make([]string, len(levels))
+		for i, level := range levels {
+			q.seniorityLevels[i] = strings.ToLower(strings.TrimSpace(level))
+		}
+	}
+}
+
+// NewJSONQuery creates a new instance of JSONQuery
+// Names are sorted using a root (locale neutral) collation unless WithCollationLocale is used
+func NewJSONQuery(opts ...Option) *JSONQuery {
+	q := &JSONQuery{
+		collator:         collate.New(language.Und, collate.IgnoreCase),
+		seniorityLevels:  DefaultSeniorityLevels,
+		lastResultCount:  -1,
+		staleAfter:       DefaultStaleAfter,
+		fuzzyMaxDistance: DefaultFuzzyMaxDistance,
+		now:              time.Now,
+		location:         time.Local,
+		logOutput:        os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// WithFilter registers a custom filter applied when keyword appears in a query, see RegisterFilter
+// Empty or blank keywords are ignored
+func WithFilter(keyword string, fn func(model.EmployeeInfo) bool) Option {
+	return func(q *JSONQuery) {
+		_ = q.RegisterFilter(keyword, fn)
+	}
+}
+
+// RegisterFilter registers a custom filter applied when keyword appears in a query (e.g. "contractor")
+// The plural form of the keyword also matches. Custom filters are combined with the built-in ones and with
+// each other using AND. Registering a keyword again replaces its filter
+// It returns ErrEmptyFilterKeyword for an empty or blank keyword, which would match every query
+func (q *JSONQuery) RegisterFilter(keyword string, fn func(model.EmployeeInfo) bool) error {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if keyword == "" {
+		return ErrEmptyFilterKeyword
+	}
+	filter := customFilter{
+		keyword: keyword,
+		pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(keyword) + `s?\b`),
+		fn:      fn,
+	}
+
+	for i, existing := range q.customFilters {
+		if existing.keyword == keyword {
+			q.customFilters[i] = filter
+			return nil
+		}
+	}
+	q.customFilters = append(q.customFilters, filter)
+	return nil
+}
+
+// isCustomFilterKeyword determines if word is the keyword of a registered custom filter
+func (q *JSONQuery) isCustomFilterKeyword(word string) bool {
+	for _, filter := range q.customFilters {
+		if filter.pattern.MatchString(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastResultCount returns the number of employees returned by the last query
+// The count is unknown (false) when no query was processed yet or when the last query did not list employees
+func (q *JSONQuery) LastResultCount() (int, bool) {
+	return q.lastResultCount, q.lastResultCount >= 0
+}
+
+// ProcessQuery handles different types of queries on employee data given as a JSON array, see Query
+func (q *JSONQuery) ProcessQuery(jsonData []byte, query string) (string, error) {
+	return q.processQuery(jsonData, "input data", query)
+}
+
+// processQuery runs the query on employee data given as a JSON array, its source being named in the errors
+func (q *JSONQuery) processQuery(jsonData []byte, source, query string) (string, error) {
+	employees, err := q.loadEmployees(jsonData, source)
+	if err != nil {
+		q.logf("🔍 Processing query: %s\n", query)
+		q.resetLastQuery()
+		return fmt.Sprintf("Error: %v", err), err
+	}
+
+	return q.Query(employees, query)
+}
+
+// loadEmployees checks and decodes the employee data given as a JSON array, nil for an empty array
+// The data is checked up front to report malformed input clearly
+func (q *JSONQuery) loadEmployees(jsonData []byte, source string) ([]model.EmployeeInfo, error) {
+	if err := validateEmployeesJSON(jsonData); err != nil {
+		if errors.Is(err, errNoEmployees) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return q.decodeEmployees(jsonData, source)
+}
+
+// Query handles different types of queries on employee data, e.g. "active engineers sorted by last name"
+// The employees are filtered, sorted and formatted like with ProcessQuery, without reading nor decoding any JSON,
+// so that the employees can be queried in memory when embedding this package. The employees are not modified
+func (q *JSONQuery) Query(employees []model.EmployeeInfo, query string) (string, error) {
+	// The count is set when employees are listed
+	q.resetLastQuery()
+
+	// Measure the time spent in each stage if enabled
+	timer := newStageTimer(q.timingsEnabled)
+
+	result, err := q.queryEmployees(employees, query, timer)
+	if err != nil {
+		return q.queryError(err)
+	}
+	q.recordResult(result)
+
+	if result.present != nil {
+		return result.present()
+	}
+	return q.formatList(result, timer)
+}
+
+// queryResult is the outcome of a query before formatting: the employees it lists, and how to present them
+type queryResult struct {
+	// employees are the employees listed by the query, filtered, sorted, offset and limited
+	employees []model.EmployeeInfo
+	// listed is false for the queries not listing employees (e.g. the email uniqueness analysis), whose count is unknown
+	listed bool
+	// present formats the answer of the queries presenting their results their own way (e.g. a count or a specific
+	// employee), nil for the lists of employees formatted as asked for in the query
+	present func() (string, error)
+	// notes are prepended to the formatted list
+	notes []string
+	// query is the lowercased query, which tells the format of the list
+	query string
+	// timings holds the time spent filtering, sorting and limiting the list
+	timings Timings
+}
+
+// answer presents a fixed message as the answer of a query
+func answer(message string) func() (string, error) {
+	return func() (string, error) {
+		return message, nil
+	}
+}
+
+// queryEmployees runs the query on the employees up to the employees it lists, without formatting them
+// It returns an error for the invalid queries (e.g. with an invalid date or regular expression)
+func (q *JSONQuery) queryEmployees(employees []model.EmployeeInfo, query string, timer *stageTimer) (queryResult, error) {
+	q.logf("🔍 Processing query: %s\n", query)
+
+	var timings Timings
+
+	q.logf("📊 Initial dataset: %d employees\n", len(employees))
+	if len(employees) == 0 {
+		return queryResult{listed: true, present: answer(NoEmployeesMessage)}, nil
+	}
+
+	// Work on a copy, so that the employees of the caller are never modified
+	// The specific employee searches look at all the employees, whatever the status filter
+	all := slices.Clone(employees)
+	employees = all
+
+	// Names, titles and emails are matched case-insensitively unless the query says "case sensitive"
+	caseSensitive, query := parseCaseSensitivity(query)
+	if caseSensitive {
+		q.logf("🔡 Case-sensitive matching\n")
+	}
+
+	// Regular expressions keep their case, they are extracted before the query is lowercased
+	regex, query, hasRegex, err := parseRegexFilter(query, caseSensitive)
+	if err != nil {
+		return queryResult{}, err
+	}
+
+	// Convert query to lowercase for case-insensitive matching of the keywords
+	// The values matched in case-sensitive mode are taken back from the original query
+	cased := query
+	query = strings.ToLower(query)
+
+	// The workforce summary is an overview of all the employees, whatever the filters
+	if isSummaryQuery(query) {
+		q.logf("📈 Summarizing %d employees\n", len(employees))
+		return queryResult{present: func() (string, error) {
+			return formatSummary(employees), nil
+		}}, nil
+	}
+
+	// Explicit key:value tokens (e.g. "deactivated:true") take precedence over the keywords
+	tokens, query := parseQueryTokens(query)
+
+	// Compound filters (e.g. "active and title contains engineer") are extracted before the keywords are looked for
+	expression, query, hasExpression := parseFilterExpression(query, cased, caseSensitive)
+
+	// Relative date ranges (e.g. "last 30 days") are extracted too, their numbers are not limits
+	relativeRange, query, hasRelativeRange := parseRelativeDateRange(query, q.today())
+
+	// Names are matched exactly rather than partially when quoted or with the "exact" keyword
+	exact, query := parseExactMatch(query)
+
+	// Apply filters based on query
+	var status string
+	if value, ok := tokens["deactivated"]; ok {
+		deactivated, err := parseBoolToken("deactivated", value)
+		if err != nil {
+			return queryResult{}, err
+		}
+		employees = filterByDeactivated(employees, deactivated)
+		status = "active"
+		if deactivated {
+			status = "deactivated"
+		}
+		q.logf("🔎 Filtered to %s employees (deactivated:%t)\n", status, deactivated)
+	} else if isFutureDeactivationQuery(query) {
+		// Scheduled deactivations may concern employees still active, the status is not filtered
+		q.logf("🔎 Looking for deactivation dates in the future\n")
+	} else if strings.Contains(query, "deactivat") || strings.Contains(query, "terminat") {
+		employees = filterByDeactivated(employees, true)
+		status = "deactivated"
+		q.logf("🔎 Filtered to deactivated employees\n")
+	} else if strings.Contains(query, "active") && !strings.Contains(query, "deactivat") {
+		employees = filterByDeactivated(employees, false)
+		status = "active"
+		q.logf("🔎 Filtered to active employees\n")
+	}
+
+	q.logf("🔎 Found %d employees after filtering\n", len(employees))
+
+	// Check for duplicate/unique emails analysis
+	if q.isEmailUniquenessQuery(query) {
+		q.logf("📧 Analyzing email uniqueness...\n")
+		return queryResult{present: func() (string, error) {
+			return q.formatEmailUniqueness(employees, query)
+		}}, nil
+	}
+
+	// Check for a specific employee identified by email, and optionally name (e.g. "find John Doe john.doe@example.com")
+	if email, ok := parseEmail(query); ok {
+		nameWords := emailNameWords(query, email, caseSensitive, cased)
+		if caseSensitive {
+			email = originalCase(cased, email)
+		}
+		if len(nameWords) == 0 {
+			q.logf("📧 Searching for specific employee with email %s...\n", email)
+			return q.findByEmail(employees, email, caseSensitive), nil
+		}
+		q.logf("📧 Searching for specific employee named %q with email %s...\n", strings.Join(nameWords, " "), email)
+		return q.findByNameAndEmail(employees, nameWords, email, caseSensitive), nil
+	}
+
+	// Check for a search by initials (e.g. "find J.D." or "initials JD")
+	if initials, ok := parseInitials(query); ok {
+		q.logf("🔠 Searching for employees with initials %s...\n", strings.ToUpper(initials))
+		matches := findByInitials(employees, initials)
+		q.logf("🔎 Found %d employees with initials %s\n", len(matches), strings.ToUpper(initials))
+		return queryResult{employees: matches, listed: true, present: func() (string, error) {
+			return q.FormatResults(matches)
+		}}, nil
+	}
+
+	// Check if we need to find a specific employee, regular expression searches, compound filters and relative date
+	// ranges list all the matches
+	if !hasRegex && !hasExpression && !hasRelativeRange && q.isSpecificEmployeeSearch(query) {
+		q.logf("🔍 Searching for specific employee...\n")
+		return q.findSpecificEmployee(all, employees, query, exact, caseSensitive, cased), nil
+	}
+
+	// Notes to prepend to the formatted results
+	var notes []string
+	// Descriptions of the filters applied, used to answer counting queries
+	var qualifiers []string
+
+	// Filter on two-factor authentication status if requested
+	if q.isTwoFactorQuery(query) {
+		enabled := !containsAny(query, "without", "no 2fa", "no two", "not enabled", "not have", "don't have", "disabled", "missing")
+		var unknownCount int
+		employees, unknownCount = filterByTwoFactor(employees, enabled)
+		q.logf("🔐 Filtered to %d employees with 2FA enabled=%t\n", len(employees), enabled)
+		if enabled {
+			qualifiers = append(qualifiers, "with 2FA enabled")
+		} else {
+			qualifiers = append(qualifiers, "without 2FA enabled")
+		}
+
+		if unknownCount > 0 {
+			notes = append(notes, fmt.Sprintf("Note: 2FA status is unknown for %d employees (it is only visible to admin tokens), they are excluded from these results.", unknownCount))
+		}
+	}
+
+	// Filter on the presence fetched from Slack (e.g. "who is online")
+	if presence, ok := parsePresenceFilter(query); ok {
+		var unknownCount int
+		employees, unknownCount = filterByPresence(employees, presence)
+		q.logf("🟢 Filtered to %d employees with presence %s\n", len(employees), presence)
+		if presence == "active" {
+			qualifiers = append(qualifiers, "online")
+		} else {
+			qualifiers = append(qualifiers, "away")
+		}
+
+		if unknownCount > 0 {
+			notes = append(notes, fmt.Sprintf("Note: presence is unknown for %d employees (deactivated, or fetched without their presence), they are excluded from these results.", unknownCount))
+		}
+	}
+
+	// Filter on seniority level (e.g. "senior employees", "staff+ engineers")
+	if filter, ok := q.parseSeniorityFilter(query); ok {
+		employees = q.filterBySeniority(employees, filter)
+		q.logf("🎚️ Filtered to %d employees with seniority %s\n", len(employees), filter)
+		qualifiers = append(qualifiers, q.describeSeniority(filter))
+	}
+
+	// Filter on the role found in the titles (e.g. "active engineers", "deactivated marketing managers")
+	if role, ok := q.parseRoleFilter(query, employees); ok {
+		if caseSensitive {
+			role = originalCase(cased, role)
+		}
+		employees = filterByRole(employees, role, caseSensitive)
+		q.logf("💼 Filtered to %d employees with role %q\n", len(employees), role)
+		qualifiers = append(qualifiers, fmt.Sprintf("with role %q", role))
+	}
+
+	// Filter on the email domain (e.g. "employees with email domain @contractor.com")
+	if domain, ok := parseEmailDomain(query); ok {
+		employees = filterByEmailDomain(employees, domain)
+		q.logf("📧 Filtered to %d employees with an email on %s\n", len(employees), domain)
+		qualifiers = append(qualifiers, "with an email on "+domain)
+	}
+
+	// Filter on whether employees have a title at all (e.g. "employees with no title")
+	if hasTitle, ok := parseTitlePresence(query); ok {
+		var untitled int
+		employees, untitled = filterByTitlePresence(employees, hasTitle)
+		q.logf("🏷️ Filtered to %d employees with title=%t\n", len(employees), hasTitle)
+		if hasTitle {
+			qualifiers = append(qualifiers, "with a title")
+		} else {
+			qualifiers = append(qualifiers, "without a title")
+		}
+		notes = append(notes, fmt.Sprintf("Note: %d employees have no title.", untitled))
+	}
+
+	// Filter on a regular expression on the name or the title (e.g. `regex title "Senior.*Engineer"`)
+	if hasRegex {
+		employees = filterBy(employees, regex.match)
+		q.logf("🔤 Filtered to %d employees with %s\n", len(employees), regex)
+		qualifiers = append(qualifiers, "with "+regex.String())
+	}
+
+	// Filter on the compound filter (e.g. "active and title contains engineer or email domain @acme.com")
+	if hasExpression {
+		employees = filterBy(employees, expression.match)
+		q.logf("🧮 Filtered to %d employees matching %s\n", len(employees), expression)
+		qualifiers = append(qualifiers, "matching "+expression.String())
+	}
+
+	// Filter on an exact deactivation date, given or shared with another employee
+	if name, ok := parseSameDayAs(query); ok {
+		if caseSensitive {
+			name = originalCase(cased, name)
+		}
+		person, found := findByName(employees, name, caseSensitive)
+		if !found {
+			q.logf("❌ Employee %q not found\n", name)
+			return queryResult{listed: true, present: answer(fmt.Sprintf("Employee %q not found in the dataset.", name))}, nil
+		}
+		day, ok := q.deactivationDay(person)
+		if !ok {
+			return queryResult{present: answer(fmt.Sprintf("%s %s has no deactivation date.", person.FirstName, person.LastName))}, nil
+		}
+
+		employees = q.filterByDeactivationDate(employees, day, &person)
+		q.logf("📅 Filtered to %d employees deactivated on %s (same day as %s %s)\n",
+			len(employees), person.DeactivatedDate, person.FirstName, person.LastName)
+		notes = append(notes, fmt.Sprintf("Employees deactivated on %s, the same day as %s %s:",
+			person.DeactivatedDate, person.FirstName, person.LastName))
+	} else if date, ok := parseDeactivationDateOn(query); ok {
+		day, err := parseQueryDate(date)
+		if err != nil {
+			return queryResult{}, err
+		}
+		employees = q.filterByDeactivationDate(employees, day, nil)
+		q.logf("📅 Filtered to %d employees deactivated on %s\n", len(employees), date)
+		qualifiers = append(qualifiers, "deactivated on "+date)
+	} else if year, ok := parseDeactivationYear(query); ok {
+		employees = q.filterByDeactivationYear(employees, year)
+		q.logf("📅 Filtered to %d employees deactivated in %s\n", len(employees), year)
+		qualifiers = append(qualifiers, "deactivated in "+year)
+	}
+
+	// Filter on a range of deactivation dates (e.g. "between 2023-01-01 and 2023-06-30", "after 2023-01-01")
+	dateRange, ok, err := parseDeactivationDateRange(query)
+	if err != nil {
+		return queryResult{}, err
+	}
+	if ok {
+		employees = q.filterByDeactivationDateRange(employees, dateRange)
+		q.logf("📅 Filtered to %d employees deactivated %s\n", len(employees), dateRange)
+		qualifiers = append(qualifiers, "deactivated "+dateRange.String())
+	}
+
+	// Filter on a range of deactivation dates relative to today (e.g. "in the last 7 days", "this month")
+	if hasRelativeRange {
+		employees = q.filterByDeactivationDateRange(employees, relativeRange)
+		q.logf("📅 Filtered to %d employees deactivated %s (%s to %s)\n", len(employees), relativeRange,
+			relativeRange.From.Format(deactivationDateLayout), relativeRange.To.Format(deactivationDateLayout))
+		qualifiers = append(qualifiers, "deactivated "+relativeRange.String())
+	}
+
+	// Filter on the deactivation dates in the future, either scheduled offboardings or bad data
+	if isFutureDeactivationQuery(query) {
+		employees = q.filterFutureDeactivations(employees, q.now())
+		q.logf("📅 Filtered to %d employees with a deactivation date in the future\n", len(employees))
+		qualifiers = append(qualifiers, "with a deactivation date in the future")
+		notes = append(notes, fmt.Sprintf("Deactivation dates after %s, either scheduled offboardings or bad data:", q.now().Format(deactivationDateLayout)))
+	}
+
+	for _, filter := range q.customFilters {
+		if filter.pattern.MatchString(query) {
+			employees = filterBy(employees, filter.fn)
+			q.logf("🧩 Filtered to %d employees matching custom filter %q\n", len(employees), filter.keyword)
+			qualifiers = append(qualifiers, fmt.Sprintf("matching %q", filter.keyword))
+		}
+	}
+
+	// Drop deactivated accounts without email as they are useless in exports
+	if q.dropScrubbed {
+		var dropped int
+		employees, dropped = dropScrubbedEmployees(employees)
+		if dropped > 0 {
+			q.logf("🧹 Dropped %d deactivated employees without email\n", dropped)
+			notes = append(notes, fmt.Sprintf("Note: %d deactivated employees without email were dropped from these results.", dropped))
+		}
+	}
+
+	// The audit report only lists the deactivated employees
+	if q.isAuditReportQuery(query) && !isExcelQuery(query) {
+		employees = filterByDeactivated(employees, true)
+	}
+
+	timings.Filtering = timer.lap()
+
+	// Break the matching employees down by title (e.g. "active employees per title")
+	if isGroupByTitleQuery(query) {
+		q.logf("📋 Grouping employees by title\n")
+		return queryResult{employees: employees, listed: true, present: func() (string, error) {
+			output, err := q.FormatGroupedByTitle(employees)
+			return prependNotes(output, notes), err
+		}}, nil
+	}
+
+	// Answer counting queries with the number of matching employees rather than their list
+	if isCountQuery(query) {
+		q.logf("🔢 Counted %d employees\n", len(employees))
+		return queryResult{employees: employees, listed: true,
+			present: answer(prependNotes(describeCount(len(employees), status, qualifiers), notes))}, nil
+	}
+
+	// Sort on one or more keys (e.g. "sort by title then by deactivation date")
+	if keys := parseSortKeys(query); len(keys) > 0 {
+		q.sortEmployees(employees, keys)
+		q.logf("🔃 Sorted employees by %s\n", keys)
+	}
+
+	timings.Sorting = timer.lap()
+
+	// Skip the first results if requested (e.g. "skip 20", "offset 20", "from 21" or "show 21-40"), before applying the limit
+	offset, rangeLimit := parseOffset(query)
+	if offset > 0 {
+		if offset >= len(employees) {
+			notes = append(notes, fmt.Sprintf("Note: no more results, the offset (%d) is beyond the %d matching employees.", offset, len(employees)))
+			employees = nil
+		} else {
+			employees = employees[offset:]
+		}
+		q.logf("⏭️ Skipped the first %d employees\n", offset)
+	}
+	if rangeLimit > 0 && rangeLimit < len(employees) {
+		employees = employees[:rangeLimit]
+		q.logf("📏 Limited results to %d employees\n", len(employees))
+	}
+
+	// Limit results if needed
+	originalCount := len(employees)
+
+	// Look for patterns like "last 5", "top 10", "first 3", "take 10", "50 employees", etc.
+	// "first" and "earliest" limits apply to the oldest deactivations, as the sort is then ascending
+	words := strings.Fields(query)
+	var limitApplied bool
+
+	// First look for explicit numeric limits
+	for i, word := range words {
+		// Check for "last X", "top X", "latest X", "first X", "earliest X", "take X" patterns
+		if (word == "last" || word == "top" || word == "latest" || word == "first" || word == "earliest" || word == "take") && i+1 < len(words) {
+			// Try to parse the next word as a number
+			if num, err := strconv.Atoi(words[i+1]); err == nil && num > 0 {
+				if num < len(employees) {
+					employees = employees[:num]
+					limitApplied = true
+					break
+				}
+			}
+		}
+
+		// Check for "X employees" pattern, unless X is an offset (e.g. "skip 20 employees")
+		if i+1 < len(words) && (words[i+1] == "employees" || words[i+1] == "employee") &&
+			(i == 0 || (words[i-1] != "skip" && words[i-1] != "offset" && words[i-1] != "from")) {
+			if num, err := strconv.Atoi(word); err == nil && num > 0 {
+				if num < len(employees) {
+					employees = employees[:num]
+					limitApplied = true
+					break
+				}
+			}
+		}
+	}
+
+	if limitApplied && len(employees) < originalCount {
+		q.logf("📏 Limited results to %d employees\n", len(employees))
+	}
+
+	timings.Limiting = timer.lap()
+
+	return queryResult{employees: employees, listed: true, notes: notes, query: query, timings: timings}, nil
+}
+
+// formatList exports the employees listed by the query if configured, then formats them as asked for in the query
+func (q *JSONQuery) formatList(result queryResult, timer *stageTimer) (string, error) {
+	employees, query, timings := result.employees, result.query, result.timings
+
+	// Export the results to SQLite if configured
+	var exportNote string
+	if q.sqlitePath != "" {
+		dbPath, err := export.ToSQLite(q.sqlitePath, employees, q.sqliteAppend)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), err
+		}
+		q.logf("🗄️ Exported %d employees to SQLite database: %s\n", len(employees), dbPath)
+		exportNote = fmt.Sprintf("\nExported %d employees to SQLite database (table %q): %s\n", len(employees), export.SQLiteTable, dbPath)
+	}
+
+	// Export the results to CSV if configured
+	if q.csvPath != "" {
+		csvPath, err := q.exportCSV(employees)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), err
+		}
+		q.logf("📄 Exported %d employees to CSV file: %s\n", len(employees), csvPath)
+		exportNote += fmt.Sprintf("\nExported %d employees to CSV file: %s\n", len(employees), csvPath)
+	}
+
+	// Write the results to an Excel file if configured or asked for, the Excel queries returning its path only
+	var xlsxPath string
+	var err error
+	if q.xlsxPath != "" || isExcelQuery(query) {
+		xlsxPath, err = q.exportXLSX(employees, parseOptionalColumns(query))
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), err
+		}
+		q.logf("📗 Exported %d employees to Excel file: %s\n", len(employees), xlsxPath)
+		if !isExcelQuery(query) {
+			exportNote += fmt.Sprintf("\nExported %d employees to Excel file: %s\n", len(employees), xlsxPath)
+		}
+	}
+
+	timings.Exporting = timer.lap()
+
+	// Format the results
+	var output string
+	q.logf("📝 Formatting results for %d employees\n", len(employees))
+	if isExcelQuery(query) {
+		q.logf("📋 Using Excel format\n")
+		output = fmt.Sprintf("Exported %d employees to Excel file: %s", len(employees), xlsxPath)
+	} else if q.isAuditReportQuery(query) {
+		q.logf("📋 Using audit report format\n")
+		output, err = q.FormatAuditReport(employees)
+	} else if isGroupByYearQuery(query) {
+		q.logf("📋 Using grouped by year format\n")
+		output, err = q.FormatGroupedByYear(employees)
+	} else if strings.Contains(query, "html") {
+		q.logf("📋 Using HTML table format\n")
+		output, err = q.FormatAsHTMLTable(employees)
+	} else if strings.Contains(query, "table") || strings.Contains(query, "markdown") {
+		q.logf("📋 Using markdown table format\n")
+		output, err = q.formatAsMarkdownTable(employees, parseOptionalColumns(query))
+	} else if strings.Contains(query, "csv") {
+		q.logf("📋 Using CSV format\n")
+		output, err = q.FormatAsCSV(employees)
+	} else if isYAMLQuery(query) {
+		q.logf("📋 Using YAML format\n")
+		output, err = q.FormatAsYAML(employees)
+	} else if containsAny(query, "ndjson", "json lines", "jsonl") {
+		q.logf("📋 Using NDJSON format\n")
+		output, err = q.FormatAsNDJSON(employees)
+	} else if strings.Contains(query, "json") {
+		q.logf("📋 Using JSON format\n")
+		output, err = q.FormatAsJSON(employees)
+	} else if q.defaultFormat == "csv" {
+		q.logf("📋 Using CSV format (default format)\n")
+		output, err = q.FormatAsCSV(employees)
+	} else if q.defaultFormat == "json" {
+		q.logf("📋 Using JSON format (default format)\n")
+		output, err = q.FormatAsJSON(employees)
+	} else if isWithIDQuery(query) {
+		q.logf("📋 Using default list format with Slack IDs\n")
+		output, err = q.FormatResultsWithIDs(employees)
+	} else {
+		// Default formatting
+		q.logf("📋 Using default list format\n")
+		output, err = q.FormatResults(employees)
+	}
+
+	timings.Formatting = timer.lap()
+
+	if q.timingsEnabled {
+		q.lastTimings, q.lastTimingsKnown = timings, true
+		q.logf("⏱️ Query timings: %s\n", timings)
+	}
+
+	return prependNotes(output, result.notes) + exportNote, err
+}
+
+var (
+	// deactivationDateOnPattern matches an exact date, e.g. "deactivated on 2023-03-15"
+	deactivationDateOnPattern = regexp.MustCompile(`\bon (\d{4}-\d{2}-\d{2})\b`)
+	// deactivationYearPattern matches a deactivation year, e.g. "deactivated in 2023"
+	deactivationYearPattern = regexp.MustCompile(`\bin (\d{4})\b`)
+	// sameDayAsPattern matches a reference to another employee's deactivation date, e.g. "same day as John Doe"
+	sameDayAsPattern = regexp.MustCompile(`\bsame (?:day|date) as ([^?!.,]+)`)
+)
+
+// countPattern matches the queries asking for a number of employees, e.g. "how many employees are active?"
+var countPattern = regexp.MustCompile(`\bhow many\b|\bcount\b|\bnumber of\b`)
+
+// isCountQuery determines if the lowercased query asks for the number of employees rather than their list
+func isCountQuery(query string) bool {
+	return countPattern.MatchString(query)
+}
+
+// describeCount describes the number of employees matching the filters, e.g. "42 active employees with role \"engineer\""
+func describeCount(count int, status string, qualifiers []string) string {
+	noun := "employees"
+	if count == 1 {
+		noun = "employee"
+	}
+
+	parts := []string{strconv.Itoa(count)}
+	if status != "" {
+		parts = append(parts, status)
+	}
+	parts = append(parts, noun)
+	parts = append(parts, qualifiers...)
+
+	return strings.Join(parts, " ") + "."
+}
+
+// parseDeactivationDateOn extracts the exact deactivation date from the lowercased query
+func parseDeactivationDateOn(query string) (string, bool) {
+	if matches := deactivationDateOnPattern.FindStringSubmatch(query); matches != nil {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// parseDeactivationYear extracts the deactivation year from the lowercased query
+func parseDeactivationYear(query string) (string, bool) {
+	if matches := deactivationYearPattern.FindStringSubmatch(query); matches != nil {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// filterByDeactivationYear keeps the employees deactivated during the given year
+func (q *JSONQuery) filterByDeactivationYear(employees []model.EmployeeInfo, year string) []model.EmployeeInfo {
+	return filterBy(employees, func(emp model.EmployeeInfo) bool {
+		empYear, ok := q.deactivationYear(emp)
+		return ok && empYear == year
+	})
+}
+
+// parseSameDayAs extracts the name of the employee whose deactivation date is referenced in the lowercased query
+func parseSameDayAs(query string) (string, bool) {
+	if matches := sameDayAsPattern.FindStringSubmatch(query); matches != nil {
+		if name := strings.TrimSpace(matches[1]); name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// findByName returns the first employee whose full name contains name, ignoring the case unless caseSensitive is true
+func findByName(employees []model.EmployeeInfo, name string, caseSensitive bool) (model.EmployeeInfo, bool) {
+	for _, emp := range employees {
+		if containsCase(emp.FirstName+" "+emp.LastName, name, caseSensitive) {
+			return emp, true
+		}
+	}
+	return model.EmployeeInfo{}, false
+}
+
+// filterByDeactivationDate keeps the employees deactivated on the given day, excluding the given employee if any
+// Employees without deactivation date never match
+func (q *JSONQuery) filterByDeactivationDate(employees []model.EmployeeInfo, day time.Time, exclude *model.EmployeeInfo) []model.EmployeeInfo {
+	var filtered []model.EmployeeInfo
+	for _, emp := range employees {
+		if empDay, ok := q.deactivationDay(emp); !ok || !empDay.Equal(day) {
+			continue
+		}
+		if exclude != nil && emp == *exclude {
+			continue
+		}
+		filtered = append(filtered, emp)
+	}
+	return filtered
+}
+
+// seniorityAliases maps abbreviations found in titles to seniority levels
+var seniorityAliases = map[string]string{
+	"jr": "junior",
+	"sr": "senior",
+}
+
+// genericNouns are words designating employees in general rather than a role
+var genericNouns = map[string]bool{
+	"employee": true, "employees": true, "people": true, "staff": true, "folks": true,
+	"member": true, "members": true, "person": true, "persons": true, "ones": true,
+}
+
+// seniorityFilter selects employees by seniority level and optionally by role
+type seniorityFilter struct {
+	Level   int    // Index of the level in the seniority ordering
+	AtLeast bool   // Whether higher levels match too
+	Role    string // Optional role the title must contain
+}
+
+// String describes the filter for logging
+func (f seniorityFilter) String() string {
+	desc := fmt.Sprintf("level #%d", f.Level)
+	if f.AtLeast {
+		desc += " and above"
+	}
+	if f.Role != "" {
+		desc += fmt.Sprintf(" (role %q)", f.Role)
+	}
+	return desc
+}
+
+// describeSeniority describes the seniority filter with the names of the levels, e.g. "with seniority staff or above"
+func (q *JSONQuery) describeSeniority(filter seniorityFilter) string {
+	desc := "with seniority " + q.seniorityLevels[filter.Level]
+	if filter.AtLeast {
+		desc += " or above"
+	}
+	if filter.Role != "" {
+		desc += fmt.Sprintf(" and role %q", filter.Role)
+	}
+	return desc
+}
+
+// seniorityLevel returns the index of word in the seniority ordering, or -1 if it is not a level
+func (q *JSONQuery) seniorityLevel(word string) int {
+	if alias, ok := seniorityAliases[word]; ok {
+		word = alias
+	}
+	for i, level := range q.seniorityLevels {
+		if word == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseSeniorityFilter looks for a seniority keyword in the lowercased query, optionally followed by "+" and a role
+// A level keyword only counts when followed by "+" or by another word, so that "list all staff" is not a seniority query
+func (q *JSONQuery) parseSeniorityFilter(query string) (seniorityFilter, bool) {
+	words := strings.Fields(query)
+
+	for i, word := range words {
+		word = strings.TrimRight(word, "?!.,")
+		atLeast := strings.HasSuffix(word, "+")
+		level := q.seniorityLevel(strings.TrimSuffix(word, "+"))
+
+		if level < 0 || (!atLeast && i+1 >= len(words)) {
+			continue
+		}
+
+		filter := seniorityFilter{Level: level, AtLeast: atLeast}
+
+		// The next word is the role, unless it designates employees in general or a custom filter
+		if i+1 < len(words) {
+			next := strings.TrimRight(words[i+1], "?!.,")
+			if !genericNouns[next] && q.seniorityLevel(next) < 0 && !q.isCustomFilterKeyword(next) {
+				filter.Role = strings.TrimSuffix(next, "s")
+			}
+		}
+
+		return filter, true
+	}
+
+	return seniorityFilter{}, false
+}
+
+// titleSeniorityLevels returns the seniority levels found in a title
+func (q *JSONQuery) titleSeniorityLevels(title string) []int {
+	var levels []int
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if level := q.seniorityLevel(word); level >= 0 {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// filterBySeniority keeps the employees whose title matches the seniority filter
+func (q *JSONQuery) filterBySeniority(employees []model.EmployeeInfo, filter seniorityFilter) []model.EmployeeInfo {
+	var filtered []model.EmployeeInfo
+
+	for _, emp := range employees {
+		if filter.Role != "" && !strings.Contains(strings.ToLower(emp.Title), filter.Role) {
+			continue
+		}
+
+		for _, level := range q.titleSeniorityLevels(emp.Title) {
+			if level == filter.Level || (filter.AtLeast && level > filter.Level) {
+				filtered = append(filtered, emp)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// filterBy keeps the employees matching the predicate
+func filterBy(employees []model.EmployeeInfo, keep func(model.EmployeeInfo) bool) []model.EmployeeInfo {
+	var filtered []model.EmployeeInfo
+	for _, emp := range employees {
+		if keep(emp) {
+			filtered = append(filtered, emp)
+		}
+	}
+	return filtered
+}
+
+// filterByDeactivated keeps the deactivated employees, or the active ones if deactivated is false
+func filterByDeactivated(employees []model.EmployeeInfo, deactivated bool) []model.EmployeeInfo {
+	return filterBy(employees, func(emp model.EmployeeInfo) bool { return emp.Deactivated == deactivated })
+}
+
+// titlePresencePattern matches a query on whether employees have a title, e.g. "with no title", "without a title",
+// "missing title", "untitled" or "with a title"
+var titlePresencePattern = regexp.MustCompile(`\b(?:(with|without|no|missing|have|has|having)\s+(?:(no|a|any)\s+)?(?:job\s+)?titles?\b|untitled)`)
+
+// parseTitlePresence extracts from the lowercased query whether employees must have a title or not
+func parseTitlePresence(query string) (hasTitle bool, ok bool) {
+	matches := titlePresencePattern.FindStringSubmatch(query)
+	if matches == nil {
+		return false, false
+	}
+
+	switch {
+	case matches[0] == "untitled", matches[1] == "without", matches[1] == "no", matches[1] == "missing", matches[2] == "no":
+		return false, true
+	default:
+		return true, true
+	}
+}
+
+// filterByTitlePresence keeps the employees with a title, or the ones without if hasTitle is false
+// Whitespace-only titles are considered empty. It also returns the number of employees without title
+func filterByTitlePresence(employees []model.EmployeeInfo, hasTitle bool) ([]model.EmployeeInfo, int) {
+	var filtered []model.EmployeeInfo
+	untitled := 0
+	for _, emp := range employees {
+		titled := strings.TrimSpace(emp.Title) != ""
+		if !titled {
+			untitled++
+		}
+		if titled == hasTitle {
+			filtered = append(filtered, emp)
+		}
+	}
+	return filtered, untitled
+}
 
-// NewJSONQuery creates a new instance of JSONQuery
-func NewJSONQuery() *JSONQuery {
-	return &JSONQuery{}
+// dropScrubbedEmployees removes the deactivated employees with an empty email
+// It returns the remaining employees and the number of dropped ones
+func dropScrubbedEmployees(employees []model.EmployeeInfo) ([]model.EmployeeInfo, int) {
+	kept := make([]model.EmployeeInfo, 0, len(employees))
+	for _, emp := range employees {
+		if emp.Deactivated && strings.TrimSpace(emp.Email) == "" {
+			continue
+		}
+		kept = append(kept, emp)
+	}
+	return kept, len(employees) - len(kept)
 }
 
-// ProcessQuery handles different types of queries on employee data using gojsonq
-func (q *JSONQuery) ProcessQuery(jsonData []byte, query string) (string, error) {
-	fmt.Printf("🔍 Processing query: %s\n", query)
+// sortByName sorts employees by last name then first name using the configured collation
+func (q *JSONQuery) sortByName(employees []model.EmployeeInfo) {
+	q.sortEmployees(employees, []sortKey{{Field: sortFieldName}})
+}
 
-	// Create a new gojsonq instance with the JSON data
-	jq := gojsonq.New().FromString(string(jsonData))
+// prependNotes prefixes the output with the notes gathered while processing a query
+func prependNotes(output string, notes []string) string {
+	if len(notes) == 0 {
+		return output
+	}
+	return strings.Join(notes, "\n") + "\n\n" + output
+}
 
-	// Count total employees before any filtering
-	totalCount := jq.Count()
-	fmt.Printf("📊 Initial dataset: %d employees\n", totalCount)
+// containsAny reports whether the query contains any of the given patterns
+func containsAny(query string, patterns ...string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(query, pattern) {
+			return true
+		}
+	}
+	return false
+}
 
-	// Reset the query to start fresh
-	jq.Reset()
+var (
+	// initialsKeywordPattern matches initials following the "initials" keyword, e.g. "initials JD" or "initials J.D."
+	initialsKeywordPattern = regexp.MustCompile(`\binitials\s+([a-z])\.?\s?([a-z])\.?(?:\s|$|[?!,])`)
+	// dottedInitialsPattern matches initials written with dots, e.g. "J.D." or "J. D."
+	dottedInitialsPattern = regexp.MustCompile(`(?:^|\s)([a-z])\.\s?([a-z])\.?(?:\s|$|[?!,])`)
+)
 
-	// Convert query to lowercase for case-insensitive matching
-	query = strings.ToLower(query)
+// parseInitials extracts first and last name initials from the lowercased query
+func parseInitials(query string) (string, bool) {
+	for _, pattern := range []*regexp.Regexp{initialsKeywordPattern, dottedInitialsPattern} {
+		if matches := pattern.FindStringSubmatch(query); matches != nil {
+			return matches[1] + matches[2], true
+		}
+	}
+	return "", false
+}
 
-	// Apply filters based on query
-	if strings.Contains(query, "deactivat") || strings.Contains(query, "terminat") {
-		jq.Where("deactivated", "=", true)
-		fmt.Println("🔎 Filtered to deactivated employees")
-	} else if strings.Contains(query, "active") && !strings.Contains(query, "deactivat") {
-		jq.Where("deactivated", "=", false)
-		fmt.Println("🔎 Filtered to active employees")
+// findByInitials returns the employees whose first and last name start with the given initials (case-insensitive)
+func findByInitials(employees []model.EmployeeInfo, initials string) []model.EmployeeInfo {
+	initialRunes := []rune(strings.ToLower(initials))
+	if len(initialRunes) != 2 {
+		return nil
 	}
 
-	// Check if we need to find a specific employee
-	if q.isSpecificEmployeeSearch(query) {
-		fmt.Println("🔍 Searching for specific employee...")
-		return q.findSpecificEmployee(jq, query)
+	var matches []model.EmployeeInfo
+	for _, emp := range employees {
+		first := []rune(strings.ToLower(strings.TrimSpace(emp.FirstName)))
+		last := []rune(strings.ToLower(strings.TrimSpace(emp.LastName)))
+		if len(first) > 0 && len(last) > 0 && first[0] == initialRunes[0] && last[0] == initialRunes[1] {
+			matches = append(matches, emp)
+		}
 	}
+	return matches
+}
 
-	// Get the filtered data
-	result := jq.Get()
+// isEmailUniquenessQuery determines if the query is about duplicate (shared) or unique emails
+func (q *JSONQuery) isEmailUniquenessQuery(query string) bool {
+	return strings.Contains(query, "email") && containsAny(query, "duplicate", "shared", "same email", "unique")
+}
 
-	// Convert result to []model.EmployeeInfo
-	var employees []model.EmployeeInfo
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return fmt.Sprintf("Error: %v", err), err
-	}
+// emailCluster is a group of accounts sharing the same normalized email
+type emailCluster struct {
+	Email     string
+	Employees []model.EmployeeInfo
+}
 
-	err = json.Unmarshal(resultBytes, &employees)
-	if err != nil {
-		return fmt.Sprintf("Error: %v", err), err
+// groupByEmail groups employees by normalized (trimmed, lowercased) email, ignoring empty emails
+// It returns the clusters with more than one account, sorted by email, and the number of distinct emails
+func groupByEmail(employees []model.EmployeeInfo) ([]emailCluster, int) {
+	groups := make(map[string][]model.EmployeeInfo)
+	for _, emp := range employees {
+		email := strings.ToLower(strings.TrimSpace(emp.Email))
+		if email == "" {
+			continue
+		}
+		groups[email] = append(groups[email], emp)
 	}
 
-	fmt.Printf("🔎 Found %d employees after filtering\n", len(employees))
+	var duplicates []emailCluster
+	for email, members := range groups {
+		if len(members) > 1 {
+			duplicates = append(duplicates, emailCluster{Email: email, Employees: members})
+		}
+	}
 
-	// Sort by deactivation date if needed
-	if strings.Contains(query, "last") || strings.Contains(query, "recent") ||
-		strings.Contains(query, "sort by date") || strings.Contains(query, "sort by deactivation") {
-		// Sort employees by deactivation date
-		sort.Slice(employees, func(i, j int) bool {
-			dateI := employees[i].DeactivatedDate
-			dateJ := employees[j].DeactivatedDate
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Email < duplicates[j].Email
+	})
 
-			// Handle empty dates
-			if dateI == "" && dateJ == "" {
-				return false
-			}
-			if dateI == "" {
-				return false
-			}
-			if dateJ == "" {
-				return true
-			}
+	return duplicates, len(groups)
+}
 
-			// Parse dates
-			timeI, errI := time.Parse("2006-01-02", dateI)
-			timeJ, errJ := time.Parse("2006-01-02", dateJ)
+// formatEmailUniqueness reports the emails shared by several accounts, or only the unique emails count
+func (q *JSONQuery) formatEmailUniqueness(employees []model.EmployeeInfo, query string) (string, error) {
+	duplicates, uniqueCount := groupByEmail(employees)
 
-			if errI != nil && errJ != nil {
-				return false
-			}
-			if errI != nil {
-				return false
-			}
-			if errJ != nil {
-				return true
-			}
+	// Only the count is wanted
+	if !containsAny(query, "duplicate", "shared", "same email") {
+		return fmt.Sprintf("Found %d unique emails across %d employees.\n", uniqueCount, len(employees)), nil
+	}
 
-			// Sort descending (most recent first)
-			return timeI.After(timeJ)
-		})
-		fmt.Println("📅 Sorted employees by deactivation date (most recent first)")
+	if len(duplicates) == 0 {
+		return fmt.Sprintf("No email is shared by multiple accounts (%d unique emails across %d employees).\n", uniqueCount, len(employees)), nil
 	}
 
-	// Limit results if needed
-	originalCount := len(employees)
+	var result strings.Builder
 
-	// Look for patterns like "last 5", "top 10", "50 employees", etc.
-	words := strings.Fields(query)
-	var limitApplied bool
+	result.WriteString(fmt.Sprintf("Found %d emails shared by multiple accounts (%d unique emails across %d employees):\n\n",
+		len(duplicates), uniqueCount, len(employees)))
 
-	// First look for explicit numeric limits
-	for i, word := range words {
-		// Check for "last X", "top X", "latest X" patterns
-		if (word == "last" || word == "top" || word == "latest") && i+1 < len(words) {
-			// Try to parse the next word as a number
-			if num, err := strconv.Atoi(words[i+1]); err == nil && num > 0 {
-				if num < len(employees) {
-					employees = employees[:num]
-					limitApplied = true
-					break
-				}
-			}
-		}
+	for i, cluster := range duplicates {
+		result.WriteString(fmt.Sprintf("%d. %s (%d accounts)\n", i+1, cluster.Email, len(cluster.Employees)))
 
-		// Check for "X employees" pattern
-		if i+1 < len(words) && (words[i+1] == "employees" || words[i+1] == "employee") {
-			if num, err := strconv.Atoi(word); err == nil && num > 0 {
-				if num < len(employees) {
-					employees = employees[:num]
-					limitApplied = true
-					break
-				}
+		for _, emp := range cluster.Employees {
+			result.WriteString(fmt.Sprintf("   - %s %s", emp.FirstName, emp.LastName))
+			if emp.Title != "" {
+				result.WriteString(fmt.Sprintf(" - %s", emp.Title))
 			}
+			if emp.Deactivated {
+				result.WriteString(" (Deactivated)")
+			}
+			result.WriteString("\n")
 		}
 	}
 
-	if limitApplied && len(employees) < originalCount {
-		fmt.Printf("📏 Limited results to %d employees\n", len(employees))
-	}
+	return result.String(), nil
+}
 
-	// Format the results
-	fmt.Printf("📝 Formatting results for %d employees\n", len(employees))
-	if strings.Contains(query, "table") || strings.Contains(query, "markdown") {
-		fmt.Println("📋 Using markdown table format")
-		return q.FormatAsMarkdownTable(employees)
+// isTwoFactorQuery determines if the query is about two-factor authentication status
+func (q *JSONQuery) isTwoFactorQuery(query string) bool {
+	return containsAny(query, "2fa", "two-factor", "two factor", "mfa")
+}
+
+// filterByTwoFactor keeps the employees whose 2FA status matches enabled
+// Employees with an unknown 2FA status are excluded and counted separately
+func filterByTwoFactor(employees []model.EmployeeInfo, enabled bool) ([]model.EmployeeInfo, int) {
+	var filtered []model.EmployeeInfo
+	unknownCount := 0
+
+	for _, emp := range employees {
+		if emp.Has2FA == nil {
+			unknownCount++
+			continue
+		}
+		if *emp.Has2FA == enabled {
+			filtered = append(filtered, emp)
+		}
 	}
 
-	// Default formatting
-	fmt.Println("📋 Using default list format")
-	return q.FormatResults(employees)
+	return filtered, unknownCount
 }
 
-// findSpecificEmployee searches for a specific employee by name using gojsonq
-func (q *JSONQuery) findSpecificEmployee(jq *gojsonq.JSONQ, query string) (string, error) {
-	// Extract potential names from the query
+// findSpecificEmployee searches for a specific employee by name among all the employees
+// All the employees sharing the name are returned, with their count when there are several.
+// When none matches, the given employees whose names are close to the query are returned (e.g. typos)
+// Names contain the searched ones unless exact is true, e.g. "Ann" matching "Anne" and "Joann"
+func (q *JSONQuery) findSpecificEmployee(all, employees []model.EmployeeInfo, query string, exact, caseSensitive bool, cased string) queryResult {
+	// Extract potential names from the query, as written in case-sensitive mode
 	words := strings.Fields(query)
+	if caseSensitive {
+		for i, word := range words {
+			words[i] = originalCase(cased, word)
+		}
+	}
 
 	// Try different combinations of adjacent words as potential names
 	for i := 0; i < len(words)-1; i++ {
@@ -168,75 +1251,77 @@ func (q *JSONQuery) findSpecificEmployee(jq *gojsonq.JSONQ, query string) (strin
 		potentialLastName := words[i+1]
 
 		// Skip short words and common words that are unlikely to be names
-		if len(potentialFirstName) < 3 || len(potentialLastName) < 3 {
+		if !isNameCandidate(potentialFirstName) || !isNameCandidate(potentialLastName) {
 			continue
 		}
 
-		// Reset query for new search
-		jq.Reset()
-
 		// Search for first name and last name
-		result := jq.OrWhere("first_name", "contains", potentialFirstName).
-			OrWhere("last_name", "contains", potentialLastName).Get()
-
-		// Convert result to []model.EmployeeInfo
-		var employees []model.EmployeeInfo
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
+		employees := filterBy(all, func(emp model.EmployeeInfo) bool {
+			return matchName(emp.FirstName, potentialFirstName, exact, caseSensitive) ||
+				matchName(emp.LastName, potentialLastName, exact, caseSensitive)
+		})
+		if len(employees) == 0 {
 			continue
 		}
 
-		err = json.Unmarshal(resultBytes, &employees)
-		if err != nil || len(employees) == 0 {
-			continue
+		// Prefer the employees matching both names, e.g. every John Smith rather than every John
+		if both := filterBy(employees, func(emp model.EmployeeInfo) bool {
+			return matchName(emp.FirstName, potentialFirstName, exact, caseSensitive) &&
+				matchName(emp.LastName, potentialLastName, exact, caseSensitive)
+		}); len(both) > 0 {
+			employees = both
 		}
 
-		// Found at least one matching employee
-		fmt.Println("✅ Employee found!")
-
-		// Format the first matching employee
-		var resultBuilder strings.Builder
-		emp := employees[0]
-
-		resultBuilder.WriteString(fmt.Sprintf("Employee: %s %s\n", emp.FirstName, emp.LastName))
-
-		if emp.Title != "" {
-			resultBuilder.WriteString(fmt.Sprintf("Title: %s\n", emp.Title))
-		}
+		// Several employees share the name, the user is asked which one is meant
+		return q.nameMatches(employees, potentialFirstName+" "+potentialLastName)
+	}
 
-		if emp.Email != "" {
-			resultBuilder.WriteString(fmt.Sprintf("Email: %s\n", emp.Email))
-		}
+	// Look for a single first or last name (e.g. "find John")
+	if matches, name := findBySingleName(all, words, caseSensitive); len(matches) > 0 {
+		return q.nameMatches(matches, name)
+	}
 
-		if emp.Deactivated {
-			resultBuilder.WriteString("Status: Deactivated\n")
-			if emp.DeactivatedDate != "" {
-				resultBuilder.WriteString(fmt.Sprintf("Deactivation Date: %s\n", emp.DeactivatedDate))
-			}
-		} else {
-			resultBuilder.WriteString("Status: Active\n")
+	// Fall back to approximate matches on the names, unless exact or case-sensitive matches are asked for
+	if !caseSensitive && !exact {
+		if matches := q.findFuzzyMatches(employees, query); len(matches) > 0 {
+			q.logf("🔤 Found %d approximate matches\n", len(matches))
+			return queryResult{employees: fuzzyEmployees(matches), listed: true, present: func() (string, error) {
+				return formatFuzzyMatches(matches), nil
+			}}
 		}
-
-		return resultBuilder.String(), nil
 	}
 
-	fmt.Println("❌ Employee not found")
-	return "Employee not found in the dataset.", nil
+	q.logf("❌ Employee not found\n")
+	return queryResult{listed: true, present: answer("Employee not found in the dataset.")}
 }
 
 // FormatAsMarkdownTable formats the employee data as a markdown table
+// When a maximum table width is set and the table is wider, its columns are split across several tables
 func (q *JSONQuery) FormatAsMarkdownTable(employees []model.EmployeeInfo) (string, error) {
+	return q.formatAsMarkdownTable(employees, nil)
+}
+
+// formatAsMarkdownTable formats the employee data as a markdown table, with the given optional columns
+func (q *JSONQuery) formatAsMarkdownTable(employees []model.EmployeeInfo, extraColumns []optionalColumn) (string, error) {
 	if len(employees) == 0 {
-		return "No employees found matching the criteria.", nil
+		return NoResultsMessage, nil
 	}
 
-	var result strings.Builder
+	// The department column is only shown when some employees have one
+	withDepartment := hasDepartment(employees)
 
-	// Write table header
-	result.WriteString("| Name | Title | Email | Status | Deactivation Date |\n")
-	result.WriteString("|------|-------|-------|--------|------------------|\n")
+	headers := []string{"Name", "Title"}
+	if withDepartment {
+		headers = append(headers, "Department")
+	}
+	headers = append(headers, "Email")
+	for _, column := range extraColumns {
+		headers = append(headers, column.header)
+	}
+	headers = append(headers, "Status", "Deactivation Date")
 
-	// Write table rows
+	// Build table rows
+	rows := make([][]string, 0, len(employees))
 	for _, emp := range employees {
 		name := emp.FirstName + " " + emp.LastName
 
@@ -248,15 +1333,293 @@ func (q *JSONQuery) FormatAsMarkdownTable(employees []model.EmployeeInfo) (strin
 			deactivationDate = emp.DeactivatedDate
 		}
 
-		result.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
-			name, emp.Title, emp.Email, status, deactivationDate))
+		row := []string{name, emp.Title}
+		if withDepartment {
+			row = append(row, emp.Department)
+		}
+		row = append(row, emp.Email)
+		for _, column := range extraColumns {
+			row = append(row, column.value(emp))
+		}
+		rows = append(rows, append(row, status, deactivationDate))
+	}
+
+	// Group columns so that each table fits the maximum width
+	allColumns := make([]int, len(headers))
+	for i := range headers {
+		allColumns[i] = i
+	}
+	groups := [][]int{allColumns}
+	if q.maxTableWidth > 0 {
+		groups = splitColumns(columnWidths(headers, rows), q.maxTableWidth)
+	}
+
+	var result strings.Builder
+
+	for i, group := range groups {
+		if len(groups) > 1 {
+			if i > 0 {
+				result.WriteString("\n")
+			}
+			groupHeaders := make([]string, len(group))
+			for j, col := range group {
+				groupHeaders[j] = headers[col]
+			}
+			result.WriteString(fmt.Sprintf("**Part %d of %d**: %s\n\n", i+1, len(groups), strings.Join(groupHeaders, ", ")))
+		}
+
+		writeMarkdownTable(&result, headers, rows, group)
 	}
 
 	return result.String(), nil
 }
 
+// hasDepartment determines if some employees have a department
+func hasDepartment(employees []model.EmployeeInfo) bool {
+	return slices.ContainsFunc(employees, func(emp model.EmployeeInfo) bool { return emp.Department != "" })
+}
+
+// writeMarkdownTable writes a markdown table made of the given columns only
+func writeMarkdownTable(result *strings.Builder, headers []string, rows [][]string, columns []int) {
+	// Write table header
+	for _, col := range columns {
+		result.WriteString("| " + headers[col] + " ")
+	}
+	result.WriteString("|\n")
+	for _, col := range columns {
+		result.WriteString("|" + strings.Repeat("-", utf8.RuneCountInString(headers[col])+2))
+	}
+	result.WriteString("|\n")
+
+	// Write table rows
+	for _, row := range rows {
+		for _, col := range columns {
+			result.WriteString("| " + row[col] + " ")
+		}
+		result.WriteString("|\n")
+	}
+}
+
+// columnWidths computes the width of each column, i.e. the widest of its header and cells
+func columnWidths(headers []string, rows [][]string) []int {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths
+}
+
+// splitColumns groups consecutive columns so that each group renders within maxWidth
+// The first column (the name) starts every group so that each row can be identified, every other column belongs to
+// exactly one group, a column too wide to fit next to the first one gets a group of its own with it
+func splitColumns(widths []int, maxWidth int) [][]int {
+	if len(widths) == 0 {
+		return nil
+	}
+
+	// Each cell renders as "| <cell> ", plus the closing "|" of each row
+	firstWidth := 1 + widths[0] + 3
+
+	var groups [][]int
+	current := []int{0}
+	currentWidth := firstWidth
+
+	for col := 1; col < len(widths); col++ {
+		cellWidth := widths[col] + 3
+		if len(current) > 1 && currentWidth+cellWidth > maxWidth {
+			groups = append(groups, current)
+			current = []int{0}
+			currentWidth = firstWidth
+		}
+		current = append(current, col)
+		currentWidth += cellWidth
+	}
+
+	return append(groups, current)
+}
+
+var (
+	// offsetPattern matches an offset, e.g. "skip 20" or "offset 20" (skips 20 results) or "from 21" (starts at the 21st result)
+	offsetPattern = regexp.MustCompile(`\b(skip|offset|from)\s+(\d+)(?:\s|$|[?!,.])`)
+	// rangePattern matches a range of results, e.g. "show 21-40" or "results 21 to 40"
+	rangePattern = regexp.MustCompile(`(?:^|\s)(\d+)\s*(?:-|to)\s*(\d+)(?:\s|$|[?!,.])`)
+)
+
+// parseOffset extracts the number of results to skip from the lowercased query
+// A range of results (e.g. "21-40") also sets the number of results to return, 0 otherwise
+func parseOffset(query string) (offset, limit int) {
+	if matches := rangePattern.FindStringSubmatch(query); matches != nil {
+		first, _ := strconv.Atoi(matches[1])
+		last, _ := strconv.Atoi(matches[2])
+		if first >= 1 && last >= first {
+			return first - 1, last - first + 1
+		}
+	}
+
+	if matches := offsetPattern.FindStringSubmatch(query); matches != nil {
+		n, _ := strconv.Atoi(matches[2])
+		if matches[1] == "from" {
+			// Results are numbered from 1
+			return max(n-1, 0), 0
+		}
+		return n, 0
+	}
+
+	return 0, 0
+}
+
+// emailPattern matches an email address in the lowercased query
+var emailPattern = regexp.MustCompile(`[a-z0-9._%+'-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+
+// nameFillerWords are the words of a name and email search that are not part of the name
+var nameFillerWords = map[string]bool{
+	"find": true, "search": true, "look": true, "for": true, "locate": true, "get": true, "info": true, "on": true,
+	"who": true, "is": true, "when": true, "was": true, "did": true, "what": true, "date": true,
+	"information": true, "details": true, "about": true, "employee": true, "the": true, "named": true,
+	"with": true, "and": true, "email": true, "e-mail": true, "address": true, "whose": true, "has": true,
+	"deactivated": true, "terminated": true, "active": true, "leave": true, "left": true,
+	"list": true, "show": true, "me": true, "all": true, "employees": true, "user": true, "users": true,
+	"account": true, "accounts": true, "of": true, "by": true,
+}
+
+// parseEmail extracts the email address from the lowercased query
+func parseEmail(query string) (string, bool) {
+	email := emailPattern.FindString(query)
+	return email, email != ""
+}
+
+// emailNameWords returns the words of the lowercased query around the email, that make up the name given with it
+// In case-sensitive mode, they are returned as written in the original (cased) query
+func emailNameWords(query, email string, caseSensitive bool, cased string) []string {
+	var nameWords []string
+	for _, word := range strings.Fields(strings.Replace(query, email, " ", 1)) {
+		word = strings.Trim(word, "?!.,:;()\"'")
+		if word != "" && !nameFillerWords[word] {
+			if caseSensitive {
+				word = originalCase(cased, word)
+			}
+			nameWords = append(nameWords, word)
+		}
+	}
+	return nameWords
+}
+
+// findByEmail finds the employee with the email, ignoring the case unless caseSensitive is true
+func (q *JSONQuery) findByEmail(employees []model.EmployeeInfo, email string, caseSensitive bool) queryResult {
+	matches := filterBy(employees, func(emp model.EmployeeInfo) bool { return equalCase(emp.Email, email, caseSensitive) })
+	if len(matches) == 0 {
+		q.logf("❌ Employee not found\n")
+		return queryResult{listed: true, present: answer(fmt.Sprintf("No employee found with email %s.", email))}
+	}
+	return q.foundByEmail(matches)
+}
+
+// findByNameAndEmail finds the employee matching both the email and the name words given with it
+// The email is the stronger key: the name only narrows down the accounts sharing the email
+func (q *JSONQuery) findByNameAndEmail(employees []model.EmployeeInfo, nameWords []string, email string, caseSensitive bool) queryResult {
+	result := q.findByEmail(employees, email, caseSensitive)
+	if len(result.employees) == 0 {
+		return result
+	}
+
+	matches := filterBy(result.employees, func(emp model.EmployeeInfo) bool { return matchesNameWords(emp, nameWords, caseSensitive) })
+	if len(matches) == 0 {
+		q.logf("❌ Employee not found\n")
+		name := strings.Join(nameWords, " ")
+		return queryResult{listed: true, present: answer(fmt.Sprintf("No employee named %q found with email %s.", name, email))}
+	}
+	return q.foundByEmail(matches)
+}
+
+// foundByEmail returns the result of the employees found by email
+// Several accounts may share the email (e.g. reactivated accounts), in which case they are all listed
+func (q *JSONQuery) foundByEmail(matches []model.EmployeeInfo) queryResult {
+	if len(matches) == 1 {
+		q.logf("✅ Employee found!\n")
+		return queryResult{employees: matches, listed: true, present: func() (string, error) {
+			return formatEmployee(matches[0]), nil
+		}}
+	}
+	return queryResult{employees: matches, listed: true, present: func() (string, error) {
+		return q.FormatResults(matches)
+	}}
+}
+
+// matchesNameWords determines if every word is the first or last name of the employee,
+// ignoring the case unless caseSensitive is true
+func matchesNameWords(emp model.EmployeeInfo, words []string, caseSensitive bool) bool {
+	names := strings.Fields(emp.FirstName + " " + emp.LastName)
+	for _, word := range words {
+		found := false
+		for _, name := range names {
+			if equalCase(name, word, caseSensitive) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// formatEmployee formats the details of a single employee
+func formatEmployee(emp model.EmployeeInfo) string {
+	var resultBuilder strings.Builder
+
+	if emp.IsBot {
+		resultBuilder.WriteString(fmt.Sprintf("Employee: %s %s (bot)\n", emp.FirstName, emp.LastName))
+	} else {
+		resultBuilder.WriteString(fmt.Sprintf("Employee: %s %s\n", emp.FirstName, emp.LastName))
+	}
+
+	if emp.Title != "" {
+		resultBuilder.WriteString(fmt.Sprintf("Title: %s\n", emp.Title))
+	}
+
+	if emp.Email != "" {
+		resultBuilder.WriteString(fmt.Sprintf("Email: %s\n", emp.Email))
+	}
+
+	if emp.Deactivated {
+		resultBuilder.WriteString("Status: Deactivated\n")
+		if emp.DeactivatedDate != "" {
+			resultBuilder.WriteString(fmt.Sprintf("Deactivation Date: %s\n", emp.DeactivatedDate))
+		}
+	} else {
+		resultBuilder.WriteString("Status: Active\n")
+	}
+
+	return resultBuilder.String()
+}
+
 // isSpecificEmployeeSearch determines if the query is looking for a specific person
 func (q *JSONQuery) isSpecificEmployeeSearch(query string) bool {
+	// Queries on deactivation dates list several employees, even if they reference one
+	if _, ok := parseSameDayAs(query); ok {
+		return false
+	}
+	if _, ok := parseDeactivationDateOn(query); ok {
+		return false
+	}
+	if _, ok, err := parseDeactivationDateRange(query); ok || err != nil {
+		return false
+	}
+	if _, ok := parseEmailDomain(query); ok {
+		return false
+	}
+	if _, ok := parsePresenceFilter(query); ok {
+		return false
+	}
+
 	// Common patterns for specific employee searches
 	specificPatterns := []string{
 		"when was", "when did", "what date", "who is", "information about", "details for", "details about",
@@ -285,10 +1648,69 @@ func (q *JSONQuery) isSpecificEmployeeSearch(query string) bool {
 	return false
 }
 
+// FormatAsCSV formats the employee data as comma-separated values with a header row
+// Fields are escaped by encoding/csv, empty deactivation dates are written as empty cells
+func (q *JSONQuery) FormatAsCSV(employees []model.EmployeeInfo) (string, error) {
+	var result strings.Builder
+	writer := csv.NewWriter(&result)
+
+	records := [][]string{{"First Name", "Last Name", "Email", "Title", "Status", "Deactivation Date"}}
+	for _, emp := range employees {
+		status := "Active"
+		if emp.Deactivated {
+			status = "Deactivated"
+		}
+		records = append(records, []string{emp.FirstName, emp.LastName, emp.Email, emp.Title, status, emp.DeactivatedDate})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Sprintf("Error: %v", err), err
+	}
+
+	return result.String(), nil
+}
+
+// exportCSV writes the employees as CSV to the configured file and returns its absolute path
+func (q *JSONQuery) exportCSV(employees []model.EmployeeInfo) (string, error) {
+	absPath, err := filepath.Abs(q.csvPath)
+	if err != nil {
+		absPath = q.csvPath // Fall back to given path if absolute fails
+	}
+
+	content, err := q.FormatAsCSV(employees)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(absPath, []byte(content), dataFilePerm); err != nil {
+		return "", fmt.Errorf("failed to write CSV file %s: %v", absPath, err)
+	}
+
+	return absPath, nil
+}
+
 // FormatResults formats the employee data as a simple text list
 func (q *JSONQuery) FormatResults(employees []model.EmployeeInfo) (string, error) {
+	return q.formatResults(employees, false)
+}
+
+// FormatResultsWithIDs formats the employee data as a simple text list, with the Slack ID of each employee
+func (q *JSONQuery) FormatResultsWithIDs(employees []model.EmployeeInfo) (string, error) {
+	return q.formatResults(employees, true)
+}
+
+// withIDPattern matches the queries asking for the Slack IDs of the employees, e.g. "with id" or "with slack ids"
+var withIDPattern = regexp.MustCompile(`\bwith (?:their )?(?:slack |user )?ids?\b`)
+
+// isWithIDQuery determines if the lowercased query asks for the Slack IDs of the employees
+func isWithIDQuery(query string) bool {
+	return withIDPattern.MatchString(query)
+}
+
+// formatResults formats the employee data as a simple text list, with the Slack IDs if showIDs is true
+func (q *JSONQuery) formatResults(employees []model.EmployeeInfo, showIDs bool) (string, error) {
 	if len(employees) == 0 {
-		return "No employees found matching the criteria.", nil
+		return NoResultsMessage, nil
 	}
 
 	var result strings.Builder
@@ -296,22 +1718,54 @@ func (q *JSONQuery) FormatResults(employees []model.EmployeeInfo) (string, error
 	result.WriteString(fmt.Sprintf("Found %d employees:\n\n", len(employees)))
 
 	for i, emp := range employees {
-		result.WriteString(fmt.Sprintf("%d. %s %s", i+1, emp.FirstName, emp.LastName))
-
-		if emp.Title != "" {
-			result.WriteString(fmt.Sprintf(" - %s", emp.Title))
+		line := formatEmployeeLine(emp)
+		if showIDs && emp.SlackID != "" {
+			line += " - Slack ID: " + emp.SlackID
 		}
+		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, line))
+	}
 
-		if emp.Deactivated {
-			if emp.DeactivatedDate != "" {
-				result.WriteString(fmt.Sprintf(" (Deactivated on %s)", emp.DeactivatedDate))
-			} else {
-				result.WriteString(" (Deactivated)")
-			}
+	return result.String(), nil
+}
+
+// formatEmployeeLine formats an employee on a single line, e.g. "John Doe - Engineer (Deactivated on 2023-03-15)"
+func formatEmployeeLine(emp model.EmployeeInfo) string {
+	var line strings.Builder
+	line.WriteString(fmt.Sprintf("%s %s", emp.FirstName, emp.LastName))
+	if emp.IsBot {
+		line.WriteString(" (bot)")
+	}
+
+	if emp.Title != "" {
+		line.WriteString(fmt.Sprintf(" - %s", emp.Title))
+	}
+
+	if emp.Department != "" {
+		line.WriteString(fmt.Sprintf(" [%s]", emp.Department))
+	}
+
+	if emp.Deactivated {
+		if emp.DeactivatedDate != "" {
+			line.WriteString(fmt.Sprintf(" (Deactivated on %s)", emp.DeactivatedDate))
+		} else {
+			line.WriteString(" (Deactivated)")
 		}
+	} else if emp.DeactivatedDate != "" {
+		line.WriteString(fmt.Sprintf(" (Deactivation scheduled on %s)", emp.DeactivatedDate))
+	}
 
-		result.WriteString("\n")
+	if emp.Phone != "" {
+		line.WriteString(fmt.Sprintf(" - Phone: %s", emp.Phone))
+	}
+	if emp.Timezone != "" {
+		line.WriteString(fmt.Sprintf(" - Timezone: %s", emp.Timezone))
+	}
+	if emp.StatusText != "" {
+		line.WriteString(fmt.Sprintf(" - Status: %s", emp.StatusText))
+	}
+	if emp.Presence != "" {
+		line.WriteString(fmt.Sprintf(" - Presence: %s", emp.Presence))
 	}
 
-	return result.String(), nil
+	return line.String()
 }
@@ -0,0 +1,96 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// ambiguousMatchThreshold is the number of employees above which a name search is ambiguous, the user being
+// asked which one is meant
+const ambiguousMatchThreshold = 1
+
+// nameSearchWords are the words of the specific employee searches that are never names, e.g. "find" or "about"
+var nameSearchWords = map[string]bool{
+	"find": true, "search": true, "look": true, "locate": true, "tell": true, "info": true, "information": true,
+	"about": true, "details": true, "did": true, "employee": true, "employees": true, "person": true, "someone": true,
+	"named": true, "called": true,
+}
+
+// queryWords are the words of the queries and their options, e.g. "list" or "active", that are never names
+var queryWords = map[string]bool{
+	"a": true, "all": true, "an": true, "and": true, "are": true, "as": true, "by": true, "for": true, "from": true,
+	"in": true, "is": true, "me": true, "of": true, "on": true, "or": true, "the": true, "their": true, "then": true,
+	"to": true, "was": true, "were": true, "with": true, "without": true, "who": true, "which": true, "what": true,
+	"when": true, "how": true, "many": true, "much": true, "list": true, "show": true, "give": true, "get": true,
+	"display": true, "count": true, "number": true, "active": true, "deactivated": true, "deactivation": true,
+	"terminated": true, "deleted": true, "current": true, "currently": true, "former": true, "users": true,
+	"last": true, "latest": true, "recent": true, "recently": true, "top": true, "first": true, "oldest": true,
+	"newest": true, "sort": true, "sorted": true, "order": true, "alphabetical": true, "name": true, "names": true,
+	"title": true, "titles": true, "date": true, "table": true, "markdown": true, "csv": true, "json": true,
+	"ndjson": true, "skip": true, "offset": true, "take": true, "results": true, "have": true, "has": true, "no": true, "not": true, "don't": true,
+	"2fa": true, "enabled": true, "disabled": true, "please": true, "year": true, "per": true, "future": true, "scheduled": true, "upcoming": true, "there": true, "any": true, "only": true,
+	"email": true, "emails": true, "domain": true, "group": true, "grouped": true, "breakdown": true, "broken": true, "down": true,
+	"ids": true, "slack": true, "user": true, "html": true,
+	"phone": true, "phones": true, "timezone": true, "timezones": true, "tz": true, "status": true, "text": true,
+	"bot": true, "bots": true, "include": true, "including": true,
+	"online": true, "offline": true, "away": true, "presence": true,
+	"regex": true, "matching": true, "match": true, "case": true, "sensitive": true,
+	"excel": true, "xlsx": true, "spreadsheet": true, "spreadsheets": true,
+	"yaml": true, "yml": true,
+	"contains": true, "contain": true, "where": true, "whose": true,
+}
+
+// isNameCandidate determines if a word of the query may be a first or last name
+func isNameCandidate(word string) bool {
+	word = strings.ToLower(word)
+	return len(word) >= 3 && !nameSearchWords[word] && !queryWords[word]
+}
+
+// findBySingleName returns the employees whose first or last name is one of the words of the query, for the
+// searches on a single name (e.g. "find John"), the first word matching employees winning
+func findBySingleName(employees []model.EmployeeInfo, words []string, caseSensitive bool) ([]model.EmployeeInfo, string) {
+	for _, word := range words {
+		if !isNameCandidate(word) {
+			continue
+		}
+		matches := filterBy(employees, func(emp model.EmployeeInfo) bool {
+			return equalCase(emp.FirstName, word, caseSensitive) || equalCase(emp.LastName, word, caseSensitive)
+		})
+		if len(matches) > 0 {
+			return matches, word
+		}
+	}
+	return nil, ""
+}
+
+// nameMatches returns the employees matching the searched name, presented by formatNameMatches
+func (q *JSONQuery) nameMatches(employees []model.EmployeeInfo, name string) queryResult {
+	return queryResult{employees: employees, listed: true, present: func() (string, error) {
+		return q.formatNameMatches(employees, name), nil
+	}}
+}
+
+// formatNameMatches formats the employees matching the searched name, asking which one is meant when the search
+// is ambiguous: the matches are listed with their title and email, so that the user can tell them apart
+func (q *JSONQuery) formatNameMatches(employees []model.EmployeeInfo, name string) string {
+	if len(employees) <= ambiguousMatchThreshold {
+		q.logf("✅ Employee found!\n")
+		return formatEmployee(employees[0])
+	}
+
+	q.logf("❓ Found %d employees matching %q, asking which one\n", len(employees), name)
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d employees matching %q, which one do you mean?\n", len(employees), name))
+	for i, emp := range employees {
+		result.WriteString(fmt.Sprintf("\n%d. %s", i+1, formatEmployee(emp)))
+	}
+
+	example := employees[0].FirstName + " " + employees[0].LastName
+	if employees[0].Email != "" {
+		example += " " + employees[0].Email
+	}
+	result.WriteString(fmt.Sprintf("\nPlease be more specific, with the full name or the email (e.g. \"find %s\").", example))
+	return result.String()
+}
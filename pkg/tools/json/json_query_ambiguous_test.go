@@ -0,0 +1,63 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestAmbiguousNameSearch(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Email: "john.smith@corp.com", Title: "Software Engineer"},
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@corp.com", Title: "Sales Lead"},
+		{FirstName: "John", LastName: "Poe", Email: "jpoe@corp.com", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Elton", LastName: "Johnson", Email: "elton.johnson@corp.com"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@corp.com"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery(WithLogOutput(nil))
+
+	// The user is asked which John is meant, rather than given one of them
+	output, err := q.ProcessQuery(data, "Find John")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	for _, expected := range []string{
+		`Found 3 employees matching "john", which one do you mean?`,
+		"1. Employee: John Smith\nTitle: Software Engineer\nEmail: john.smith@corp.com",
+		"2. Employee: John Doe\nTitle: Sales Lead\nEmail: john.doe@corp.com",
+		"3. Employee: John Poe\nEmail: jpoe@corp.com",
+		`Please be more specific, with the full name or the email (e.g. "find John Smith john.smith@corp.com").`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"Elton Johnson", "Jane Roe"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Unexpected %s in output:\n%s", unexpected, output)
+		}
+	}
+	if count, _ := q.LastResultCount(); count != 3 {
+		t.Errorf("Expected a result count of 3, got %d", count)
+	}
+
+	// A name matching a single employee is not ambiguous
+	output, err = q.ProcessQuery(data, "Find Smith")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.HasPrefix(output, "Employee: John Smith\n") || strings.Contains(output, "which one") {
+		t.Errorf("Expected John Smith alone, got:\n%s", output)
+	}
+
+	// The full name picks the employee
+	output, err = q.ProcessQuery(data, "Find John Doe")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.HasPrefix(output, "Employee: John Doe\n") {
+		t.Errorf("Expected John Doe alone, got:\n%s", output)
+	}
+}
@@ -0,0 +1,96 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// WithClock sets the function returning the current time, used for the generation metadata of the reports
+func WithClock(now func() time.Time) Option {
+	return func(q *JSONQuery) {
+		q.now = now
+	}
+}
+
+// isAuditReportQuery determines if the query asks for the deactivation audit report
+func (q *JSONQuery) isAuditReportQuery(query string) bool {
+	return strings.Contains(query, "audit report") ||
+		(strings.Contains(query, "audit") && strings.Contains(query, "report"))
+}
+
+// FormatAuditReport formats the deactivated employees as an audit report for compliance filings
+// The report starts with its generation metadata and total count, then has one section per deactivated employee
+// stating whether the deactivation date is estimated or verified. Active employees are left out
+func (q *JSONQuery) FormatAuditReport(employees []model.EmployeeInfo) (string, error) {
+	var deactivated []model.EmployeeInfo
+	estimated, verified, unknown := 0, 0, 0
+	for _, emp := range employees {
+		if !emp.Deactivated {
+			continue
+		}
+		deactivated = append(deactivated, emp)
+
+		switch dateSource(emp) {
+		case model.DateSourceVerified:
+			verified++
+		case model.DateSourceEstimated:
+			estimated++
+		default:
+			unknown++
+		}
+	}
+
+	var result strings.Builder
+
+	result.WriteString("# Deactivation Audit Report\n\n")
+	result.WriteString(fmt.Sprintf("- Generated at: %s\n", q.now().UTC().Format(time.RFC3339)))
+	result.WriteString(fmt.Sprintf("- Deactivated employees: %d\n", len(deactivated)))
+	result.WriteString(fmt.Sprintf("- Deactivation dates: %d verified, %d estimated, %d unknown\n", verified, estimated, unknown))
+
+	if len(deactivated) == 0 {
+		result.WriteString("\n" + NoResultsMessage + "\n")
+		return result.String(), nil
+	}
+
+	for i, emp := range deactivated {
+		result.WriteString(fmt.Sprintf("\n## %d. %s %s\n\n", i+1, emp.FirstName, emp.LastName))
+		result.WriteString(fmt.Sprintf("- Name: %s %s\n", emp.FirstName, emp.LastName))
+		result.WriteString(fmt.Sprintf("- Email: %s\n", valueOrNotAvailable(emp.Email)))
+		result.WriteString(fmt.Sprintf("- Title: %s\n", valueOrNotAvailable(emp.Title)))
+		result.WriteString(fmt.Sprintf("- Deactivation date: %s\n", valueOrNotAvailable(emp.DeactivatedDate)))
+		result.WriteString(fmt.Sprintf("- Date source: %s\n", describeDateSource(emp)))
+	}
+
+	return result.String(), nil
+}
+
+// dateSource returns the source of the deactivation date of the employee, empty when unknown
+func dateSource(emp model.EmployeeInfo) string {
+	if emp.DeactivatedDate == "" {
+		return ""
+	}
+	return emp.DeactivatedDateSource
+}
+
+// describeDateSource explains where the deactivation date of the employee comes from
+func describeDateSource(emp model.EmployeeInfo) string {
+	switch dateSource(emp) {
+	case model.DateSourceVerified:
+		return "verified (reported by Slack)"
+	case model.DateSourceEstimated:
+		return "estimated (from the last profile update)"
+	default:
+		return "unknown"
+	}
+}
+
+// valueOrNotAvailable returns the value, or "n/a" when it is empty
+func valueOrNotAvailable(value string) string {
+	if value == "" {
+		return "n/a"
+	}
+	return value
+}
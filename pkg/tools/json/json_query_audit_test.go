@@ -0,0 +1,59 @@
+package json
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestFormatAuditReport(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Software Engineer", Deactivated: true,
+			DeactivatedDate: "2023-03-15", DeactivatedDateSource: model.DateSourceEstimated},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true,
+			DeactivatedDate: "2023-04-01", DeactivatedDateSource: model.DateSourceVerified},
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@example.com", Deactivated: true},
+	}
+
+	clock := func() time.Time { return time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC) }
+	q := NewJSONQuery(WithClock(clock))
+
+	output, err := q.ProcessQuery(mustMarshal(t, employees), "Generate the audit report")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	// Generation metadata and total count
+	header := "# Deactivation Audit Report\n\n" +
+		"- Generated at: 2024-05-01T10:30:00Z\n" +
+		"- Deactivated employees: 3\n" +
+		"- Deactivation dates: 1 verified, 1 estimated, 1 unknown\n"
+	if !strings.HasPrefix(output, header) {
+		t.Errorf("Expected header:\n%s\ngot:\n%s", header, output)
+	}
+
+	// One section per deactivated employee
+	sections := []string{
+		"## 1. John Doe\n\n- Name: John Doe\n- Email: john.doe@example.com\n- Title: Software Engineer\n" +
+			"- Deactivation date: 2023-03-15\n- Date source: estimated (from the last profile update)\n",
+		"## 2. Max Poe\n\n- Name: Max Poe\n- Email: n/a\n- Title: n/a\n" +
+			"- Deactivation date: 2023-04-01\n- Date source: verified (reported by Slack)\n",
+		"## 3. Ann Lee\n\n- Name: Ann Lee\n- Email: ann.lee@example.com\n- Title: n/a\n" +
+			"- Deactivation date: n/a\n- Date source: unknown\n",
+	}
+	for _, section := range sections {
+		if !strings.Contains(output, section) {
+			t.Errorf("Expected section:\n%s\ngot:\n%s", section, output)
+		}
+	}
+
+	if strings.Contains(output, "Jane Roe") {
+		t.Errorf("Active employees must not be in the audit report:\n%s", output)
+	}
+	if count, _ := q.LastResultCount(); count != 3 {
+		t.Errorf("Expected a result count of 3, got %d", count)
+	}
+}
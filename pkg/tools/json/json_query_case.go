@@ -0,0 +1,47 @@
+package json
+
+import (
+	"regexp"
+	"strings"
+)
+
+// caseSensitivePattern matches the queries asking for case-sensitive matching, e.g. "case sensitive"
+var caseSensitivePattern = regexp.MustCompile(`(?i)\bcase[\s-]sensitive\b`)
+
+// parseCaseSensitivity determines if the query asks for case-sensitive matching of the names, titles and emails
+// It returns the query without the keyword, in its original case
+func parseCaseSensitivity(query string) (bool, string) {
+	if !caseSensitivePattern.MatchString(query) {
+		return false, query
+	}
+	return true, strings.Join(strings.Fields(caseSensitivePattern.ReplaceAllString(query, "")), " ")
+}
+
+// originalCase returns the value as written in the query, the value being taken from the lowercased query
+// The value is returned as is when it cannot be found, e.g. when lowercasing changed the length of the query
+func originalCase(query, value string) string {
+	lower := strings.ToLower(query)
+	if len(lower) != len(query) {
+		return value
+	}
+	if i := strings.Index(lower, value); i >= 0 {
+		return query[i : i+len(value)]
+	}
+	return value
+}
+
+// containsCase determines if s contains substr, ignoring the case unless caseSensitive is true
+func containsCase(s, substr string, caseSensitive bool) bool {
+	if caseSensitive {
+		return strings.Contains(s, substr)
+	}
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// equalCase determines if a and b are equal, ignoring the case unless caseSensitive is true
+func equalCase(a, b string, caseSensitive bool) bool {
+	if caseSensitive {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
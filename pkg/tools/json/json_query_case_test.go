@@ -0,0 +1,52 @@
+package json
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestCaseSensitiveSearch(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Email: "John.Smith@example.com", Title: "Software Engineer"},
+		{FirstName: "John", LastName: "smith", Email: "john.smith@example.com", Title: "software engineer"},
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"name", "find John smith", []string{"John Smith", "John smith"}},
+		{"case-sensitive name", "find John smith case sensitive", []string{"John smith"}},
+		{"case-sensitive capitalized name", "find John Smith case sensitive", []string{"John Smith"}},
+		{"email", "find john.smith@example.com", []string{"John Smith", "John smith"}},
+		{"case-sensitive email", "find John.Smith@example.com case-sensitive", []string{"John Smith"}},
+		{"title", "who are the engineers", []string{"John Smith", "John smith"}},
+		{"case-sensitive title", "who are the Engineers, case sensitive", []string{"John Smith"}},
+	}
+
+	for _, tt := range tests {
+		output, err := NewJSONQuery().ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("%s: error processing query: %v", tt.name, err)
+		}
+		for _, name := range []string{"John Smith", "John smith"} {
+			expected := slices.Contains(tt.expected, name)
+			if strings.Contains(output, name) != expected {
+				t.Errorf("%s: expected %s in the results: %t, got:\n%s", tt.name, name, expected, output)
+			}
+		}
+	}
+
+	// No approximate matches in case-sensitive mode
+	output, err := NewJSONQuery().ProcessQuery(data, "find JOHN SMITH case sensitive")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "Employee not found in the dataset." {
+		t.Errorf("Expected no employee found, got:\n%s", output)
+	}
+}
@@ -0,0 +1,39 @@
+package json
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// optionalColumn is a column of the markdown tables only shown on demand, e.g. "as a table with phone"
+type optionalColumn struct {
+	header  string
+	pattern *regexp.Regexp // Matches the name of the column after "with" in the lowercased query
+	value   func(emp model.EmployeeInfo) string
+}
+
+// optionalColumns are the optional columns of the markdown tables, in display order after the email
+var optionalColumns = []optionalColumn{
+	{"Phone", regexp.MustCompile(`\bphones?\b`), func(emp model.EmployeeInfo) string { return emp.Phone }},
+	{"Timezone", regexp.MustCompile(`\b(?:time ?zones?|tz)\b`), func(emp model.EmployeeInfo) string { return emp.Timezone }},
+	{"Status Text", regexp.MustCompile(`\b(?:status(?:es)? texts?|slack status(?:es)?|custom status(?:es)?)\b`),
+		func(emp model.EmployeeInfo) string { return emp.StatusText }},
+}
+
+// parseOptionalColumns returns the optional columns asked for in the lowercased query, e.g. "with phone and timezone"
+func parseOptionalColumns(query string) []optionalColumn {
+	_, after, found := strings.Cut(query, "with ")
+	if !found {
+		return nil
+	}
+
+	var columns []optionalColumn
+	for _, column := range optionalColumns {
+		if column.pattern.MatchString(after) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
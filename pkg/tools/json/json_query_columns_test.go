@@ -0,0 +1,63 @@
+package json
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestDirectoryFields(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer",
+			Phone: "+1 555 0100", Timezone: "America/New_York", StatusText: "In a meeting"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Designer", Timezone: "Europe/Paris"},
+	}
+	data := mustMarshal(t, employees)
+
+	// The fields survive the round-trip through the query
+	output, err := NewJSONQuery().ProcessQuery(data, "List all employees as json")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	var result []model.EmployeeInfo
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Error unmarshalling output: %v\n%s", err, output)
+	}
+	if len(result) != 2 || result[0] != employees[0] || result[1] != employees[1] {
+		t.Errorf("Expected the employees unchanged, got %+v", result)
+	}
+
+	// Appended to the list when present
+	output, err = NewJSONQuery().ProcessQuery(data, "List all employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "1. John Doe - Engineer - Phone: +1 555 0100 - Timezone: America/New_York - Status: In a meeting\n") ||
+		!strings.Contains(output, "2. Jane Roe - Designer - Timezone: Europe/Paris\n") {
+		t.Errorf("Expected the phone, timezone and status text, got:\n%s", output)
+	}
+
+	// Table columns on demand only
+	tests := []struct {
+		query   string
+		headers string
+		row     string
+	}{
+		{"List all employees as a table", "| Name | Title | Email | Status |", "| john.doe@example.com | Active |"},
+		{"List all employees as a table with phone", "| Email | Phone | Status |", "| john.doe@example.com | +1 555 0100 | Active |"},
+		{"List all employees as a table with their timezone and slack status", "| Email | Timezone | Status Text | Status |",
+			"| john.doe@example.com | America/New_York | In a meeting | Active |"},
+	}
+
+	for _, tt := range tests {
+		output, err := NewJSONQuery().ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if !strings.Contains(output, tt.headers) || !strings.Contains(output, tt.row) {
+			t.Errorf("Query %q: expected headers %q and row %q, got:\n%s", tt.query, tt.headers, tt.row, output)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package json
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// filterPredicatePattern matches a predicate of a compound filter in the lowercased query: a status, a text contained
+// in the title or the name, or an email domain, e.g. `title contains engineer` or `email domain @acme.com`
+// Texts with spaces are quoted, e.g. `title contains "product manager"`
+var filterPredicatePattern = regexp.MustCompile(`\b(?:(deactivated|active)\b|(title|name)\s+contains\s+("[^"]*"|'[^']*'|[^\s"']+)|email\s+domain\s+@?([a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,})\b)`)
+
+// filterExpressionPattern matches predicates joined by "and" and "or"
+var filterExpressionPattern = regexp.MustCompile(filterPredicatePattern.String() + `(?:\s+(?:and|or)\s+` + filterPredicatePattern.String() + `)*`)
+
+// filterPredicate is a condition on a field of the employees
+type filterPredicate struct {
+	field string // "status", "title", "name" or "email domain"
+	value string
+}
+
+// match determines if the employee satisfies the predicate, texts being matched case-insensitively unless
+// caseSensitive is true
+func (p filterPredicate) match(emp model.EmployeeInfo, caseSensitive bool) bool {
+	switch p.field {
+	case "status":
+		return emp.Deactivated == (p.value == "deactivated")
+	case "title":
+		return containsCase(emp.Title, p.value, caseSensitive)
+	case "name":
+		return containsCase(strings.TrimSpace(emp.FirstName+" "+emp.LastName), p.value, caseSensitive)
+	default:
+		return hasEmailDomain(emp, p.value)
+	}
+}
+
+// filterExpression is a compound filter, predicates joined by "and" and "or", "and" taking precedence over "or"
+// e.g. "active and title contains engineer or email domain @acme.com" keeps the active engineers and everyone on acme.com
+type filterExpression struct {
+	terms         [][]filterPredicate // Alternatives ("or") of predicates all required ("and")
+	source        string              // The expression as written in the query
+	caseSensitive bool
+}
+
+// String describes the filter, i.e. the expression as written in the query
+func (e filterExpression) String() string {
+	return e.source
+}
+
+// match determines if the employee satisfies all the predicates of one of the alternatives of the expression
+func (e filterExpression) match(emp model.EmployeeInfo) bool {
+	for _, term := range e.terms {
+		matched := true
+		for _, predicate := range term {
+			if !predicate.match(emp, e.caseSensitive) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilterExpression extracts the compound filter from the lowercased query, the texts being taken back from the
+// original query (cased) in case-sensitive mode. Only predicates joined by "and" or "or", or "contains" predicates,
+// make a compound filter, so that simple phrases such as "active engineers" keep their meaning
+// It returns the query without the expression, so that its keywords do not trigger the other filters
+func parseFilterExpression(query, cased string, caseSensitive bool) (filterExpression, string, bool) {
+	for _, span := range filterExpressionPattern.FindAllStringIndex(query, -1) {
+		source := query[span[0]:span[1]]
+		expression := filterExpression{source: source, caseSensitive: caseSensitive}
+		compound := false
+
+		var term []filterPredicate
+		end := 0
+		for _, m := range filterPredicatePattern.FindAllStringSubmatchIndex(source, -1) {
+			switch strings.TrimSpace(source[end:m[0]]) {
+			case "or":
+				expression.terms = append(expression.terms, term)
+				term = nil
+				compound = true
+			case "and":
+				compound = true
+			}
+			end = m[1]
+
+			switch {
+			case m[2] >= 0:
+				term = append(term, filterPredicate{field: "status", value: source[m[2]:m[3]]})
+			case m[4] >= 0:
+				value := strings.Trim(source[m[6]:m[7]], `"'`)
+				if caseSensitive {
+					value = originalCase(cased, value)
+				}
+				term = append(term, filterPredicate{field: source[m[4]:m[5]], value: value})
+				compound = true
+			default:
+				term = append(term, filterPredicate{field: "email domain", value: source[m[8]:m[9]]})
+			}
+		}
+		expression.terms = append(expression.terms, term)
+
+		if compound {
+			rest := query[:span[0]] + " " + query[span[1]:]
+			return expression, strings.Join(strings.Fields(rest), " "), true
+		}
+	}
+	return filterExpression{}, query, false
+}
@@ -0,0 +1,53 @@
+package json
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestFilterExpression(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer", Email: "john.doe@acme.com"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Marketing Manager", Email: "jane.roe@acme.com"},
+		{FirstName: "Max", LastName: "Poe", Title: "Staff Engineer", Email: "max.poe@contractor.com"},
+		{FirstName: "Ann", LastName: "Lee", Title: "Data Engineer", Email: "ann.lee@acme.com", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Bob", LastName: "Kay", Title: "Product Manager", Email: "bob.kay@contractor.com"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"and chain", "active and title contains engineer and email domain @acme.com", []string{"John Doe"}},
+		{"or chain", "title contains marketing or email domain contractor.com", []string{"Jane Roe", "Max Poe", "Bob Kay"}},
+		{"and before or", "deactivated and title contains engineer or name contains jane", []string{"Jane Roe", "Ann Lee"}},
+		{"quoted text", `list employees where title contains "product manager" or title contains "data engineer"`, []string{"Ann Lee", "Bob Kay"}},
+		{"with a status outside of the expression", "list active employees whose title contains engineer", []string{"John Doe", "Max Poe"}},
+		{"case sensitive", "title contains Engineer and name contains j case sensitive", nil},
+		{"simple phrase", "active employees with role engineer", []string{"John Doe", "Max Poe"}},
+	}
+
+	for _, tt := range tests {
+		results, err := NewJSONQuery().QueryStructured(employees, tt.query+" sorted by first name")
+		if err != nil {
+			t.Fatalf("%s: error processing query: %v", tt.name, err)
+		}
+		var names []string
+		for _, emp := range results {
+			names = append(names, emp.FirstName+" "+emp.LastName)
+		}
+		slices.Sort(names)
+		expected := slices.Sorted(slices.Values(tt.expected))
+		if !slices.Equal(names, expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, expected, names)
+		}
+	}
+
+	// Dates joined by "and" are not a compound filter
+	if _, _, ok := parseFilterExpression("deactivated between 2023-01-01 and 2023-06-30", "", false); ok {
+		t.Error("Expected no compound filter for a date range")
+	}
+}
@@ -0,0 +1,156 @@
+package json
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// deactivationDateLayout is the ISO layout of the dates in queries, and of the deactivation dates by default
+const deactivationDateLayout = model.DefaultDateFormat
+
+// WithDateFormat sets the Go layout of the deactivation dates of the data files (e.g. "02/01/2006" or time.RFC3339)
+// Deactivation dates are parsed with this layout first, then with the ISO layout. Dates in queries are always ISO
+func WithDateFormat(layout string) Option {
+	return func(q *JSONQuery) {
+		q.dateFormat = layout
+	}
+}
+
+// ValidateDateFormat returns an error if layout is not a Go layout holding the day, month and year of a date
+func ValidateDateFormat(layout string) error {
+	reference := time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC)
+	date, err := time.Parse(layout, reference.Format(layout))
+	if err != nil || date.Year() != reference.Year() || date.Month() != reference.Month() || date.Day() != reference.Day() {
+		return fmt.Errorf("invalid date format %q, it must be a Go layout with the day, month and year (e.g. %q, \"02/01/2006\" or %q)",
+			layout, model.DefaultDateFormat, time.RFC3339)
+	}
+	return nil
+}
+
+// parseDeactivationDate parses a deactivation date with the configured layout, falling back to the ISO layout
+func (q *JSONQuery) parseDeactivationDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if q.dateFormat != "" && q.dateFormat != deactivationDateLayout {
+		if date, err := time.Parse(q.dateFormat, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Parse(deactivationDateLayout, value)
+}
+
+// deactivationDay returns the day the employee was deactivated, false if the date is empty or unparseable
+func (q *JSONQuery) deactivationDay(emp model.EmployeeInfo) (time.Time, bool) {
+	date, err := q.parseDeactivationDate(emp.DeactivatedDate)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), true
+}
+
+var (
+	// betweenDatesPattern matches an inclusive date range, e.g. "between 2023-01-01 and 2023-06-30"
+	betweenDatesPattern = regexp.MustCompile(`\bbetween (\d[\d/.-]*\d) and (\d[\d/.-]*\d)\b`)
+	// afterDatePattern matches a lower date bound, e.g. "after 2023-01-01"
+	afterDatePattern = regexp.MustCompile(`\bafter (\d[\d/.-]*\d)\b`)
+	// beforeDatePattern matches an upper date bound, e.g. "before 2023-06-30"
+	beforeDatePattern = regexp.MustCompile(`\bbefore (\d[\d/.-]*\d)\b`)
+)
+
+// dateRange bounds deactivation dates, bounds included, a zero bound meaning unbounded
+type dateRange struct {
+	From        time.Time
+	To          time.Time
+	Description string // As worded in the query, e.g. "between 2023-01-01 and 2023-06-30"
+}
+
+// String describes the date range as worded in the query
+func (r dateRange) String() string {
+	return r.Description
+}
+
+// contains determines if the date is within the range, bounds included
+func (r dateRange) contains(date time.Time) bool {
+	return (r.From.IsZero() || !date.Before(r.From)) && (r.To.IsZero() || !date.After(r.To))
+}
+
+// parseDeactivationDateRange extracts the deactivation date range from the lowercased query
+// "between" includes both dates while "after" and "before" exclude the given date.
+// An error is returned when a date of the query is not a valid YYYY-MM-DD date
+func parseDeactivationDateRange(query string) (dateRange, bool, error) {
+	var r dateRange
+
+	if matches := betweenDatesPattern.FindStringSubmatch(query); matches != nil {
+		from, err := parseQueryDate(matches[1])
+		if err != nil {
+			return r, false, err
+		}
+		to, err := parseQueryDate(matches[2])
+		if err != nil {
+			return r, false, err
+		}
+		if to.Before(from) {
+			from, to = to, from
+		}
+		return dateRange{From: from, To: to, Description: fmt.Sprintf("between %s and %s", matches[1], matches[2])}, true, nil
+	}
+
+	var bounds []string
+	if matches := afterDatePattern.FindStringSubmatch(query); matches != nil {
+		after, err := parseQueryDate(matches[1])
+		if err != nil {
+			return r, false, err
+		}
+		r.From = after.AddDate(0, 0, 1)
+		bounds = append(bounds, "after "+matches[1])
+	}
+
+	if matches := beforeDatePattern.FindStringSubmatch(query); matches != nil {
+		before, err := parseQueryDate(matches[1])
+		if err != nil {
+			return r, false, err
+		}
+		r.To = before.AddDate(0, 0, -1)
+		bounds = append(bounds, "before "+matches[1])
+	}
+
+	r.Description = strings.Join(bounds, " and ")
+	return r, len(bounds) > 0, nil
+}
+
+// parseQueryDate parses a date of the query, which must use the YYYY-MM-DD layout
+func parseQueryDate(value string) (time.Time, error) {
+	date, err := time.Parse(deactivationDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, dates must use the YYYY-MM-DD format (e.g. 2023-06-30)", value)
+	}
+	return date, nil
+}
+
+// filterByDeactivationDateRange keeps the employees deactivated within the date range
+// Employees with an empty or unparseable deactivation date never match
+func (q *JSONQuery) filterByDeactivationDateRange(employees []model.EmployeeInfo, r dateRange) []model.EmployeeInfo {
+	return filterBy(employees, func(emp model.EmployeeInfo) bool {
+		day, ok := q.deactivationDay(emp)
+		return ok && r.contains(day)
+	})
+}
+
+// isFutureDeactivationQuery determines if the lowercased query asks for the deactivation dates in the future,
+// e.g. "future deactivations" or "employees deactivated in the future"
+func isFutureDeactivationQuery(query string) bool {
+	return containsAny(query, "future", "scheduled deactivation", "upcoming deactivation")
+}
+
+// filterFutureDeactivations keeps the employees whose deactivation date is after the day of now
+// Such dates are either scheduled offboardings or bad data (e.g. a wrong estimate)
+func (q *JSONQuery) filterFutureDeactivations(employees []model.EmployeeInfo, now time.Time) []model.EmployeeInfo {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return filterBy(employees, func(emp model.EmployeeInfo) bool {
+		day, ok := q.deactivationDay(emp)
+		return ok && day.After(today)
+	})
+}
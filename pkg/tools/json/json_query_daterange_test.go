@@ -0,0 +1,169 @@
+package json
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestDeactivationDateRange(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2023-01-01"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2023-06-30"},
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2023-07-01"},
+		{FirstName: "Bob", LastName: "Ray", Deactivated: true},
+		{FirstName: "Eve", LastName: "Kim", Deactivated: true, DeactivatedDate: "unknown"},
+		{FirstName: "Tom", LastName: "Fox"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	all := []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Bob Ray", "Eve Kim", "Tom Fox"}
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		// Both bounds are included
+		{"Who was deactivated between 2023-01-01 and 2023-06-30?", []string{"John Doe", "Jane Roe", "Max Poe"}},
+		{"Who was deactivated between 2023-06-30 and 2023-01-01?", []string{"John Doe", "Jane Roe", "Max Poe"}},
+		{"Show employees deactivated after 2023-03-15", []string{"Max Poe", "Ann Lee"}},
+		{"Show employees deactivated before 2023-03-15", []string{"John Doe"}},
+		{"Find employees deactivated after 2023-01-01 and before 2023-07-01", []string{"Jane Roe", "Max Poe"}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		for _, name := range all {
+			expected := false
+			for _, e := range tt.expected {
+				expected = expected || e == name
+			}
+			if strings.Contains(output, name) != expected {
+				t.Errorf("Query %q: expected %s in output=%t, got:\n%s", tt.query, name, expected, output)
+			}
+		}
+	}
+}
+
+func TestDeactivationDateRangeMalformed(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+	})
+	q := NewJSONQuery()
+
+	for _, query := range []string{
+		"Who was deactivated between 2023-01-01 and 2023-02-30?",
+		"Show employees deactivated after 2023/01/01",
+		"Show employees deactivated before 15-03-2023",
+	} {
+		output, err := q.ProcessQuery(data, query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", query, err)
+		}
+		if !strings.Contains(output, "invalid date") || !strings.Contains(output, "YYYY-MM-DD") || strings.Contains(output, "John Doe") {
+			t.Errorf("Query %q: expected an invalid date message, got:\n%s", query, output)
+		}
+	}
+}
+
+func TestFutureDeactivations(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2024-04-30"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "2024-05-01"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2024-05-02"},
+		{FirstName: "Ann", LastName: "Lee", DeactivatedDate: "2024-06-30"},
+		{FirstName: "Bob", LastName: "Ray", Deactivated: true, DeactivatedDate: "not-a-date"},
+		{FirstName: "Eve", LastName: "Kim"},
+	}
+	data := mustMarshal(t, employees)
+
+	clock := func() time.Time { return time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC) }
+	q := NewJSONQuery(WithClock(clock))
+
+	output, err := q.ProcessQuery(data, "Show future deactivations")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	// Today is not in the future, employees still active may have a scheduled deactivation
+	for _, expected := range []string{
+		"Deactivation dates after 2024-05-01, either scheduled offboardings or bad data:",
+		"Found 2 employees",
+		"Max Poe (Deactivated on 2024-05-02)",
+		"Ann Lee (Deactivation scheduled on 2024-06-30)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+	for _, name := range []string{"John Doe", "Jane Roe", "Bob Ray", "Eve Kim"} {
+		if strings.Contains(output, name) {
+			t.Errorf("Unexpected %s in output:\n%s", name, output)
+		}
+	}
+
+	output, err = q.ProcessQuery(data, "How many employees are deactivated in the future?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "2 employees with a deactivation date in the future.") {
+		t.Errorf("Expected the count of future deactivations, got:\n%s", output)
+	}
+}
+
+func TestCustomDateFormat(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "15/03/2023"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "02/01/2024"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2022-07-01"}, // ISO fallback
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "not-a-date"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery(WithDateFormat("02/01/2006"))
+
+	output, err := q.ProcessQuery(data, "Show the most recent deactivated employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	expected := []string{"Jane Roe", "John Doe", "Max Poe", "Ann Lee"}
+	if names := namesInOrder(t, output, expected); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected order %v, got %v", expected, names)
+	}
+
+	// Dates in queries are always ISO
+	output, err = q.ProcessQuery(data, "Who was deactivated between 2023-01-01 and 2023-12-31?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 1 employees") || !strings.Contains(output, "John Doe (Deactivated on 15/03/2023)") {
+		t.Errorf("Expected John Doe only, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Who was deactivated on 2024-01-02?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Jane Roe") || strings.Contains(output, "John Doe") {
+		t.Errorf("Expected Jane Roe only, got:\n%s", output)
+	}
+}
+
+func TestValidateDateFormat(t *testing.T) {
+	for _, layout := range []string{"2006-01-02", "02/01/2006", "01/02/2006", time.RFC3339, "Jan 2, 2006"} {
+		if err := ValidateDateFormat(layout); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", layout, err)
+		}
+	}
+	for _, layout := range []string{"", "YYYY-MM-DD", "01/2006", "15:04"} {
+		if err := ValidateDateFormat(layout); err == nil {
+			t.Errorf("Expected %q to be invalid", layout)
+		}
+	}
+}
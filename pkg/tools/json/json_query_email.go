@@ -0,0 +1,40 @@
+package json
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// emailDomainPattern matches an email domain in a query, either prefixed with "@" (e.g. "@contractor.com")
+// or following "domain" (e.g. "email domain contractor.com")
+var emailDomainPattern = regexp.MustCompile(`(?:\bdomain\s+@?|(?:^|[\s(])@)([a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,})\b`)
+
+// parseEmailDomain extracts the email domain from the lowercased query
+// Full email addresses are not domains, they are handled by the search on the email
+func parseEmailDomain(query string) (string, bool) {
+	if matches := emailDomainPattern.FindStringSubmatch(query); matches != nil {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// filterByEmailDomain keeps the employees whose email is on the domain or one of its subdomains (case-insensitive)
+// Employees without email never match
+func filterByEmailDomain(employees []model.EmployeeInfo, domain string) []model.EmployeeInfo {
+	return filterBy(employees, func(emp model.EmployeeInfo) bool {
+		return hasEmailDomain(emp, domain)
+	})
+}
+
+// hasEmailDomain determines if the email of the employee is on the lowercased domain or one of its subdomains
+func hasEmailDomain(emp model.EmployeeInfo, domain string) bool {
+	email := strings.ToLower(strings.TrimSpace(emp.Email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	host := email[at+1:]
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,80 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestParseEmailDomain(t *testing.T) {
+	tests := []struct {
+		query  string
+		domain string
+		ok     bool
+	}{
+		{"list employees with email domain @contractor.com", "contractor.com", true},
+		{"employees with email domain contractor.co.uk", "contractor.co.uk", true},
+		{"who is on @acme.com?", "acme.com", true},
+		{"find employee with email john.doe@acme.com", "", false},
+		{"list active employees", "", false},
+	}
+
+	for _, tt := range tests {
+		domain, ok := parseEmailDomain(tt.query)
+		if domain != tt.domain || ok != tt.ok {
+			t.Errorf("parseEmailDomain(%q): expected (%q, %t), got (%q, %t)", tt.query, tt.domain, tt.ok, domain, ok)
+		}
+	}
+}
+
+func TestEmailSearch(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "John.Doe@Acme.com", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@contractor.com", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Email: "max.poe@eu.contractor.com", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@notcontractor.com"},
+		{FirstName: "No", LastName: "Email"},
+	}
+	data := mustMarshal(t, employees)
+
+	// Full address, matched exactly whatever the case
+	output, err := NewJSONQuery().ProcessQuery(data, "find employee with email john.doe@acme.com")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Employee: John Doe") {
+		t.Errorf("Expected John Doe, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery().ProcessQuery(data, "show the employee with email jane.roe@contractor.com")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Employee: Jane Roe") {
+		t.Errorf("Expected Jane Roe, got:\n%s", output)
+	}
+
+	// Domain, including its subdomains but not the domains merely ending with it
+	q := NewJSONQuery()
+	output, err = q.ProcessQuery(data, "list employees with email domain @contractor.com")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Jane Roe") || !strings.Contains(output, "Max Poe") ||
+		strings.Contains(output, "Ann Lee") || strings.Contains(output, "John Doe") || strings.Contains(output, "No Email") {
+		t.Errorf("Expected Jane Roe and Max Poe only, got:\n%s", output)
+	}
+	if count, _ := q.LastResultCount(); count != 2 {
+		t.Errorf("Expected a result count of 2, got %d", count)
+	}
+
+	// Combined with the other filters
+	output, err = NewJSONQuery().ProcessQuery(data, "how many active employees with email domain contractor.com?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "1 active employee with an email on contractor.com." {
+		t.Errorf("Expected the count of active employees on the domain, got:\n%s", output)
+	}
+}
@@ -0,0 +1,38 @@
+package json
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// exactMatchPattern matches the queries asking for exact name matches, e.g. "exact" or "exact match"
+	exactMatchPattern = regexp.MustCompile(`\bexact(?:ly)?(?:\s+match(?:es|ing)?)?\b`)
+	// quotedNamePattern matches a name quoted in the query, e.g. "find \"Ann Lee\"", asking for exact matches too
+	// Single quotes only quote at word boundaries, so that the apostrophes of "Mary O'Brien's" are not quotes
+	quotedNamePattern = regexp.MustCompile(`"([^"]+)"|(^|\s)'([^']+)'($|[\s?!.,;:])`)
+)
+
+// parseExactMatch determines if the lowercased query asks for exact matches of the names, with the "exact"
+// keyword or a quoted name. It returns the query without the keyword nor the quotes
+func parseExactMatch(query string) (bool, string) {
+	exact := false
+	if exactMatchPattern.MatchString(query) {
+		exact = true
+		query = exactMatchPattern.ReplaceAllString(query, "")
+	}
+	if quotedNamePattern.MatchString(query) {
+		exact = true
+		query = quotedNamePattern.ReplaceAllString(query, "${1}${2}${3}${4}")
+	}
+	return exact, strings.Join(strings.Fields(query), " ")
+}
+
+// matchName determines if the name of an employee matches the searched one: equal in exact mode, containing it
+// otherwise. The case is ignored unless caseSensitive is true
+func matchName(name, searched string, exact, caseSensitive bool) bool {
+	if exact {
+		return equalCase(name, searched, caseSensitive)
+	}
+	return containsCase(name, searched, caseSensitive)
+}
@@ -0,0 +1,94 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestExactNameMatch(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@corp.com"},
+		{FirstName: "Anne", LastName: "Leete", Email: "anne.leete@corp.com"},
+		{FirstName: "Joann", LastName: "Leeson", Email: "joann.leeson@corp.com"},
+		{FirstName: "Bob", LastName: "Ray", Email: "bob.ray@corp.com"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery(WithLogOutput(nil))
+
+	// Names are contained by default, partial matches included
+	output, err := q.ProcessQuery(data, "Find Ann Lee")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	for _, name := range []string{"Ann Lee", "Anne Leete", "Joann Leeson"} {
+		if !strings.Contains(output, name) {
+			t.Errorf("Expected %s in the output of contains mode, got:\n%s", name, output)
+		}
+	}
+
+	for _, query := range []string{`Find "Ann Lee"`, "Find 'ann lee'", "Find exact Ann Lee", "find ann lee exact match"} {
+		output, err := q.ProcessQuery(data, query)
+		if err != nil {
+			t.Fatalf("Query %q: error processing query: %v", query, err)
+		}
+		if !strings.HasPrefix(output, "Employee: Ann Lee\n") {
+			t.Errorf("Query %q: expected Ann Lee alone, got:\n%s", query, output)
+		}
+		for _, name := range []string{"Anne Leete", "Joann Leeson"} {
+			if strings.Contains(output, name) {
+				t.Errorf("Query %q: unexpected partial match %s in exact mode:\n%s", query, name, output)
+			}
+		}
+	}
+
+	// "Joan" is part of "Joann" only, exact mode has no approximate matches either
+	output, err = q.ProcessQuery(data, "Find Joan Lees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Joann Leeson") {
+		t.Errorf("Expected Joann Leeson in the output of contains mode, got:\n%s", output)
+	}
+	output, err = q.ProcessQuery(data, `Find "Joan Lees"`)
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "Employee not found in the dataset." {
+		t.Errorf("Expected no match in exact mode, got:\n%s", output)
+	}
+}
+
+func TestParseExactMatchApostrophes(t *testing.T) {
+	tests := []struct {
+		query    string
+		exact    bool
+		expected string
+	}{
+		{"when was mary o'brien's account deactivated?", false, "when was mary o'brien's account deactivated?"},
+		{"find o'brien and d'souza", false, "find o'brien and d'souza"},
+		{"find 'ann lee'?", true, "find ann lee?"},
+		{"'ann lee' details", true, "ann lee details"},
+		{`find "mary o'brien"`, true, "find mary o'brien"},
+	}
+
+	for _, tt := range tests {
+		exact, query := parseExactMatch(tt.query)
+		if exact != tt.exact || query != tt.expected {
+			t.Errorf("%q: expected %v and %q, got %v and %q", tt.query, tt.exact, tt.expected, exact, query)
+		}
+	}
+
+	employees := []model.EmployeeInfo{
+		{FirstName: "Mary", LastName: "O'Brien", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Bob", LastName: "Ray"},
+	}
+	output, err := NewJSONQuery(WithLogOutput(nil)).ProcessQuery(mustMarshal(t, employees), "When was Mary O'Brien's account deactivated?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Mary O'Brien") || !strings.Contains(output, "2023-03-15") {
+		t.Errorf("Expected the deactivation date of Mary O'Brien, got:\n%s", output)
+	}
+}
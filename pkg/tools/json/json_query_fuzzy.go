@@ -0,0 +1,111 @@
+package json
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// DefaultFuzzyMaxDistance is the default maximum edit distance of the approximate name matches
+const DefaultFuzzyMaxDistance = 2
+
+// WithFuzzyMaxDistance sets the maximum edit distance between a name of a query and a first or last name for
+// them to match approximately, when no employee matches exactly (e.g. "Jon Smyth" for John Smith)
+// A distance of 0 disables approximate matching
+func WithFuzzyMaxDistance(distance int) Option {
+	return func(q *JSONQuery) {
+		q.fuzzyMaxDistance = distance
+	}
+}
+
+// fuzzyMatch is an employee matching a name of a query approximately
+type fuzzyMatch struct {
+	Employee model.EmployeeInfo
+	Distance int // Sum of the edit distances of the first and last names
+}
+
+// findFuzzyMatches finds the employees whose first and last names are both within the maximum edit distance of
+// adjacent words of the lowercased query, the closest first
+func (q *JSONQuery) findFuzzyMatches(employees []model.EmployeeInfo, query string) []fuzzyMatch {
+	if q.fuzzyMaxDistance <= 0 {
+		return nil
+	}
+
+	var words []string
+	for _, word := range strings.Fields(query) {
+		word = strings.Trim(word, "?!.,:;()\"'")
+		if len(word) >= 3 && !nameFillerWords[word] {
+			words = append(words, word)
+		}
+	}
+
+	var matches []fuzzyMatch
+	for _, emp := range employees {
+		firstName, lastName := strings.ToLower(emp.FirstName), strings.ToLower(emp.LastName)
+		best := -1
+		for i := 0; i+1 < len(words); i++ {
+			firstDistance := levenshtein(words[i], firstName)
+			lastDistance := levenshtein(words[i+1], lastName)
+			if firstDistance > q.fuzzyMaxDistance || lastDistance > q.fuzzyMaxDistance {
+				continue
+			}
+			if distance := firstDistance + lastDistance; best < 0 || distance < best {
+				best = distance
+			}
+		}
+		if best >= 0 {
+			matches = append(matches, fuzzyMatch{Employee: emp, Distance: best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+	return matches
+}
+
+// fuzzyEmployees returns the employees of the approximate matches, the closest first
+func fuzzyEmployees(matches []fuzzyMatch) []model.EmployeeInfo {
+	employees := make([]model.EmployeeInfo, len(matches))
+	for i, match := range matches {
+		employees[i] = match.Employee
+	}
+	return employees
+}
+
+// formatFuzzyMatches formats the approximate matches, noting that they are approximate
+func formatFuzzyMatches(matches []fuzzyMatch) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("No exact match found, %d approximate matches (closest first):\n", len(matches)))
+	for i, match := range matches {
+		result.WriteString(fmt.Sprintf("\n%d. %s", i+1, formatEmployee(match.Employee)))
+	}
+	return result.String()
+}
+
+// levenshtein returns the edit distance between a and b, i.e. the minimum number of single-character
+// insertions, deletions and substitutions turning a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
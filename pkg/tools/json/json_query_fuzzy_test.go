@@ -0,0 +1,91 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"smith", "smith", 0},
+		{"smyth", "smith", 1}, // substitution
+		{"jon", "john", 1},    // insertion
+		{"johnn", "john", 1},  // deletion
+		{"jhon", "john", 2},   // transposition
+		{"", "john", 4},
+		{"zoë", "zoe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q): expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestFuzzyNameMatching(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Email: "john.smith@example.com"},
+		{FirstName: "Joan", LastName: "Smith", Email: "joan.smith@example.com"},
+		{FirstName: "Jane", LastName: "Doe", Email: "jane.doe@example.com"},
+	}
+	jsonData := mustMarshal(t, employees)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string // Closest first
+	}{
+		{"one-character typos", "Find Jhn Smyth", []string{"John Smith", "Joan Smith"}},
+		{"transpositions", "Find Jnae Deo", []string{"Jane Doe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewJSONQuery()
+			output, err := q.ProcessQuery(jsonData, tt.query)
+			if err != nil {
+				t.Fatalf("Error processing query: %v", err)
+			}
+			if !strings.Contains(output, "approximate matches") {
+				t.Errorf("Expected the matches to be noted as approximate:\n%s", output)
+			}
+			namesInOrder(t, output, tt.expected)
+			if count, _ := q.LastResultCount(); count != len(tt.expected) {
+				t.Errorf("Expected a result count of %d, got %d", len(tt.expected), count)
+			}
+		})
+	}
+
+	// Exact matches are not mixed with approximate ones
+	output, err := NewJSONQuery().ProcessQuery(jsonData, "Find John Smith")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if strings.Contains(output, "approximate") || strings.Contains(output, "Joan") {
+		t.Errorf("Expected only the exact match:\n%s", output)
+	}
+
+	// Names too far from any employee are not found
+	output, err = NewJSONQuery().ProcessQuery(jsonData, "Find Bob Marley")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "Employee not found in the dataset." {
+		t.Errorf("Expected no match, got:\n%s", output)
+	}
+
+	// A maximum distance of 0 disables approximate matching
+	output, err = NewJSONQuery(WithFuzzyMaxDistance(0)).ProcessQuery(jsonData, "Find Jon Smyth")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "Employee not found in the dataset." {
+		t.Errorf("Expected approximate matching to be disabled, got:\n%s", output)
+	}
+}
@@ -0,0 +1,135 @@
+package json
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+const (
+	// noDateGroup is the group of the employees without deactivation year
+	noDateGroup = "Active/No date"
+	// noTitleGroup is the group of the employees without title
+	noTitleGroup = "(no title)"
+)
+
+var (
+	// groupByYearPattern matches the queries asking for results grouped by deactivation year, e.g. "by year"
+	groupByYearPattern = regexp.MustCompile(`\b(?:by|per) (?:deactivation )?year\b`)
+	// groupByTitlePattern matches the queries asking for the number of employees of each title,
+	// e.g. "group by title", "breakdown by title" or "how many employees per title", but not "sort by title"
+	groupByTitlePattern = regexp.MustCompile(`\b(?:group(?:ed)?|breakdown|broken down|count(?:ed)?) by (?:job )?titles?\b|\bper (?:job )?title\b`)
+)
+
+// isGroupByYearQuery determines if the lowercased query asks for the results grouped by deactivation year
+func isGroupByYearQuery(query string) bool {
+	return groupByYearPattern.MatchString(query)
+}
+
+// isGroupByTitleQuery determines if the lowercased query asks for the number of employees of each title
+func isGroupByTitleQuery(query string) bool {
+	return groupByTitlePattern.MatchString(query)
+}
+
+// titleCount is the number of employees sharing a title
+type titleCount struct {
+	Title string
+	Count int
+}
+
+// countByTitle counts the employees of each title, the most common first then alphabetically
+// Titles are trimmed, and the employees without title are counted under "(no title)"
+func countByTitle(employees []model.EmployeeInfo) []titleCount {
+	counts := make(map[string]int)
+	for _, emp := range employees {
+		title := strings.TrimSpace(emp.Title)
+		if title == "" {
+			title = noTitleGroup
+		}
+		counts[title]++
+	}
+
+	titles := make([]titleCount, 0, len(counts))
+	for title, count := range counts {
+		titles = append(titles, titleCount{Title: title, Count: count})
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		if titles[i].Count != titles[j].Count {
+			return titles[i].Count > titles[j].Count
+		}
+		return titles[i].Title < titles[j].Title
+	})
+
+	return titles
+}
+
+// FormatGroupedByTitle formats the number of employees of each title as a markdown table, the most common first
+func (q *JSONQuery) FormatGroupedByTitle(employees []model.EmployeeInfo) (string, error) {
+	if len(employees) == 0 {
+		return NoResultsMessage, nil
+	}
+
+	titles := countByTitle(employees)
+	headers := []string{"Title", "Count"}
+	rows := make([][]string, 0, len(titles))
+	for _, title := range titles {
+		rows = append(rows, []string{title.Title, strconv.Itoa(title.Count)})
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d employees with %d titles:\n\n", len(employees), len(titles)))
+	writeMarkdownTable(&result, headers, rows, []int{0, 1})
+
+	return result.String(), nil
+}
+
+// deactivationYear returns the year of the deactivation date of the employee, if any
+func (q *JSONQuery) deactivationYear(emp model.EmployeeInfo) (string, bool) {
+	day, ok := q.deactivationDay(emp)
+	if !ok {
+		return "", false
+	}
+	return strconv.Itoa(day.Year()), true
+}
+
+// FormatGroupedByYear formats the employee data as lists under deactivation year headers, most recent year first,
+// with the number of employees of each year. Employees without deactivation date (e.g. active ones) come last,
+// under the "Active/No date" header. Employees keep their order within each year
+func (q *JSONQuery) FormatGroupedByYear(employees []model.EmployeeInfo) (string, error) {
+	if len(employees) == 0 {
+		return NoResultsMessage, nil
+	}
+
+	groups := make(map[string][]model.EmployeeInfo)
+	var years []string
+	for _, emp := range employees {
+		year, ok := q.deactivationYear(emp)
+		if !ok {
+			year = noDateGroup
+		}
+		if _, seen := groups[year]; !seen && ok {
+			years = append(years, year)
+		}
+		groups[year] = append(groups[year], emp)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(years)))
+	if _, ok := groups[noDateGroup]; ok {
+		years = append(years, noDateGroup)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d employees in %d groups:\n", len(employees), len(years)))
+
+	for _, year := range years {
+		result.WriteString(fmt.Sprintf("\n## %s (%d employees)\n\n", year, len(groups[year])))
+		for i, emp := range groups[year] {
+			result.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatEmployeeLine(emp)))
+		}
+	}
+
+	return result.String(), nil
+}
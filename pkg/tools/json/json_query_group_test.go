@@ -0,0 +1,90 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestFormatGroupedByYear(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2021-03-15"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2023-01-10"},
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2021-11-02"},
+		{FirstName: "Bob", LastName: "Ray", Deactivated: true},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	output, err := q.ProcessQuery(data, "List all employees by year")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	expected := "Found 5 employees in 3 groups:\n" +
+		"\n## 2023 (1 employees)\n\n1. Max Poe (Deactivated on 2023-01-10)\n" +
+		"\n## 2021 (2 employees)\n\n1. John Doe (Deactivated on 2021-03-15)\n2. Ann Lee (Deactivated on 2021-11-02)\n" +
+		"\n## Active/No date (2 employees)\n\n1. Jane Roe - Designer\n2. Bob Ray (Deactivated)\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+
+	// Grouping applies after filtering
+	output, err = q.ProcessQuery(data, "Show deactivated employees per year")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "## 2021 (2 employees)") || !strings.Contains(output, "## Active/No date (1 employees)") ||
+		strings.Contains(output, "Jane Roe") {
+		t.Errorf("Expected deactivated employees grouped by year, got:\n%s", output)
+	}
+}
+
+func TestFormatGroupedByTitle(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Title: " Software Engineer ", Deactivated: true, DeactivatedDate: "2023-01-10"},
+		{FirstName: "Ann", LastName: "Lee"},
+		{FirstName: "Bob", LastName: "Ray", Title: "  ", Deactivated: true},
+		{FirstName: "Eve", LastName: "Kay", Title: "Software Engineer"},
+	}
+	data := mustMarshal(t, employees)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"Group by title", "Found 6 employees with 3 titles:\n\n" +
+			"| Title | Count |\n|-------|-------|\n" +
+			"| Software Engineer | 3 |\n| (no title) | 2 |\n| Designer | 1 |\n"},
+		{"How many active employees per title?", "Found 4 employees with 3 titles:\n\n" +
+			"| Title | Count |\n|-------|-------|\n" +
+			"| Software Engineer | 2 |\n| (no title) | 1 |\n| Designer | 1 |\n"},
+		{"Breakdown by title of deactivated employees", "Found 2 employees with 2 titles:\n\n" +
+			"| Title | Count |\n|-------|-------|\n" +
+			"| (no title) | 1 |\n| Software Engineer | 1 |\n"},
+	}
+
+	for _, tt := range tests {
+		q := NewJSONQuery()
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if output != tt.expected {
+			t.Errorf("Query %q: expected:\n%s\ngot:\n%s", tt.query, tt.expected, output)
+		}
+	}
+
+	// Sorting by title is not grouping
+	output, err := NewJSONQuery().ProcessQuery(data, "List all employees sorted by title")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if strings.Contains(output, "| Count |") {
+		t.Errorf("Expected the list of employees, got:\n%s", output)
+	}
+}
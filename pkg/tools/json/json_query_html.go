@@ -0,0 +1,79 @@
+package json
+
+import (
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// FormatAsHTMLTable formats the employee data as an HTML table, to be embedded in web reports
+// All the values are HTML-escaped, so that titles such as "R&D <Lead>" do not break the markup
+// A column of profile pictures is added when at least one employee has an image URL
+func (q *JSONQuery) FormatAsHTMLTable(employees []model.EmployeeInfo) (string, error) {
+	if len(employees) == 0 {
+		return NoResultsMessage, nil
+	}
+
+	withImages := slices.ContainsFunc(employees, func(emp model.EmployeeInfo) bool { return emp.ImageURL != "" })
+
+	var result strings.Builder
+	result.WriteString("<table>\n")
+	result.WriteString("  <thead>\n")
+	header := escapeHTML("Name", "Title", "Email", "Status", "Deactivation Date")
+	if withImages {
+		header = append([]string{"Photo"}, header...)
+	}
+	writeHTMLRow(&result, "th", header)
+	result.WriteString("  </thead>\n")
+	result.WriteString("  <tbody>\n")
+
+	for _, emp := range employees {
+		status := "Active"
+		deactivationDate := ""
+
+		if emp.Deactivated {
+			status = "Deactivated"
+			deactivationDate = emp.DeactivatedDate
+		}
+
+		name := emp.FirstName + " " + emp.LastName
+		row := escapeHTML(name, emp.Title, emp.Email, status, deactivationDate)
+		if withImages {
+			row = append([]string{htmlImage(emp.ImageURL, name)}, row...)
+		}
+		writeHTMLRow(&result, "td", row)
+	}
+
+	result.WriteString("  </tbody>\n")
+	result.WriteString("</table>\n")
+
+	return result.String(), nil
+}
+
+// writeHTMLRow writes a table row made of cells of the given tag (th or td), the values being already escaped
+func writeHTMLRow(result *strings.Builder, tag string, values []string) {
+	result.WriteString("    <tr>")
+	for _, value := range values {
+		result.WriteString("<" + tag + ">" + value + "</" + tag + ">")
+	}
+	result.WriteString("</tr>\n")
+}
+
+// escapeHTML escapes the values for the cells of writeHTMLRow
+func escapeHTML(values ...string) []string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = html.EscapeString(value)
+	}
+	return escaped
+}
+
+// htmlImage returns the img element of a profile picture, empty without image URL or for URLs other than HTTP(S)
+func htmlImage(url, alt string) string {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return ""
+	}
+	return `<img src="` + html.EscapeString(url) + `" alt="` + html.EscapeString(alt) + `">`
+}
@@ -0,0 +1,67 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestFormatAsHTMLTable(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: `R&D <script>alert("x")</script>`},
+		{FirstName: "Jane", LastName: "O'Roe", Email: "jane.roe@example.com", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Max", LastName: "Poe", Title: "Engineer", DeactivatedDate: "2030-01-01"}, // scheduled deactivation
+	}
+
+	q := NewJSONQuery()
+	output, err := q.ProcessQuery(mustMarshal(t, employees), "List all employees as an html table")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	expected := "<table>\n" +
+		"  <thead>\n" +
+		"    <tr><th>Name</th><th>Title</th><th>Email</th><th>Status</th><th>Deactivation Date</th></tr>\n" +
+		"  </thead>\n" +
+		"  <tbody>\n" +
+		"    <tr><td>John Doe</td><td>R&amp;D &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</td><td>john.doe@example.com</td><td>Active</td><td></td></tr>\n" +
+		"    <tr><td>Jane O&#39;Roe</td><td>Designer</td><td>jane.roe@example.com</td><td>Deactivated</td><td>2023-03-15</td></tr>\n" +
+		"    <tr><td>Max Poe</td><td>Engineer</td><td></td><td>Active</td><td></td></tr>\n" +
+		"  </tbody>\n" +
+		"</table>\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the HTML table:\n%s\ngot:\n%s", expected, output)
+	}
+	if strings.Contains(output, "<script>") {
+		t.Errorf("Expected the title to be escaped, got:\n%s", output)
+	}
+	if count, _ := q.LastResultCount(); count != 3 {
+		t.Errorf("Expected a result count of 3, got %d", count)
+	}
+}
+
+func TestFormatAsHTMLTableImages(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Engineer", ImageURL: "https://avatars.slack-edge.com/john_192.png?a=1&b=2"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer", Deactivated: true}, // No profile picture
+		{FirstName: "Max", LastName: "Poe", Title: "Engineer", ImageURL: `javascript:alert("x")`},
+	}
+
+	q := NewJSONQuery()
+	output, err := q.FormatAsHTMLTable(employees)
+	if err != nil {
+		t.Fatalf("Error formatting the table: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<tr><th>Photo</th><th>Name</th>",
+		`<tr><td><img src="https://avatars.slack-edge.com/john_192.png?a=1&amp;b=2" alt="John Doe"></td><td>John Doe</td>`,
+		"<tr><td></td><td>Jane Roe</td>",
+		"<tr><td></td><td>Max Poe</td>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in the HTML table, got:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// inlineData returns the employees given inline in the data field of the tool input, false if the field is absent
+// or null. The array may also be given as a JSON string, as LLMs sometimes quote it
+func inlineData(data json.RawMessage) ([]byte, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, false
+	}
+
+	var quoted string
+	if err := json.Unmarshal(data, &quoted); err == nil {
+		return []byte(quoted), true
+	}
+	return data, true
+}
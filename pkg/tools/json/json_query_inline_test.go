@@ -0,0 +1,59 @@
+package json
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestInlineData(t *testing.T) {
+	tool := NewJSONQueryTool(WithLogOutput(nil))
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"array", `{"data": [{"first_name": "John", "last_name": "Doe"}, {"first_name": "Jane", "last_name": "Roe", "deactivated": true}], "query": "List active employees"}`},
+		{"quoted array", `{"data": "[{\"first_name\": \"John\", \"last_name\": \"Doe\"}, {\"first_name\": \"Jane\", \"last_name\": \"Roe\", \"deactivated\": true}]", "query": "List active employees"}`},
+	}
+
+	for _, tt := range tests {
+		output, err := tool.Call(context.Background(), tt.input)
+		if err != nil {
+			t.Fatalf("%s: error calling tool: %v", tt.name, err)
+		}
+		if !strings.Contains(output, "John Doe") || strings.Contains(output, "Jane Roe") {
+			t.Errorf("%s: expected only John Doe in the output, got:\n%s", tt.name, output)
+		}
+	}
+
+	// The inline data is validated like the files
+	if _, err := tool.Call(context.Background(), `{"data": {"first_name": "John"}, "query": "List all employees"}`); err == nil {
+		t.Error("Expected an error for inline data which is not an array")
+	}
+
+	// One of the sources must be provided
+	if _, err := tool.Call(context.Background(), `{"data": null, "query": "List all employees"}`); err == nil || !strings.Contains(err.Error(), "no file path nor data provided") {
+		t.Errorf("Expected an error without file path nor data, got %v", err)
+	}
+}
+
+func TestInlineDataPrecedence(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "employees-all.json")
+	if err := os.WriteFile(filePath, mustMarshal(t, []model.EmployeeInfo{{FirstName: "Max", LastName: "Poe"}}), 0644); err != nil {
+		t.Fatalf("Error writing fixture file: %v", err)
+	}
+
+	input := `{"file_path": "` + filePath + `", "data": [{"first_name": "John", "last_name": "Doe"}], "query": "List all employees"}`
+	output, err := NewJSONQueryTool(WithLogOutput(nil)).Call(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Error calling tool: %v", err)
+	}
+	if !strings.Contains(output, "John Doe") || strings.Contains(output, "Max Poe") {
+		t.Errorf("Expected the inline data to take precedence over the file, got:\n%s", output)
+	}
+}
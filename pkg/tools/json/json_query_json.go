@@ -0,0 +1,185 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// EmployeeFields lists the JSON field names of an employee, in output order
+var EmployeeFields = employeeFieldNames()
+
+// employeeFieldNames reads the JSON field names from the tags of model.EmployeeInfo
+func employeeFieldNames() []string {
+	var names []string
+	employeeType := reflect.TypeOf(model.EmployeeInfo{})
+	for i := 0; i < employeeType.NumField(); i++ {
+		name, _, _ := strings.Cut(employeeType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateJSONFields returns an error if a field is not a JSON field name of an employee
+func ValidateJSONFields(fields []string) error {
+	for _, field := range normalizeFields(fields) {
+		if !containsField(EmployeeFields, field) {
+			return fmt.Errorf("unknown employee field %q (valid fields: %s)", field, strings.Join(EmployeeFields, ", "))
+		}
+	}
+	return nil
+}
+
+// WithJSONFields restricts the fields written by the JSON and NDJSON outputs (e.g. for privacy-scoped exports)
+// Only the fields in include are written, all fields if include is empty, minus the fields in exclude
+// Field names are the JSON names of the employee fields (e.g. "first_name", "email"), unknown names are ignored
+func WithJSONFields(include, exclude []string) Option {
+	return func(q *JSONQuery) {
+		selected := EmployeeFields
+		if len(include) > 0 {
+			selected = normalizeFields(include)
+		}
+
+		excluded := normalizeFields(exclude)
+		q.jsonFields = []string{}
+		for _, field := range EmployeeFields {
+			if containsField(selected, field) && !containsField(excluded, field) {
+				q.jsonFields = append(q.jsonFields, field)
+			}
+		}
+	}
+}
+
+// WithCompactJSON writes the JSON outputs compacted on a single line instead of indented, for logging and piping
+// The NDJSON output is always compact
+func WithCompactJSON(compact bool) Option {
+	return func(q *JSONQuery) {
+		q.compactJSON = compact
+	}
+}
+
+// normalizeFields lowercases and trims field names
+func normalizeFields(fields []string) []string {
+	normalized := make([]string, len(fields))
+	for i, field := range fields {
+		normalized[i] = strings.ToLower(strings.TrimSpace(field))
+	}
+	return normalized
+}
+
+// containsField determines if fields contains field
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatAsJSON formats the employee data as a JSON array, restricted to the selected fields
+// The array is indented unless compact JSON is enabled
+func (q *JSONQuery) FormatAsJSON(employees []model.EmployeeInfo) (string, error) {
+	var result bytes.Buffer
+	result.WriteString("[")
+
+	if q.compactJSON {
+		for i, emp := range employees {
+			object, err := q.marshalEmployee(emp)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), err
+			}
+			if i > 0 {
+				result.WriteString(",")
+			}
+			result.Write(object)
+		}
+		result.WriteString("]\n")
+		return result.String(), nil
+	}
+
+	for i, emp := range employees {
+		object, err := q.marshalEmployee(emp)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), err
+		}
+
+		if i > 0 {
+			result.WriteString(",")
+		}
+		result.WriteString("\n  ")
+		if err := json.Indent(&result, object, "  ", "  "); err != nil {
+			return fmt.Sprintf("Error: %v", err), err
+		}
+	}
+
+	if len(employees) > 0 {
+		result.WriteString("\n")
+	}
+	result.WriteString("]\n")
+
+	return result.String(), nil
+}
+
+// FormatAsNDJSON formats the employee data as newline delimited JSON, one employee per line,
+// restricted to the selected fields
+func (q *JSONQuery) FormatAsNDJSON(employees []model.EmployeeInfo) (string, error) {
+	var result bytes.Buffer
+
+	for _, emp := range employees {
+		object, err := q.marshalEmployee(emp)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), err
+		}
+		result.Write(object)
+		result.WriteString("\n")
+	}
+
+	return result.String(), nil
+}
+
+// marshalEmployee marshals an employee as a JSON object holding only the selected fields
+// Fields omitted from the data files when empty are omitted too, the fields keep the order of model.EmployeeInfo
+func (q *JSONQuery) marshalEmployee(emp model.EmployeeInfo) ([]byte, error) {
+	data, err := json.Marshal(emp)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling employee: %v", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("error marshalling employee: %v", err)
+	}
+
+	fields := q.jsonFields
+	if fields == nil {
+		fields = EmployeeFields
+	}
+
+	var object bytes.Buffer
+	object.WriteString("{")
+	written := 0
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			continue
+		}
+		if written > 0 {
+			object.WriteString(",")
+		}
+		key, _ := json.Marshal(field)
+		object.Write(key)
+		object.WriteString(":")
+		object.Write(value)
+		written++
+	}
+	object.WriteString("}")
+
+	return object.Bytes(), nil
+}
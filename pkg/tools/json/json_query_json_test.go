@@ -0,0 +1,149 @@
+package json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestJSONFieldSelection(t *testing.T) {
+	has2FA := true
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15", Has2FA: &has2FA},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Designer"},
+	}
+	data := mustMarshal(t, employees)
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		query    string
+		expected []string
+	}{
+		{"all fields by default", nil, "Show employees as json", []string{
+			`{"first_name":"John","last_name":"Doe","email":"john.doe@example.com","title":"Engineer","deactivated":true,"deactivated_date":"2023-03-15","has_2fa":true}`,
+			`{"first_name":"Jane","last_name":"Roe","email":"jane.roe@example.com","title":"Designer","deactivated":false}`,
+		}},
+		{"include set", []Option{WithJSONFields([]string{"email", " First_Name "}, nil)}, "Show employees as json", []string{
+			`{"first_name":"John","email":"john.doe@example.com"}`,
+			`{"first_name":"Jane","email":"jane.roe@example.com"}`,
+		}},
+		{"exclude set", []Option{WithJSONFields(nil, []string{"email", "has_2fa", "deactivated_date"})}, "Show employees as ndjson", []string{
+			`{"first_name":"John","last_name":"Doe","title":"Engineer","deactivated":true}`,
+			`{"first_name":"Jane","last_name":"Roe","title":"Designer","deactivated":false}`,
+		}},
+		{"include and exclude sets", []Option{WithJSONFields([]string{"first_name", "last_name", "email"}, []string{"email"})}, "Show employees as ndjson", []string{
+			`{"first_name":"John","last_name":"Doe"}`,
+			`{"first_name":"Jane","last_name":"Roe"}`,
+		}},
+	}
+
+	for _, tt := range tests {
+		output, err := NewJSONQuery(tt.opts...).ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("%s: error processing query: %v", tt.name, err)
+		}
+
+		// Compact the JSON array so that both outputs can be compared line by line
+		if strings.HasPrefix(output, "[") {
+			var objects []json.RawMessage
+			if err := json.Unmarshal([]byte(output), &objects); err != nil {
+				t.Fatalf("%s: invalid JSON output: %v\n%s", tt.name, err, output)
+			}
+			var lines []string
+			for _, object := range objects {
+				compacted, err := json.Marshal(object)
+				if err != nil {
+					t.Fatalf("%s: error compacting JSON: %v", tt.name, err)
+				}
+				lines = append(lines, string(compacted))
+			}
+			output = strings.Join(lines, "\n") + "\n"
+		}
+
+		if lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n"); !reflect.DeepEqual(lines, tt.expected) {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, strings.Join(tt.expected, "\n"), output)
+		}
+	}
+}
+
+func TestValidateJSONFields(t *testing.T) {
+	if err := ValidateJSONFields([]string{"first_name", "Email", "has_2fa"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := ValidateJSONFields([]string{"first_name", "salary"}); err == nil || !strings.Contains(err.Error(), "salary") {
+		t.Errorf("Expected an error for unknown field salary, got %v", err)
+	}
+}
+
+func TestCompactJSON(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+	})
+	opts := []Option{WithJSONFields([]string{"first_name", "last_name", "deactivated_date"}, nil)}
+
+	indented, err := NewJSONQuery(opts...).ProcessQuery(data, "Show employees as json")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	expected := "[\n" +
+		"  {\n    \"first_name\": \"John\",\n    \"last_name\": \"Doe\"\n  },\n" +
+		"  {\n    \"first_name\": \"Jane\",\n    \"last_name\": \"Roe\",\n    \"deactivated_date\": \"2023-03-15\"\n  }\n" +
+		"]\n"
+	if indented != expected {
+		t.Errorf("Expected indented JSON:\n%s\ngot:\n%s", expected, indented)
+	}
+
+	compact, err := NewJSONQuery(append(opts, WithCompactJSON(true))...).ProcessQuery(data, "Show employees as json")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	expected = `[{"first_name":"John","last_name":"Doe"},{"first_name":"Jane","last_name":"Roe","deactivated_date":"2023-03-15"}]` + "\n"
+	if compact != expected {
+		t.Errorf("Expected compact JSON:\n%s\ngot:\n%s", expected, compact)
+	}
+
+	// Empty results are an empty array either way
+	empty, err := NewJSONQuery(WithCompactJSON(true)).ProcessQuery(data, "Show employees deactivated on 2020-01-01 as json")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if empty != "[]\n" {
+		t.Errorf("Expected an empty array, got %q", empty)
+	}
+}
+
+func TestJSONOutputAfterFilteringSortingAndLimiting(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2024-01-10"},
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2022-07-01"},
+	})
+	q := NewJSONQuery()
+
+	output, err := q.ProcessQuery(data, "Show the last 2 deactivated employees as json")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	var employees []model.EmployeeInfo
+	if err := json.Unmarshal([]byte(output), &employees); err != nil {
+		t.Fatalf("Invalid JSON output: %v\n%s", err, output)
+	}
+	if len(employees) != 2 || employees[0].FirstName != "Max" || employees[1].FirstName != "John" {
+		t.Errorf("Expected Max then John, got %+v", employees)
+	}
+
+	// Empty deactivation dates are omitted
+	output, err = q.ProcessQuery(data, "Active employees, json output")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.HasPrefix(output, "[") || !strings.Contains(output, `"first_name": "Jane"`) || strings.Contains(output, "deactivated_date") {
+		t.Errorf("Expected Jane without deactivation date, got:\n%s", output)
+	}
+}
@@ -0,0 +1,22 @@
+package json
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithLogOutput sets where the progress messages of the queries are written (e.g. "📋 Using CSV format"),
+// the standard output by default. A nil writer suppresses them, e.g. in quiet mode
+func WithLogOutput(w io.Writer) Option {
+	return func(q *JSONQuery) {
+		q.logOutput = w
+		if w == nil {
+			q.logOutput = io.Discard
+		}
+	}
+}
+
+// logf writes a progress message to the log output
+func (q *JSONQuery) logf(format string, args ...any) {
+	fmt.Fprintf(q.logOutput, format, args...)
+}
@@ -0,0 +1,52 @@
+package json
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what fn writes to the standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Error creating pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestLogOutput(t *testing.T) {
+	data := []byte(`[{"first_name":"Jane","last_name":"Doe","title":"Designer","deactivated":false}]`)
+
+	var logs bytes.Buffer
+	stdout := captureStdout(t, func() {
+		if _, err := NewJSONQuery(WithLogOutput(&logs)).ProcessQuery(data, "active employees as csv"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	if !strings.Contains(logs.String(), "📋 Using CSV format\n") || !strings.Contains(logs.String(), "🔎 Filtered to active employees\n") {
+		t.Errorf("Expected the progress messages in the log output, got %q", logs.String())
+	}
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+
+	// Suppressed
+	stdout = captureStdout(t, func() {
+		NewJSONQuery(WithLogOutput(nil)).ProcessQuery(data, "active employees as csv")
+	})
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+}
@@ -0,0 +1,39 @@
+package json
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// presenceFilterPattern matches the queries on the presence of the employees, e.g. "who is online", "are currently
+// away" or "who's offline". The presence word alone is not enough, so that "online marketing manager" is not filtered
+var presenceFilterPattern = regexp.MustCompile(`\b(?:is|are|was|were|be|being|who's|currently|now)\s+` +
+	`(?:currently\s+|now\s+|still\s+)?(` + strings.Join(model.PresenceWords, "|") + `)\b`)
+
+// parsePresenceFilter extracts the presence asked for in the lowercased query, "active" for online employees
+// and "away" for the offline ones
+func parsePresenceFilter(query string) (string, bool) {
+	matches := presenceFilterPattern.FindStringSubmatch(query)
+	if matches == nil {
+		return "", false
+	}
+	if matches[1] == "online" {
+		return "active", true
+	}
+	return "away", true
+}
+
+// filterByPresence keeps the employees with the given presence, and counts those whose presence is unknown
+// (deactivated employees, or data fetched without the presence)
+func filterByPresence(employees []model.EmployeeInfo, presence string) ([]model.EmployeeInfo, int) {
+	unknown := 0
+	filtered := filterBy(employees, func(emp model.EmployeeInfo) bool {
+		if emp.Presence == "" {
+			unknown++
+		}
+		return emp.Presence == presence
+	})
+	return filtered, unknown
+}
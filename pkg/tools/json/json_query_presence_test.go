@@ -0,0 +1,57 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestPresenceFilter(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Presence: "active"},
+		{FirstName: "Max", LastName: "Poe", Presence: "away"},
+		{FirstName: "Jane", LastName: "Doe", Deactivated: true},
+	})
+
+	output, err := NewJSONQuery().ProcessQuery(data, "Who is online?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "1. John Doe - Presence: active\n") || strings.Contains(output, "Max Poe") {
+		t.Errorf("Expected John Doe only, got:\n%s", output)
+	}
+	if !strings.HasPrefix(output, "Note: presence is unknown for 1 employees") {
+		t.Errorf("Expected a note on the unknown presence, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery().ProcessQuery(data, "How many active employees are away?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "1 active employee away." {
+		t.Errorf("Expected a count of 1 away employee, got:\n%s", output)
+	}
+}
+
+func TestParsePresenceFilter(t *testing.T) {
+	tests := []struct {
+		query    string
+		presence string
+		ok       bool
+	}{
+		{"who is online?", "active", true},
+		{"which engineers are currently away?", "away", true},
+		{"who's offline", "away", true},
+		{"list the online marketing managers", "", false},
+		{"who works on offline payments?", "", false},
+		{"employees who moved away from paris", "", false},
+	}
+
+	for _, tt := range tests {
+		presence, ok := parsePresenceFilter(tt.query)
+		if presence != tt.presence || ok != tt.ok {
+			t.Errorf("%q: expected %q and %v, got %q and %v", tt.query, tt.presence, tt.ok, presence, ok)
+		}
+	}
+}
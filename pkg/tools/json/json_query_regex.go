@@ -0,0 +1,59 @@
+package json
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// regexClausePattern matches a regular expression search in a query, on the name or the title of the employees,
+// e.g. `regex title "Senior.*Engineer"` or `regex name '^J'`. Patterns without spaces may be left unquoted
+var regexClausePattern = regexp.MustCompile(`(?i)\bregex\s+(name|title)\s+(?:"([^"]*)"|'([^']*)'|(\S+))`)
+
+// regexFilter keeps the employees whose name or title matches a regular expression
+type regexFilter struct {
+	field   string // "name" (first and last names) or "title"
+	pattern *regexp.Regexp
+	source  string // The pattern as written in the query
+}
+
+// String describes the filter, e.g. `title matching "Senior.*Engineer"`
+func (f regexFilter) String() string {
+	return fmt.Sprintf("%s matching %q", f.field, f.source)
+}
+
+// match determines if the name or the title of the employee matches the regular expression
+func (f regexFilter) match(emp model.EmployeeInfo) bool {
+	if f.field == "title" {
+		return f.pattern.MatchString(emp.Title)
+	}
+	return f.pattern.MatchString(strings.TrimSpace(emp.FirstName + " " + emp.LastName))
+}
+
+// parseRegexFilter extracts the regular expression search from the query, before it is lowercased so that
+// case-sensitive patterns keep their case. Matching is case-insensitive unless caseSensitive is true
+// It returns the query without the search, so that the pattern does not trigger the keyword filters
+func parseRegexFilter(query string, caseSensitive bool) (regexFilter, string, bool, error) {
+	matches := regexClausePattern.FindStringSubmatch(query)
+	if matches == nil {
+		return regexFilter{}, query, false, nil
+	}
+
+	filter := regexFilter{field: strings.ToLower(matches[1]), source: matches[2] + matches[3] + matches[4]}
+	rest := regexClausePattern.ReplaceAllString(query, "")
+
+	expr := filter.source
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return regexFilter{}, query, false, fmt.Errorf("invalid regex: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+	filter.pattern = pattern
+
+	return filter, strings.Join(strings.Fields(rest), " "), true, nil
+}
@@ -0,0 +1,64 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestRegexFilter(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Senior Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Senior Product Manager"},
+		{FirstName: "Max", LastName: "Poe", Title: "Staff Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "julia", LastName: "Smith", Title: "senior data engineer"},
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+		missing  []string
+	}{
+		{"title pattern", `find all titles matching regex title "Senior.*Engineer"`, []string{"John Doe", "julia Smith"}, []string{"Jane Roe", "Max Poe"}},
+		{"name pattern", `list employees regex name '^J'`, []string{"John Doe", "Jane Roe", "julia Smith"}, []string{"Max Poe"}},
+		{"case sensitive", `list employees regex name ^J case sensitive`, []string{"John Doe", "Jane Roe"}, []string{"julia Smith"}},
+		{"combined with the status", `list deactivated employees regex title "engineer$"`, []string{"Max Poe"}, []string{"John Doe", "julia Smith"}},
+	}
+
+	for _, tt := range tests {
+		output, err := NewJSONQuery().ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("%s: error processing query: %v", tt.name, err)
+		}
+		for _, name := range tt.expected {
+			if !strings.Contains(output, name) {
+				t.Errorf("%s: expected %s in the results, got:\n%s", tt.name, name, output)
+			}
+		}
+		for _, name := range tt.missing {
+			if strings.Contains(output, name) {
+				t.Errorf("%s: unexpected %s in the results, got:\n%s", tt.name, name, output)
+			}
+		}
+	}
+
+	// No match
+	output, err := NewJSONQuery().ProcessQuery(data, `list employees regex title "^Principal"`)
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != NoResultsMessage {
+		t.Errorf("Expected no results, got:\n%s", output)
+	}
+
+	// Malformed pattern
+	output, err = NewJSONQuery().ProcessQuery(data, `list employees regex name "[J"`)
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.HasPrefix(output, "Error: invalid regex: missing closing ]") {
+		t.Errorf("Expected an invalid regex error, got %q", output)
+	}
+}
@@ -0,0 +1,64 @@
+package json
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeDatePattern matches a range of deactivation dates relative to today, e.g. "in the last 30 days",
+// "this quarter" or "yesterday"
+var relativeDatePattern = regexp.MustCompile(`\b(?:(?:in|during|over|within)\s+the\s+)?(?:last|past)\s+(\d+)\s+days?\b|\b(?:(?:in|during)\s+)?this\s+(week|month|quarter|year)\b|\byesterday\b`)
+
+// WithLocation sets the time zone of today for the relative date ranges (e.g. "this month"), the local one if nil
+func WithLocation(location *time.Location) Option {
+	return func(q *JSONQuery) {
+		if location != nil {
+			q.location = location
+		}
+	}
+}
+
+// today returns the current day in the time zone of the query, at midnight UTC like the deactivation days
+func (q *JSONQuery) today() time.Time {
+	now := q.now().In(q.location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseRelativeDateRange extracts the range of deactivation dates relative to today from the lowercased query
+// "last N days" includes today and the N-1 days before it, while the current week (starting on Monday), month,
+// quarter or year ends today. It returns the query without the range, so that "last 30" is not taken as a limit
+func parseRelativeDateRange(query string, today time.Time) (dateRange, string, bool) {
+	matches := relativeDatePattern.FindStringSubmatchIndex(query)
+	if matches == nil {
+		return dateRange{}, query, false
+	}
+
+	r := dateRange{To: today, Description: strings.TrimSpace(query[matches[0]:matches[1]])}
+	switch {
+	case matches[2] >= 0:
+		days, err := strconv.Atoi(query[matches[2]:matches[3]])
+		if err != nil || days < 1 {
+			return dateRange{}, query, false
+		}
+		r.From = today.AddDate(0, 0, 1-days)
+	case matches[4] >= 0:
+		switch query[matches[4]:matches[5]] {
+		case "week":
+			r.From = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		case "month":
+			r.From = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		case "quarter":
+			r.From = time.Date(today.Year(), (today.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		default:
+			r.From = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+	default:
+		r.From = today.AddDate(0, 0, -1)
+		r.To = r.From
+	}
+
+	rest := query[:matches[0]] + " " + query[matches[1]:]
+	return r, strings.Join(strings.Fields(rest), " "), true
+}
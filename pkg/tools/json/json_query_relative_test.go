@@ -0,0 +1,69 @@
+package json
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestRelativeDateRange(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2024-05-15"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "2024-05-14"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2024-05-09"},
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2024-05-08"},
+		{FirstName: "Sam", LastName: "Orr", Deactivated: true, DeactivatedDate: "2024-05-01"},
+		{FirstName: "Liz", LastName: "Ode", Deactivated: true, DeactivatedDate: "2024-04-30"},
+		{FirstName: "Joe", LastName: "Tan", Deactivated: true, DeactivatedDate: "2024-01-02"},
+		{FirstName: "Bob", LastName: "Ray", Deactivated: true},
+		{FirstName: "Eve", LastName: "Kim", Deactivated: true, DeactivatedDate: "unknown"},
+		{FirstName: "Tom", LastName: "Fox"},
+	}
+	data := mustMarshal(t, employees)
+
+	// Wednesday May 15, 2024 in Paris, still May 14 in UTC
+	paris := time.FixedZone("CEST", 2*60*60)
+	clock := func() time.Time { return time.Date(2024, 5, 14, 23, 30, 0, 0, time.UTC) }
+	q := NewJSONQuery(WithClock(clock), WithLocation(paris))
+
+	all := []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Sam Orr", "Liz Ode", "Joe Tan", "Bob Ray", "Eve Kim", "Tom Fox"}
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		// Today and the 6 days before it
+		{"Who was deactivated in the last 7 days?", []string{"John Doe", "Jane Roe", "Max Poe"}},
+		{"Who was deactivated this month?", []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Sam Orr"}},
+		{"Employees deactivated this week", []string{"John Doe", "Jane Roe"}},
+		{"Employees deactivated this quarter", []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Sam Orr", "Liz Ode"}},
+		{"Employees deactivated this year", []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Sam Orr", "Liz Ode", "Joe Tan"}},
+		{"Who was deactivated yesterday?", []string{"Jane Roe"}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		for _, name := range all {
+			expected := false
+			for _, e := range tt.expected {
+				expected = expected || e == name
+			}
+			if strings.Contains(output, name) != expected {
+				t.Errorf("Query %q: expected %s in output=%t, got:\n%s", tt.query, name, expected, output)
+			}
+		}
+	}
+
+	// The number of days is not a limit
+	output, err := q.ProcessQuery(data, "How many employees were deactivated in the last 2 days?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "2 deactivated employees deactivated in the last 2 days.") {
+		t.Errorf("Expected the count of the last 2 days, got:\n%s", output)
+	}
+}
@@ -0,0 +1,90 @@
+package json
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// maxRoleWords is the maximum number of words of a role, e.g. "senior software engineer"
+const maxRoleWords = 3
+
+// roleTriggerPattern matches the explicit phrasing introducing a role, e.g. "role engineer", "whose title is
+// marketing manager", "titled designer" or "who are the engineers"
+var roleTriggerPattern = regexp.MustCompile(`\b(?:roles?(?:\s+(?:of|is|as))?|(?:job\s+)?titles?\s+(?:of|is)|titled|who\s+(?:are|were)\s+the)\s+`)
+
+// parseRoleFilter extracts the role introduced by an explicit phrasing in the lowercased query, e.g. "who are the
+// marketing managers" or "with title engineer". Only phrases found as whole words in the titles of the given
+// employees are candidates, among the words following the phrasing, and the longest one wins, so that the other
+// words of the query are ignored. The role is returned in its singular form
+func (q *JSONQuery) parseRoleFilter(query string, employees []model.EmployeeInfo) (string, bool) {
+	titles := make([]string, 0, len(employees))
+	for _, emp := range employees {
+		if title := strings.ToLower(strings.TrimSpace(emp.Title)); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	if len(titles) == 0 {
+		return "", false
+	}
+
+	for _, trigger := range roleTriggerPattern.FindAllStringIndex(query, -1) {
+		// The role is among the next words, a qualifier (e.g. "active") possibly coming first
+		var words []string
+		for _, word := range strings.Fields(query[trigger[1]:]) {
+			word = strings.Trim(word, "?!.,:;()\"'")
+			if len(words) > maxRoleWords || !q.isRoleWord(word) {
+				break
+			}
+			words = append(words, singular(word))
+		}
+
+		// Look for the longest phrase found in a title
+		for size := min(len(words), maxRoleWords); size >= 1; size-- {
+			for start := 0; start+size <= len(words); start++ {
+				role := strings.Join(words[start:start+size], " ")
+				rolePattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(role) + `\b`)
+				if slices.ContainsFunc(titles, rolePattern.MatchString) {
+					return role, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isRoleWord determines if a word following the phrasing of a role may be part of it
+// Seniority levels are left to the seniority filter, as titles may abbreviate them (e.g. "Sr.")
+func (q *JSONQuery) isRoleWord(word string) bool {
+	if len(word) < 3 || genericNouns[word] || q.isCustomFilterKeyword(word) ||
+		q.seniorityLevel(strings.TrimSuffix(word, "+")) >= 0 {
+		return false
+	}
+	if _, err := strconv.Atoi(word); err == nil {
+		return false
+	}
+	return !strings.Contains(word, "@")
+}
+
+// singular returns the singular form of a plural word, e.g. "engineers" or "secretaries"
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
+
+// filterByRole keeps the employees whose title contains the role, ignoring the case unless caseSensitive is true
+func filterByRole(employees []model.EmployeeInfo, role string, caseSensitive bool) []model.EmployeeInfo {
+	return filterBy(employees, func(emp model.EmployeeInfo) bool {
+		return containsCase(emp.Title, role, caseSensitive)
+	})
+}
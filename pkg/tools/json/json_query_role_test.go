@@ -0,0 +1,72 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestRoleFilter(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Data Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Max", LastName: "Poe", Title: "Marketing Manager", Deactivated: true, DeactivatedDate: "2023-04-01"},
+		{FirstName: "Ann", LastName: "Lee", Title: "Product Manager", Deactivated: true, DeactivatedDate: "2023-05-01"},
+		{FirstName: "Bob", LastName: "Ray", Title: "Marketing Manager"},
+		{FirstName: "Eve", LastName: "Kim"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected []string
+		excluded []string
+	}{
+		{"List all active employees with role engineer", []string{"John Doe"}, []string{"Jane Roe", "Max Poe", "Ann Lee", "Bob Ray", "Eve Kim"}},
+		{"Who are the engineers?", []string{"John Doe", "Jane Roe"}, []string{"Max Poe", "Ann Lee", "Bob Ray", "Eve Kim"}},
+		// Multi-word roles compose with the status filters
+		{"Who are the deactivated marketing managers?", []string{"Max Poe"}, []string{"John Doe", "Jane Roe", "Ann Lee", "Bob Ray", "Eve Kim"}},
+		{"List employees titled manager", []string{"Max Poe", "Ann Lee", "Bob Ray"}, []string{"John Doe", "Jane Roe", "Eve Kim"}},
+		// Queries without an explicit role are not filtered, whatever their other words
+		{"List all active engineers", []string{"John Doe", "Bob Ray", "Eve Kim"}, []string{"Jane Roe", "Max Poe", "Ann Lee"}},
+		{"Show the marketing team please", []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Bob Ray", "Eve Kim"}, nil},
+		{"Who are the employees sorted by title then name?", []string{"John Doe", "Jane Roe", "Max Poe", "Ann Lee", "Bob Ray", "Eve Kim"}, nil},
+		{"List all active employees", []string{"John Doe", "Bob Ray", "Eve Kim"}, []string{"Jane Roe", "Max Poe", "Ann Lee"}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		for _, name := range tt.expected {
+			if !strings.Contains(output, name) {
+				t.Errorf("Query %q: expected %s in output:\n%s", tt.query, name, output)
+			}
+		}
+		for _, name := range tt.excluded {
+			if strings.Contains(output, name) {
+				t.Errorf("Query %q: unexpected %s in output:\n%s", tt.query, name, output)
+			}
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"engineers":   "engineer",
+		"secretaries": "secretary",
+		"managers":    "manager",
+		"sales":       "sale",
+		"boss":        "boss",
+		"designer":    "designer",
+	}
+
+	for word, expected := range tests {
+		if got := singular(word); got != expected {
+			t.Errorf("singular(%q): expected %q, got %q", word, expected, got)
+		}
+	}
+}
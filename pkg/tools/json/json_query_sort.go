@@ -0,0 +1,202 @@
+package json
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// Fields employees can be sorted on
+const (
+	sortFieldName      = "name"
+	sortFieldFirstName = "first name"
+	sortFieldTitle     = "title"
+	sortFieldEmail     = "email"
+	sortFieldStatus    = "status"
+	sortFieldDate      = "deactivation date"
+)
+
+// sortKey is one level of a multi-level sort
+type sortKey struct {
+	Field      string
+	Descending bool
+}
+
+// String describes the sort key for logging
+func (k sortKey) String() string {
+	if k.Descending {
+		return k.Field + " (descending)"
+	}
+	return k.Field
+}
+
+// sortClausePattern matches the sort clause of a query, e.g. "sort by title then by deactivation date"
+// or "sort active employees by last name"
+var sortClausePattern = regexp.MustCompile(`\bsort(?:ed)?(?:\s+[a-z]+){0,3}?\s+by\s+([^?!.,;]+)`)
+
+// sortKeySeparatorPattern splits the sort clause into its keys
+var sortKeySeparatorPattern = regexp.MustCompile(`\s+(?:then|and then)\s+(?:by\s+)?`)
+
+// parseSortKeys extracts the ordered sort keys from the lowercased query
+// Without sort clause, "last"/"recent" sort by deactivation date (most recent first, oldest first if the query
+// says "oldest", "ascending" or "asc") and "alphabetical" sorts by name
+func parseSortKeys(query string) []sortKey {
+	if matches := sortClausePattern.FindStringSubmatch(query); matches != nil {
+		var keys []sortKey
+		for _, part := range sortKeySeparatorPattern.Split(matches[1], -1) {
+			key, ok := parseSortKey(part)
+			if !ok {
+				break
+			}
+			keys = append(keys, key)
+		}
+		if len(keys) > 0 {
+			return withNameTieBreaker(keys)
+		}
+	}
+
+	var keys []sortKey
+	if strings.Contains(query, "alphabetical") {
+		keys = append(keys, sortKey{Field: sortFieldName})
+	}
+	if containsAny(query, "last", "recent", "oldest", "earliest") || earliestCountPattern.MatchString(query) {
+		keys = append(keys, sortKey{Field: sortFieldDate, Descending: !isAscendingQuery(query)})
+	}
+	return keys
+}
+
+// earliestCountPattern matches a limit on the earliest deactivations, e.g. "first 10" (but not "first name")
+var earliestCountPattern = regexp.MustCompile(`\b(?:first|earliest) \d+\b`)
+
+// isAscendingQuery determines if the lowercased query asks for the oldest deactivations first
+func isAscendingQuery(query string) bool {
+	if containsAny(query, "oldest", "earliest", "ascending") || earliestCountPattern.MatchString(query) {
+		return true
+	}
+	for _, word := range strings.Fields(query) {
+		if strings.Trim(word, "?!.,()") == "asc" {
+			return true
+		}
+	}
+	return false
+}
+
+// withNameTieBreaker appends a sort by last name to sorts on titles or first names, so that the order of the
+// employees sharing them is deterministic
+func withNameTieBreaker(keys []sortKey) []sortKey {
+	needed := false
+	for _, key := range keys {
+		switch key.Field {
+		case sortFieldName:
+			return keys
+		case sortFieldTitle, sortFieldFirstName:
+			needed = true
+		}
+	}
+
+	if needed {
+		keys = append(keys, sortKey{Field: sortFieldName})
+	}
+	return keys
+}
+
+// parseSortKey parses one part of a sort clause, e.g. "title", "first name desc" or "deactivation date ascending"
+// Names are sorted by last name then first name unless "first name" is used
+// Deactivation dates are sorted most recent first unless stated otherwise, other fields in ascending order
+func parseSortKey(part string) (sortKey, bool) {
+	var key sortKey
+
+	switch {
+	case containsAny(part, "date", "deactivation"):
+		key = sortKey{Field: sortFieldDate, Descending: true}
+	case strings.Contains(part, "first name"):
+		key = sortKey{Field: sortFieldFirstName}
+	case containsAny(part, "name", "alphabetical"):
+		key = sortKey{Field: sortFieldName}
+	case containsAny(part, "title", "role", "job"):
+		key = sortKey{Field: sortFieldTitle}
+	case strings.Contains(part, "email"):
+		key = sortKey{Field: sortFieldEmail}
+	case strings.Contains(part, "status"):
+		key = sortKey{Field: sortFieldStatus}
+	default:
+		return sortKey{}, false
+	}
+
+	if containsAny(part, "desc", "reverse", "newest", "most recent", "z-a") {
+		key.Descending = true
+	} else if containsAny(part, "asc", "oldest", "a-z") {
+		key.Descending = false
+	}
+
+	return key, true
+}
+
+// sortEmployees sorts employees on the given keys, each key breaking the ties of the previous ones
+// The sort is stable so that employees tied on all keys keep their original order
+func (q *JSONQuery) sortEmployees(employees []model.EmployeeInfo, keys []sortKey) {
+	sort.SliceStable(employees, func(i, j int) bool {
+		for _, key := range keys {
+			if c := q.compareOn(key, employees[i], employees[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// compareOn compares two employees on a sort key, returning a negative number if a comes first
+// Missing titles and deactivation dates always come last, whatever the direction
+func (q *JSONQuery) compareOn(key sortKey, a, b model.EmployeeInfo) int {
+	var c int
+
+	switch key.Field {
+	case sortFieldName:
+		if c = q.collator.CompareString(a.LastName, b.LastName); c == 0 {
+			c = q.collator.CompareString(a.FirstName, b.FirstName)
+		}
+	case sortFieldFirstName:
+		c = q.collator.CompareString(a.FirstName, b.FirstName)
+	case sortFieldTitle:
+		if missing := compareMissing(a.Title == "", b.Title == ""); missing != 0 {
+			return missing
+		}
+		c = q.collator.CompareString(a.Title, b.Title)
+	case sortFieldEmail:
+		c = strings.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email))
+	case sortFieldStatus:
+		// Active employees first
+		if a.Deactivated != b.Deactivated {
+			c = 1
+			if b.Deactivated {
+				c = -1
+			}
+		}
+	case sortFieldDate:
+		dateA, errA := q.parseDeactivationDate(a.DeactivatedDate)
+		dateB, errB := q.parseDeactivationDate(b.DeactivatedDate)
+		if missing := compareMissing(errA != nil, errB != nil); missing != 0 {
+			return missing
+		}
+		c = dateA.Compare(dateB)
+	}
+
+	if key.Descending {
+		return -c
+	}
+	return c
+}
+
+// compareMissing orders missing values after present ones
+func compareMissing(missingA, missingB bool) int {
+	switch {
+	case missingA == missingB:
+		return 0
+	case missingA:
+		return 1
+	default:
+		return -1
+	}
+}
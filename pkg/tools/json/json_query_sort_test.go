@@ -0,0 +1,225 @@
+package json
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []sortKey
+	}{
+		{"list deactivated employees sorted by title then by deactivation date", []sortKey{
+			{Field: sortFieldTitle}, {Field: sortFieldDate, Descending: true}, {Field: sortFieldName},
+		}},
+		{"sort employees by last name", []sortKey{
+			{Field: sortFieldName},
+		}},
+		{"sort by first name", []sortKey{
+			{Field: sortFieldFirstName}, {Field: sortFieldName},
+		}},
+		{"sort by title", []sortKey{
+			{Field: sortFieldTitle}, {Field: sortFieldName},
+		}},
+		{"employees sort by status then name desc then email", []sortKey{
+			{Field: sortFieldStatus}, {Field: sortFieldName, Descending: true}, {Field: sortFieldEmail},
+		}},
+		{"sort by deactivation date oldest first then by last name", []sortKey{
+			{Field: sortFieldDate}, {Field: sortFieldName},
+		}},
+		{"who are the last 10 deactivated employees?", []sortKey{
+			{Field: sortFieldDate, Descending: true},
+		}},
+		{"show deactivated employees oldest first", []sortKey{
+			{Field: sortFieldDate},
+		}},
+		{"last 10 deactivated employees, asc", []sortKey{
+			{Field: sortFieldDate},
+		}},
+		{"active employees in alphabetical order", []sortKey{
+			{Field: sortFieldName},
+		}},
+		{"first 10 deactivated employees", []sortKey{
+			{Field: sortFieldDate},
+		}},
+		{"earliest 5 deactivated employees", []sortKey{
+			{Field: sortFieldDate},
+		}},
+		{"how many employees are active?", nil},
+	}
+
+	for _, tt := range tests {
+		if keys := parseSortKeys(tt.query); !reflect.DeepEqual(keys, tt.expected) {
+			t.Errorf("Query %q: expected %v, got %v", tt.query, tt.expected, keys)
+		}
+	}
+}
+
+func TestMultiKeySort(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Ann", LastName: "Lee", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-01-10"},
+		{FirstName: "Bob", LastName: "Ray", Title: "Designer", Deactivated: true, DeactivatedDate: "2022-05-01"},
+		{FirstName: "Cid", LastName: "Fox", Title: "Engineer", Deactivated: true},
+		{FirstName: "Dan", LastName: "Orr", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-06-20"},
+		{FirstName: "Eve", LastName: "Kim", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-02-14"},
+		{FirstName: "Fay", LastName: "Ng", Deactivated: true, DeactivatedDate: "2024-01-01"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		// Ties on the title are ordered by deactivation date, most recent first, missing dates last
+		{"List deactivated employees sorted by title then by deactivation date", []string{
+			"Eve Kim", "Bob Ray", "Dan Orr", "Ann Lee", "Cid Fox", "Fay Ng",
+		}},
+		{"List deactivated employees sorted by title then by deactivation date oldest first", []string{
+			"Bob Ray", "Eve Kim", "Ann Lee", "Dan Orr", "Cid Fox", "Fay Ng",
+		}},
+		{"List deactivated employees sorted by title desc then by name", []string{
+			"Cid Fox", "Ann Lee", "Dan Orr", "Eve Kim", "Bob Ray", "Fay Ng",
+		}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if names := namesInOrder(t, output, tt.expected); !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("Query %q: expected order %v, got %v\n%s", tt.query, tt.expected, names, output)
+		}
+	}
+
+	// Employees tied on all keys keep their original order
+	tied := make([]model.EmployeeInfo, 0, 20)
+	for i := 0; i < 20; i++ {
+		tied = append(tied, model.EmployeeInfo{FirstName: string(rune('A' + i)), LastName: "Doe", Title: "Engineer"})
+	}
+	q.sortEmployees(tied, []sortKey{{Field: sortFieldTitle}, {Field: sortFieldDate, Descending: true}})
+	for i, emp := range tied {
+		if emp.FirstName != string(rune('A'+i)) {
+			t.Fatalf("Expected tied employees to keep their original order, got %v at position %d", emp.FirstName, i)
+		}
+	}
+}
+
+func TestSortByDeactivationDateDirection(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Empty", LastName: "One", Deactivated: true},
+		{FirstName: "Mid", LastName: "Date", Deactivated: true, DeactivatedDate: "2023-02-01"},
+		{FirstName: "Bad", LastName: "Date", Deactivated: true, DeactivatedDate: "not-a-date"},
+		{FirstName: "Old", LastName: "Date", Deactivated: true, DeactivatedDate: "2021-07-30"},
+		{FirstName: "Empty", LastName: "Two", Deactivated: true},
+		{FirstName: "New", LastName: "Date", Deactivated: true, DeactivatedDate: "2024-11-05"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	// Empty and unparseable dates stay at the end, in their original order, whatever the direction
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"Show the most recent deactivated employees", []string{
+			"New Date", "Mid Date", "Old Date", "Empty One", "Bad Date", "Empty Two",
+		}},
+		{"Show deactivated employees oldest first", []string{
+			"Old Date", "Mid Date", "New Date", "Empty One", "Bad Date", "Empty Two",
+		}},
+		{"Sort by deactivation date ascending", []string{
+			"Old Date", "Mid Date", "New Date", "Empty One", "Bad Date", "Empty Two",
+		}},
+		{"Show the last 6 deactivated employees asc", []string{
+			"Old Date", "Mid Date", "New Date", "Empty One", "Bad Date", "Empty Two",
+		}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if names := namesInOrder(t, output, tt.expected); !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("Query %q: expected order %v, got %v", tt.query, tt.expected, names)
+		}
+	}
+}
+
+func TestFirstAndLastLimit(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2023-03-01"},
+		{FirstName: "Bob", LastName: "Ray", Deactivated: true, DeactivatedDate: "2021-01-15"},
+		{FirstName: "Cid", LastName: "Fox", Deactivated: true, DeactivatedDate: "2024-06-30"},
+		{FirstName: "Dan", LastName: "Orr", Deactivated: true, DeactivatedDate: "2022-09-10"},
+		{FirstName: "Eve", LastName: "Kim", Deactivated: true, DeactivatedDate: "2020-12-01"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected []string
+		excluded []string
+	}{
+		{"Show the first 3 deactivated employees", []string{"Eve Kim", "Bob Ray", "Dan Orr"}, []string{"Ann Lee", "Cid Fox"}},
+		{"Show the earliest 3 deactivated employees", []string{"Eve Kim", "Bob Ray", "Dan Orr"}, []string{"Ann Lee", "Cid Fox"}},
+		{"Show the last 3 deactivated employees", []string{"Cid Fox", "Ann Lee", "Dan Orr"}, []string{"Eve Kim", "Bob Ray"}},
+		// The numeric pattern still works
+		{"Show 2 employees deactivated most recently", []string{"Cid Fox", "Ann Lee"}, []string{"Dan Orr", "Eve Kim", "Bob Ray"}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if names := namesInOrder(t, output, tt.expected); !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("Query %q: expected order %v, got %v", tt.query, tt.expected, names)
+		}
+		for _, name := range tt.excluded {
+			if strings.Contains(output, name) {
+				t.Errorf("Query %q: unexpected %s in output:\n%s", tt.query, name, output)
+			}
+		}
+	}
+}
+
+func TestSortByNameOrTitle(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "bob", LastName: "Young", Title: "engineer"},
+		{FirstName: "Alice", LastName: "zimmer", Title: "Designer"},
+		{FirstName: "Carl", LastName: "Adams", Title: "Engineer"},
+		{FirstName: "alice", LastName: "Baker", Title: "Manager"},
+		{FirstName: "Dana", LastName: "Baker", Title: "designer"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"Sort employees by last name", []string{"Carl Adams", "alice Baker", "Dana Baker", "bob Young", "Alice zimmer"}},
+		// Ties on the first name fall back to the last name
+		{"Sort employees by first name", []string{"alice Baker", "Alice zimmer", "bob Young", "Carl Adams", "Dana Baker"}},
+		// Ties on the title (case-insensitive) fall back to the last name
+		{"Sort employees by title", []string{"Dana Baker", "Alice zimmer", "Carl Adams", "bob Young", "alice Baker"}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if names := namesInOrder(t, output, tt.expected); !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("Query %q: expected order %v, got %v", tt.query, tt.expected, names)
+		}
+	}
+}
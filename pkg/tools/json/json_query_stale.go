@@ -0,0 +1,52 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultStaleAfter is the default age after which employees data files are considered stale
+const DefaultStaleAfter = 24 * time.Hour
+
+// WithStaleAfter sets the age after which employees data files are considered stale and a warning is
+// prepended to the results. A threshold of 0 disables the warning
+func WithStaleAfter(threshold time.Duration) Option {
+	return func(q *JSONQuery) {
+		q.staleAfter = threshold
+	}
+}
+
+// staleDataWarning returns the warning for a data file last modified at modTime, if it is stale
+// No warning is returned for machine-readable outputs (JSON, NDJSON, CSV, HTML, YAML), asked for by the query or
+// by default (see WithDefaultFormat), as it would make them invalid
+func (q *JSONQuery) staleDataWarning(modTime time.Time, query string) (string, bool) {
+	query = strings.ToLower(query)
+	machineReadable := q.defaultFormat == "json" || q.defaultFormat == "csv" ||
+		containsAny(query, "json", "csv", "html") || isYAMLQuery(query)
+	if q.staleAfter <= 0 || machineReadable {
+		return "", false
+	}
+
+	age := q.now().Sub(modTime)
+	if age < q.staleAfter {
+		return "", false
+	}
+
+	return fmt.Sprintf("⚠️ Data is %s old; consider refreshing.", formatAge(age)), true
+}
+
+// formatAge formats the age of a data file in days, or in hours when less than a day old
+func formatAge(age time.Duration) string {
+	if days := int(age.Hours() / 24); days >= 1 {
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+
+	if hours := int(age.Hours()); hours != 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return "1 hour"
+}
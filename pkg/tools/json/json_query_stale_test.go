@@ -0,0 +1,80 @@
+package json
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestStaleDataWarning(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{{FirstName: "John", LastName: "Doe"}})
+
+	dir := t.TempDir()
+	freshPath := filepath.Join(dir, "employees-fresh.json")
+	oldPath := filepath.Join(dir, "employees-old.json")
+	for _, path := range []string{freshPath, oldPath} {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("Error writing fixture file: %v", err)
+		}
+	}
+	oldTime := time.Now().Add(-3*24*time.Hour - time.Hour)
+	if err := os.Chtimes(oldPath, oldTime, oldTime); err != nil {
+		t.Fatalf("Error setting fixture file time: %v", err)
+	}
+
+	const warning = "⚠️ Data is 3 days old; consider refreshing."
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		path     string
+		query    string
+		expected bool
+	}{
+		{"old file", nil, oldPath, "List all employees", true},
+		{"fresh file", nil, freshPath, "List all employees", false},
+		{"json output", nil, oldPath, "List all employees as json", false},
+		{"yaml output", nil, oldPath, "List all employees as yaml", false},
+		{"yml output", nil, oldPath, "List all employees as yml", false},
+		{"json output by default", []Option{WithDefaultFormat("json")}, oldPath, "List all employees", false},
+		{"csv output by default", []Option{WithDefaultFormat("csv")}, oldPath, "List all employees", false},
+		{"higher threshold", []Option{WithStaleAfter(7 * 24 * time.Hour)}, oldPath, "List all employees", false},
+		{"disabled", []Option{WithStaleAfter(0)}, oldPath, "List all employees", false},
+	}
+
+	for _, tt := range tests {
+		input := fmt.Sprintf(`{"file_path": %q, "query": %q}`, tt.path, tt.query)
+		output, err := NewJSONQueryTool(tt.opts...).Call(context.Background(), input)
+		if err != nil {
+			t.Fatalf("%s: error calling tool: %v", tt.name, err)
+		}
+		if got := strings.HasPrefix(output, warning+"\n\n"); got != tt.expected {
+			t.Errorf("%s: expected warning=%t, got:\n%s", tt.name, tt.expected, output)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{30 * time.Minute, "0 hours"},
+		{time.Hour, "1 hour"},
+		{5 * time.Hour, "5 hours"},
+		{25 * time.Hour, "1 day"},
+		{3*24*time.Hour + 2*time.Hour, "3 days"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.expected {
+			t.Errorf("formatAge(%s): expected %q, got %q", tt.age, tt.expected, got)
+		}
+	}
+}
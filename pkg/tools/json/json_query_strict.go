@@ -0,0 +1,44 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// errUnknownField is returned in strict mode for data with fields unknown to model.EmployeeInfo, wrapped in an error
+// naming the data source
+var errUnknownField = errors.New("fields unknown to this version")
+
+// WithStrictDecoding rejects the data files with fields unknown to model.EmployeeInfo instead of ignoring them,
+// e.g. in CI to catch a drift between the producer of the data files and this version
+func WithStrictDecoding(enabled bool) Option {
+	return func(q *JSONQuery) {
+		q.strictDecoding = enabled
+	}
+}
+
+// decodeEmployees decodes the JSON array of employees, rejecting unknown fields in strict mode
+// The source of the data (e.g. "inline data" or the data file) is named in the errors on the unknown fields
+func (q *JSONQuery) decodeEmployees(jsonData []byte, source string) ([]model.EmployeeInfo, error) {
+	var employees []model.EmployeeInfo
+	if !q.strictDecoding {
+		err := json.Unmarshal(jsonData, &employees)
+		return employees, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&employees); err != nil {
+		// The decoder has no typed error for the unknown fields
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			return nil, fmt.Errorf("%s has %w: %v", source, errUnknownField, err)
+		}
+		return nil, err
+	}
+	return employees, nil
+}
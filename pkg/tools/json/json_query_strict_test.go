@@ -0,0 +1,71 @@
+package json
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	data := []byte(`[{"first_name": "John", "last_name": "Doe"}, {"first_name": "Jane", "last_name": "Roe", "cost_center": "R&D"}]`)
+
+	// Unknown fields are ignored by default
+	output, err := NewJSONQuery(WithLogOutput(nil)).ProcessQuery(data, "List all employees")
+	if err != nil {
+		t.Fatalf("Unexpected error in lenient mode: %v", err)
+	}
+	if !strings.Contains(output, "John Doe") || !strings.Contains(output, "Jane Roe") {
+		t.Errorf("Expected both employees in lenient mode, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery(WithLogOutput(nil), WithStrictDecoding(true)).ProcessQuery(data, "List all employees")
+	if !errors.Is(err, errUnknownField) {
+		t.Fatalf("Expected an unknown field error in strict mode, got %v", err)
+	}
+	if !strings.Contains(output, `input data has fields unknown to this version: json: unknown field "cost_center"`) ||
+		strings.Contains(output, "John Doe") {
+		t.Errorf("Expected the unknown field to be reported, got:\n%s", output)
+	}
+
+	// The error names the source of the data
+	tool := NewJSONQueryTool(WithLogOutput(nil), WithStrictDecoding(true))
+	input := `{"data": ` + string(data) + `, "query": "List all employees"}`
+	if _, err := tool.Call(context.Background(), input); !errors.Is(err, errUnknownField) || !strings.HasPrefix(err.Error(), "inline data has") {
+		t.Errorf("Expected an unknown field error on the inline data, got %v", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "employees.json")
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		t.Fatalf("Error writing data file: %v", err)
+	}
+	input = `{"file_path": "` + filePath + `", "query": "List all employees"}`
+	if _, err := tool.Call(context.Background(), input); !errors.Is(err, errUnknownField) || !strings.HasPrefix(err.Error(), "file "+filePath+" has") {
+		t.Errorf("Expected an unknown field error on the data file, got %v", err)
+	}
+
+	// Known fields only are accepted in strict mode
+	known := []byte(`[{"first_name": "John", "last_name": "Doe", "deactivated": false}]`)
+	if _, err := NewJSONQuery(WithLogOutput(nil), WithStrictDecoding(true)).ProcessQuery(known, "List all employees"); err != nil {
+		t.Errorf("Unexpected error in strict mode with known fields: %v", err)
+	}
+}
+
+func TestStrictDecodingOfSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "employees-all-20240101-090000.json", employeesFixture(10, 2))
+	snapshot := filepath.Join(dir, "employees-all-20240201-090000.json")
+	if err := os.WriteFile(snapshot, []byte(`[{"first_name": "John", "cost_center": "R&D"}]`), 0600); err != nil {
+		t.Fatalf("Error writing snapshot: %v", err)
+	}
+
+	// The snapshots are decoded like the data files
+	if _, err := NewJSONQuery(WithLogOutput(nil)).HeadcountTrend(dir); err != nil {
+		t.Errorf("Unexpected error in lenient mode: %v", err)
+	}
+	_, err := NewJSONQuery(WithLogOutput(nil), WithStrictDecoding(true)).HeadcountTrend(dir)
+	if !errors.Is(err, errUnknownField) || !strings.HasPrefix(err.Error(), "snapshot "+snapshot+" has") {
+		t.Errorf("Expected an unknown field error on the snapshot, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package json
+
+import (
+	"fmt"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// QueryStructured runs the query like Query but returns the employees it lists instead of the formatted output,
+// so that they can be presented differently. They are filtered, sorted, offset and limited as in the output, and
+// the counting queries return the employees counted. The queries listing no employees (e.g. the email uniqueness
+// analysis) return nil, the invalid ones (e.g. with an invalid date or regular expression) an error
+// Nothing is formatted nor exported
+func (q *JSONQuery) QueryStructured(employees []model.EmployeeInfo, query string) ([]model.EmployeeInfo, error) {
+	q.resetLastQuery()
+
+	result, err := q.queryEmployees(employees, query, newStageTimer(false))
+	if err != nil {
+		q.logf("❌ %v\n", err)
+		return nil, err
+	}
+	q.recordResult(result)
+
+	return result.employees, nil
+}
+
+// ProcessQueryStructured runs the query on employee data given as a JSON array, see QueryStructured
+func (q *JSONQuery) ProcessQueryStructured(jsonData []byte, query string) ([]model.EmployeeInfo, error) {
+	employees, err := q.loadEmployees(jsonData, "input data")
+	if err != nil {
+		q.resetLastQuery()
+		return nil, err
+	}
+
+	return q.QueryStructured(employees, query)
+}
+
+// recordResult records the number of employees listed by the query, left unknown for the queries listing none
+func (q *JSONQuery) recordResult(result queryResult) {
+	if result.listed {
+		q.lastResultCount = len(result.employees)
+	}
+}
+
+// resetLastQuery forgets the result count and the timings of the last query, before processing another one
+func (q *JSONQuery) resetLastQuery() {
+	q.lastResultCount = -1
+	q.lastTimingsKnown = false
+}
+
+// queryError reports an invalid query in the output, where the agent can read it, rather than as an error
+// The error is still returned by the structured queries
+func (q *JSONQuery) queryError(err error) (string, error) {
+	q.logf("❌ %v\n", err)
+	q.lastResultCount = 0
+	return fmt.Sprintf("Error: %v.", err), nil
+}
@@ -0,0 +1,121 @@
+package json
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestQueryStructured(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Email: "john.smith@corp.com", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@corp.com", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@corp.com", Title: "Engineering Manager"},
+		{FirstName: "Bob", LastName: "Ray", Email: "bob.ray@corp.com", Title: "Sales Lead", Deactivated: true, DeactivatedDate: "2024-01-10"},
+		{FirstName: "Eve", LastName: "Kim", Email: "eve.kim@corp.com", Title: "Software Engineer"},
+	}
+	data := mustMarshal(t, employees)
+
+	// The structured results are the employees of the JSON output, offset and limit included
+	for _, query := range []string{
+		"Show all employees sorted by last name",
+		"Show active employees with role engineer sorted by first name",
+		"Show employees 2-3 sorted by last name",
+		"Top 2 employees sorted by last name descending",
+		"Deactivated employees in 2024",
+	} {
+		q := NewJSONQuery()
+		results, err := q.ProcessQueryStructured(data, query)
+		if err != nil {
+			t.Fatalf("Error querying %q: %v", query, err)
+		}
+
+		output, err := q.ProcessQuery(data, query+" as json")
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", query, err)
+		}
+		var expected []model.EmployeeInfo
+		if err := json.Unmarshal([]byte(output), &expected); err != nil {
+			t.Fatalf("Error decoding the JSON output of %q: %v\n%s", query, err, output)
+		}
+
+		if !reflect.DeepEqual(results, expected) {
+			t.Errorf("Query %q: expected %v, got %v", query, expected, results)
+		}
+		if count, _ := q.LastResultCount(); count != len(results) {
+			t.Errorf("Query %q: expected a result count of %d, got %d", query, len(results), count)
+		}
+	}
+
+	q := NewJSONQuery()
+
+	// Counting queries return the employees counted
+	results, err := q.QueryStructured(employees, "How many employees whose title is software engineer?")
+	if err != nil || len(results) != 2 || results[0].LastName != "Smith" || results[1].LastName != "Kim" {
+		t.Errorf("Expected the 2 software engineers, got %v, %v", results, err)
+	}
+
+	// Specific employee searches return the employees found
+	results, err = q.QueryStructured(employees, "Find Jane Roe")
+	if err != nil || len(results) != 1 || results[0].Email != "jane.roe@corp.com" {
+		t.Errorf("Expected Jane Roe, got %v, %v", results, err)
+	}
+
+	// Invalid queries fail instead of returning the error in the output
+	results, err = q.QueryStructured(employees, "Employees deactivated on 2023-02-30")
+	if err == nil || results != nil {
+		t.Errorf("Expected an invalid date error, got %v, %v", results, err)
+	}
+	results, err = q.QueryStructured(employees, `regex name "("`)
+	if err == nil || !strings.Contains(err.Error(), "invalid regex") || results != nil {
+		t.Errorf("Expected an invalid regex error, got %v, %v", results, err)
+	}
+
+	// The error of an invalid query is not kept for the next ones
+	if results, err := q.QueryStructured(employees, "Show active employees"); err != nil || len(results) != 3 {
+		t.Errorf("Expected the 3 active employees, got %v, %v", results, err)
+	}
+
+	// Queries not listing employees return no results
+	if results, err := q.QueryStructured(employees, "How many unique emails are there?"); err != nil || results != nil {
+		t.Errorf("Expected no results for the email uniqueness analysis, got %v, %v", results, err)
+	}
+
+	if _, err := q.ProcessQueryStructured([]byte(`{"first_name": "John"}`), "Show all employees"); err == nil {
+		t.Error("Expected an error for data that is not an array of employees")
+	}
+}
+
+func TestQueryStructuredDoesNotFormat(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Bob", LastName: "Ray", Title: "Sales Lead", Deactivated: true, DeactivatedDate: "2024-01-10"},
+	}
+	dataDir := t.TempDir()
+	csvPath := filepath.Join(dataDir, "export.csv")
+	q := NewJSONQuery(WithDataDir(dataDir), WithCSVExport(csvPath), WithLogOutput(nil))
+
+	// Nothing is exported, as the employees are returned instead of the formatted output
+	results, err := q.QueryStructured(employees, "Export the active employees to excel")
+	if err != nil || len(results) != 1 || results[0].LastName != "Smith" {
+		t.Errorf("Expected John Smith, got %v, %v", results, err)
+	}
+	if entries, err := os.ReadDir(dataDir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no exported file, got %v, %v", entries, err)
+	}
+
+	// The audit report lists the deactivated employees only
+	results, err = q.QueryStructured(employees, "Audit report sorted by last name")
+	if err != nil || len(results) != 2 || results[0].LastName != "Ray" || results[1].LastName != "Roe" {
+		t.Errorf("Expected Bob Ray and Jane Roe, got %v, %v", results, err)
+	}
+	if count, known := q.LastResultCount(); !known || count != 2 {
+		t.Errorf("Expected a result count of 2, got %d (known=%t)", count, known)
+	}
+}
@@ -0,0 +1,72 @@
+package json
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// summaryTopTitles is the number of most common titles listed in the workforce summary
+const summaryTopTitles = 5
+
+// summaryPattern matches the queries explicitly asking for an overview of the workforce, e.g. "summarize the workforce",
+// "headcount summary" or "overview of the workforce". Other queries mentioning a summary (e.g. "summary of deactivated
+// engineers as csv") are left to the filters
+var summaryPattern = regexp.MustCompile(`\b(?:workforce|headcount)\s+(?:summary|overview|statistics|stats)\b` +
+	`|\bsummar(?:ize|ise)\s+(?:the\s+)?(?:[a-z]+\s+)?(?:workforce|headcount)\b` +
+	`|\b(?:summary|overview|statistics|stats)\s+of\s+the\s+(?:whole\s+|entire\s+)?(?:workforce|headcount)\b`)
+
+// isSummaryQuery determines if the lowercased query asks for the workforce summary
+func isSummaryQuery(query string) bool {
+	return summaryPattern.MatchString(query)
+}
+
+// formatSummary formats the overview of all the employees as markdown: the total, active and deactivated counts,
+// with their share rounded to one decimal, and the most common titles, employees without title being left out
+func formatSummary(employees []model.EmployeeInfo) string {
+	var active, deactivated int
+	var titled []model.EmployeeInfo
+	for _, emp := range employees {
+		if emp.Deactivated {
+			deactivated++
+		} else {
+			active++
+		}
+		if strings.TrimSpace(emp.Title) != "" {
+			titled = append(titled, emp)
+		}
+	}
+
+	share := func(count int) string {
+		return fmt.Sprintf("%.1f%%", float64(count)*100/float64(len(employees)))
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Workforce summary of %d employees:\n\n", len(employees)))
+	writeMarkdownTable(&result, []string{"Status", "Count", "Share"}, [][]string{
+		{"Total", strconv.Itoa(len(employees)), share(len(employees))},
+		{"Active", strconv.Itoa(active), share(active)},
+		{"Deactivated", strconv.Itoa(deactivated), share(deactivated)},
+	}, []int{0, 1, 2})
+
+	titles := countByTitle(titled)
+	if len(titles) == 0 {
+		result.WriteString("\nNo employee has a title.\n")
+		return result.String()
+	}
+
+	if len(titles) > summaryTopTitles {
+		titles = titles[:summaryTopTitles]
+	}
+	rows := make([][]string, 0, len(titles))
+	for _, title := range titles {
+		rows = append(rows, []string{title.Title, strconv.Itoa(title.Count)})
+	}
+	result.WriteString(fmt.Sprintf("\nTop %d titles:\n\n", len(titles)))
+	writeMarkdownTable(&result, []string{"Title", "Count"}, rows, []int{0, 1})
+
+	return result.String()
+}
@@ -0,0 +1,95 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestSummary(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Software Engineer"},
+		{FirstName: "Max", LastName: "Poe", Title: "Software Engineer", Deactivated: true},
+		{FirstName: "Ann", LastName: "Lee", Title: "Product Manager"},
+		{FirstName: "Sam", LastName: "Orr", Title: "Product Manager", Deactivated: true},
+		{FirstName: "Liz", LastName: "Ode", Title: "Designer"},
+		{FirstName: "Joe", LastName: "Tan", Title: "Recruiter"},
+		{FirstName: "Bob", LastName: "Ray", Title: "Accountant"},
+		{FirstName: "Eve", LastName: "Kim", Title: "Analyst"},
+		{FirstName: "Tom", LastName: "Fox"},
+		{FirstName: "Kim", LastName: "Wu", Title: "  "},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery(WithLogOutput(nil))
+
+	// The status keyword does not filter the summary, computed over all the employees
+	output, err := q.ProcessQuery(data, "Summarize the active workforce")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Workforce summary of 11 employees:",
+		"| Total | 11 | 100.0% |",
+		"| Active | 9 | 81.8% |",
+		"| Deactivated | 2 | 18.2% |",
+		"Top 5 titles:",
+		"| Software Engineer | 3 |",
+		"| Product Manager | 2 |",
+		"| Accountant | 1 |",
+		"| Analyst | 1 |",
+		"| Designer | 1 |",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+
+	// Ties are broken alphabetically, empty titles are left out
+	for _, unexpected := range []string{"Recruiter", noTitleGroup, "|  |"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Unexpected %q in output:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestSummaryPhrasing(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"summarize the workforce", true},
+		{"summarise the active workforce", true},
+		{"show the workforce summary", true},
+		{"headcount stats", true},
+		{"give me an overview of the whole workforce", true},
+		{"statistics of the headcount", true},
+		{"summary of deactivated engineers as csv", false},
+		{"audit report summary for paris", false},
+		{"overview of the sales team", false},
+		{"stats for the engineers", false},
+	}
+
+	for _, tt := range tests {
+		if got := isSummaryQuery(tt.query); got != tt.expected {
+			t.Errorf("%q: expected %v, got %v", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestSummaryFiltersTakePrecedence(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Software Engineer"},
+	}
+
+	output, err := NewJSONQuery(WithLogOutput(nil)).ProcessQuery(mustMarshal(t, employees), "Summary of deactivated engineers as csv")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if strings.Contains(output, "Workforce summary") || !strings.Contains(output, "John,Doe") || strings.Contains(output, "Jane") {
+		t.Errorf("Expected the deactivated engineers as CSV, got:\n%s", output)
+	}
+}
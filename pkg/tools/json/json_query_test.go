@@ -0,0 +1,1117 @@
+package json
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// boolPtr returns a pointer to the given bool
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// mustMarshal marshals the employees for use as ProcessQuery input
+func mustMarshal(t *testing.T, employees []model.EmployeeInfo) []byte {
+	t.Helper()
+	data, err := json.Marshal(employees)
+	if err != nil {
+		t.Fatalf("Error marshalling employees: %v", err)
+	}
+	return data
+}
+
+func TestTwoFactorFilter(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Alice", LastName: "Enabled", Has2FA: boolPtr(true)},
+		{FirstName: "Bob", LastName: "Disabled", Has2FA: boolPtr(false)},
+		{FirstName: "Carol", LastName: "Unknown"},
+	}
+
+	enabled, unknown := filterByTwoFactor(employees, true)
+	if len(enabled) != 1 || enabled[0].FirstName != "Alice" || unknown != 1 {
+		t.Errorf("Expected only Alice with 1 unknown, got %v with %d unknown", enabled, unknown)
+	}
+
+	disabled, unknown := filterByTwoFactor(employees, false)
+	if len(disabled) != 1 || disabled[0].FirstName != "Bob" || unknown != 1 {
+		t.Errorf("Expected only Bob with 1 unknown, got %v with %d unknown", disabled, unknown)
+	}
+
+	q := NewJSONQuery()
+	output, err := q.ProcessQuery(mustMarshal(t, employees), "List employees without 2FA")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Bob Disabled") || strings.Contains(output, "Alice") || strings.Contains(output, "Carol") {
+		t.Errorf("Expected only Bob in output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "2FA status is unknown for 1 employees") {
+		t.Errorf("Expected unknown 2FA note in output, got:\n%s", output)
+	}
+
+	// No note when every status is known
+	output, err = q.ProcessQuery(mustMarshal(t, employees[:2]), "List employees with 2FA enabled")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Alice Enabled") || strings.Contains(output, "Bob") || strings.Contains(output, "unknown") {
+		t.Errorf("Expected only Alice without note, got:\n%s", output)
+	}
+}
+
+func TestSplitColumns(t *testing.T) {
+	widths := []int{10, 20, 30, 5, 40}
+
+	for _, maxWidth := range []int{10, 30, 50, 80, 200} {
+		groups := splitColumns(widths, maxWidth)
+
+		// The first column starts every group, every other column must appear exactly once, in order
+		next := 1
+		for _, group := range groups {
+			if len(group) < 2 || group[0] != 0 {
+				t.Fatalf("maxWidth=%d: expected column 0 followed by other columns, got %v", maxWidth, groups)
+			}
+
+			groupWidth := 1 + widths[0] + 3
+			for _, col := range group[1:] {
+				if col != next {
+					t.Fatalf("maxWidth=%d: expected column %d, got %d in %v", maxWidth, next, col, groups)
+				}
+				next++
+				groupWidth += widths[col] + 3
+			}
+
+			if len(group) > 2 && groupWidth > maxWidth {
+				t.Errorf("maxWidth=%d: group %v is %d wide", maxWidth, group, groupWidth)
+			}
+		}
+		if next != len(widths) {
+			t.Errorf("maxWidth=%d: only %d of %d columns grouped in %v", maxWidth, next, len(widths), groups)
+		}
+	}
+
+	if groups := splitColumns(widths, 200); len(groups) != 1 {
+		t.Errorf("Expected a single group when everything fits, got %v", groups)
+	}
+}
+
+func TestDepartment(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Software Engineer", Department: "R&D"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+	}
+	q := NewJSONQuery()
+
+	output, err := q.FormatResults(employees)
+	if err != nil {
+		t.Fatalf("Error formatting results: %v", err)
+	}
+	if !strings.Contains(output, "1. John Doe - Software Engineer [R&D]\n") ||
+		!strings.Contains(output, "2. Jane Roe - Designer (Deactivated on 2023-03-15)\n") {
+		t.Errorf("Expected the department of John Doe only, got:\n%s", output)
+	}
+
+	output, err = q.FormatAsMarkdownTable(employees)
+	if err != nil {
+		t.Fatalf("Error formatting table: %v", err)
+	}
+	if !strings.Contains(output, "| Name | Title | Department | Email |") || !strings.Contains(output, "| Software Engineer | R&D |") {
+		t.Errorf("Expected a department column, got:\n%s", output)
+	}
+
+	// No department column when no employee has one
+	employees[0].Department = ""
+	output, err = q.FormatAsMarkdownTable(employees)
+	if err != nil {
+		t.Fatalf("Error formatting table: %v", err)
+	}
+	if strings.Contains(output, "Department |") {
+		t.Errorf("Expected no department column, got:\n%s", output)
+	}
+}
+
+func TestSlackID(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{SlackID: "U012ABC", FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer"}, // from an older data file
+	}
+	data := mustMarshal(t, employees)
+
+	// The ID survives the round-trip through the query
+	output, err := NewJSONQuery().ProcessQuery(data, "List all employees as json")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	var result []model.EmployeeInfo
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Error unmarshalling output: %v\n%s", err, output)
+	}
+	if len(result) != 2 || result[0].SlackID != "U012ABC" || result[1].SlackID != "" {
+		t.Errorf("Expected the Slack ID of John Doe only, got %+v", result)
+	}
+
+	// The IDs are only listed on demand
+	output, err = NewJSONQuery().ProcessQuery(data, "List all employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if strings.Contains(output, "U012ABC") {
+		t.Errorf("Expected no Slack ID, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery().ProcessQuery(data, "List all employees with their slack id")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "1. John Doe - Software Engineer - Slack ID: U012ABC\n") ||
+		!strings.Contains(output, "2. Jane Roe - Designer\n") {
+		t.Errorf("Expected the Slack ID of John Doe, got:\n%s", output)
+	}
+}
+
+func TestBotMarker(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Deploy", LastName: "Bot", IsBot: true},
+	}
+
+	output, err := NewJSONQuery().ProcessQuery(mustMarshal(t, employees), "List all employees including bots")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "1. John Doe - Software Engineer\n") || !strings.Contains(output, "2. Deploy Bot (bot)\n") {
+		t.Errorf("Expected the bot to be marked, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery().ProcessQuery(mustMarshal(t, employees), "Find Deploy Bot")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.HasPrefix(output, "Employee: Deploy Bot (bot)\n") {
+		t.Errorf("Expected the bot to be marked, got:\n%s", output)
+	}
+}
+
+func TestFormatAsMarkdownTableSplit(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Principal Software Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+	}
+
+	output, err := NewJSONQuery().FormatAsMarkdownTable(employees)
+	if err != nil {
+		t.Fatalf("Error formatting table: %v", err)
+	}
+	if strings.Contains(output, "Part") {
+		t.Errorf("Expected a single table without width limit, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery(WithMaxTableWidth(60)).FormatAsMarkdownTable(employees)
+	if err != nil {
+		t.Fatalf("Error formatting table: %v", err)
+	}
+	if !strings.Contains(output, "**Part 1 of") {
+		t.Fatalf("Expected split tables, got:\n%s", output)
+	}
+
+	// The name starts each split table, while every other header and value appears exactly once
+	parts := strings.Count(output, "**Part ")
+	for _, block := range strings.Split(output, "**Part ")[1:] {
+		if !strings.Contains(block, "\n| Name |") || !strings.Contains(block, "| John Doe |") {
+			t.Errorf("Expected the name first in each table, got:\n%s", block)
+		}
+	}
+	for _, value := range []string{"| Name ", "John Doe"} {
+		if count := strings.Count(output, value); count != parts {
+			t.Errorf("Expected %q %d times, found %d times in:\n%s", value, parts, count, output)
+		}
+	}
+	for _, value := range []string{"| Title ", "| Email ", "| Status ", "| Deactivation Date ",
+		"Principal Software Engineer", "john.doe@example.com", "Deactivated |", "2023-03-15"} {
+		if count := strings.Count(output, value); count != 1 {
+			t.Errorf("Expected %q once, found %d times in:\n%s", value, count, output)
+		}
+	}
+}
+
+func TestGroupByEmail(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+		{FirstName: "Johnny", LastName: "Doe", Email: " John.Doe@Example.com ", Deactivated: true},
+		{FirstName: "Jane", LastName: "Doe", Email: "jane.doe@example.com"},
+		{FirstName: "Shared", LastName: "Inbox", Email: "team@example.com"},
+		{FirstName: "Shared", LastName: "Inbox 2", Email: "team@example.com"},
+		{FirstName: "Shared", LastName: "Inbox 3", Email: "TEAM@example.com"},
+		{FirstName: "No", LastName: "Email"},
+		{FirstName: "Also No", LastName: "Email"},
+	}
+
+	duplicates, uniqueCount := groupByEmail(employees)
+
+	if uniqueCount != 3 {
+		t.Errorf("Expected 3 unique emails, got %d", uniqueCount)
+	}
+	if len(duplicates) != 2 {
+		t.Fatalf("Expected 2 duplicate clusters, got %v", duplicates)
+	}
+	if duplicates[0].Email != "john.doe@example.com" || len(duplicates[0].Employees) != 2 {
+		t.Errorf("Unexpected first cluster: %v", duplicates[0])
+	}
+	if duplicates[1].Email != "team@example.com" || len(duplicates[1].Employees) != 3 {
+		t.Errorf("Unexpected second cluster: %v", duplicates[1])
+	}
+
+	q := NewJSONQuery()
+	data := mustMarshal(t, employees)
+
+	output, err := q.ProcessQuery(data, "Find duplicate emails")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 2 emails shared by multiple accounts") ||
+		!strings.Contains(output, "team@example.com (3 accounts)") || strings.Contains(output, "jane.doe") {
+		t.Errorf("Unexpected duplicate emails output:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "How many unique emails are there?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 3 unique emails across 8 employees") {
+		t.Errorf("Unexpected unique emails output:\n%s", output)
+	}
+}
+
+// namesInOrder returns the given names ordered by their first position in output
+func namesInOrder(t *testing.T, output string, names []string) []string {
+	t.Helper()
+	ordered := append([]string(nil), names...)
+	for _, name := range ordered {
+		if !strings.Contains(output, name) {
+			t.Fatalf("Expected %q in output:\n%s", name, output)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return strings.Index(output, ordered[i]) < strings.Index(output, ordered[j])
+	})
+	return ordered
+}
+
+func TestOutputOrderAcrossFormats(t *testing.T) {
+	// Many employees share the same deactivation date so that ties must keep the dataset order
+	// (an unstable sort only shuffles ties beyond a dozen elements)
+	var employees []model.EmployeeInfo
+	var names []string
+	for i := 0; i < 30; i++ {
+		date := "2023-05-01"
+		if i%3 == 0 {
+			date = fmt.Sprintf("2022-01-%02d", i/3+1)
+		}
+		employees = append(employees, model.EmployeeInfo{
+			FirstName: fmt.Sprintf("Employee%02d", i), LastName: "Doe", Deactivated: true, DeactivatedDate: date,
+		})
+		names = append(names, fmt.Sprintf("Employee%02d Doe", i))
+	}
+
+	// Expected order: tied 2023 dates in dataset order, then 2022 dates most recent first
+	var expected []string
+	for i := 0; i < 30; i++ {
+		if i%3 != 0 {
+			expected = append(expected, names[i])
+		}
+	}
+	for i := 27; i >= 0; i -= 3 {
+		expected = append(expected, names[i])
+	}
+
+	q := NewJSONQuery()
+	data := mustMarshal(t, employees)
+
+	// Names of the employees in output order, parsed from the structured formats
+	fullNames := func(employees []model.EmployeeInfo) []string {
+		var order []string
+		for _, emp := range employees {
+			order = append(order, emp.FirstName+" "+emp.LastName)
+		}
+		return order
+	}
+	parseJSON := func(t *testing.T, output string) []string {
+		var parsed []model.EmployeeInfo
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		return fullNames(parsed)
+	}
+	parseNDJSON := func(t *testing.T, output string) []string {
+		var parsed []model.EmployeeInfo
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			var emp model.EmployeeInfo
+			if err := json.Unmarshal([]byte(line), &emp); err != nil {
+				t.Fatalf("Invalid NDJSON line %q: %v", line, err)
+			}
+			parsed = append(parsed, emp)
+		}
+		return fullNames(parsed)
+	}
+	parseCSV := func(t *testing.T, output string) []string {
+		records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+		if err != nil {
+			t.Fatalf("Invalid CSV output: %v", err)
+		}
+		var order []string
+		for _, record := range records[1:] {
+			order = append(order, record[0]+" "+record[1]) // First and last names are separate columns
+		}
+		return order
+	}
+	parseYAML := func(t *testing.T, output string) []string {
+		var parsed []model.EmployeeInfo
+		if err := yaml.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("Invalid YAML output: %v", err)
+		}
+		return fullNames(parsed)
+	}
+	searchNames := func(t *testing.T, output string) []string {
+		return namesInOrder(t, output, names)
+	}
+
+	// One query per output format
+	queries := map[string]struct {
+		query string
+		parse func(t *testing.T, output string) []string
+	}{
+		"list":   {"Show the last 30 deactivated employees", searchNames},
+		"table":  {"Show the last 30 deactivated employees as a table", searchNames},
+		"html":   {"Show the last 30 deactivated employees as html", searchNames},
+		"json":   {"Show the last 30 deactivated employees as json", parseJSON},
+		"ndjson": {"Show the last 30 deactivated employees as ndjson", parseNDJSON},
+		"csv":    {"Show the last 30 deactivated employees as csv", parseCSV},
+		"yaml":   {"Show the last 30 deactivated employees as yaml", parseYAML},
+	}
+
+	for format, tt := range queries {
+		// Run each query several times to catch any nondeterminism
+		for run := 0; run < 5; run++ {
+			output, err := q.ProcessQuery(data, tt.query)
+			if err != nil {
+				t.Fatalf("%s: error processing query: %v", format, err)
+			}
+
+			order := tt.parse(t, output)
+			if strings.Join(order, ",") != strings.Join(expected, ",") {
+				t.Errorf("%s: expected order %v, got %v", format, expected, order)
+			}
+		}
+	}
+}
+
+func TestSortByNameCollation(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Olof", LastName: "Zetterberg"},
+		{FirstName: "Anna", LastName: "Åberg"},
+		{FirstName: "Erik", LastName: "Andersson"},
+		{FirstName: "Jan", LastName: "Öberg"},
+		{FirstName: "Lena", LastName: "Olsson"},
+	}
+
+	lastNames := func(employees []model.EmployeeInfo) string {
+		var names []string
+		for _, emp := range employees {
+			names = append(names, emp.LastName)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name     string
+		query    *JSONQuery
+		expected string
+	}{
+		{"root", NewJSONQuery(), "Åberg,Andersson,Öberg,Olsson,Zetterberg"},
+		{"german", NewJSONQuery(WithCollationLocale(language.German)), "Åberg,Andersson,Öberg,Olsson,Zetterberg"},
+		// Swedish sorts å and ö after z
+		{"swedish", NewJSONQuery(WithCollationLocale(language.Swedish)), "Andersson,Olsson,Zetterberg,Åberg,Öberg"},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]model.EmployeeInfo(nil), employees...)
+		tt.query.sortByName(sorted)
+		if got := lastNames(sorted); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestDropScrubbed(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Active", LastName: "NoEmail"},
+		{FirstName: "Active", LastName: "WithEmail", Email: "active@example.com"},
+		{FirstName: "Former", LastName: "NoEmail", Deactivated: true, DeactivatedDate: "2023-01-01"},
+		{FirstName: "Former", LastName: "BlankEmail", Email: "  ", Deactivated: true},
+		{FirstName: "Former", LastName: "WithEmail", Email: "former@example.com", Deactivated: true},
+	}
+
+	kept, dropped := dropScrubbedEmployees(employees)
+	if dropped != 2 || len(kept) != 3 {
+		t.Fatalf("Expected 2 dropped and 3 kept, got %d dropped and %v kept", dropped, kept)
+	}
+	for _, emp := range kept {
+		if emp.Deactivated && strings.TrimSpace(emp.Email) == "" {
+			t.Errorf("Scrubbed employee kept: %v", emp)
+		}
+	}
+
+	data := mustMarshal(t, employees)
+
+	// Default behavior keeps every row
+	output, err := NewJSONQuery().ProcessQuery(data, "List all employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 5 employees") || strings.Contains(output, "dropped") {
+		t.Errorf("Expected all employees without drop note, got:\n%s", output)
+	}
+
+	output, err = NewJSONQuery(WithDropScrubbed(true)).ProcessQuery(data, "List all employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "2 deactivated employees without email were dropped") ||
+		!strings.Contains(output, "Found 3 employees") || strings.Contains(output, "Former NoEmail") {
+		t.Errorf("Expected scrubbed employees to be dropped, got:\n%s", output)
+	}
+}
+
+func TestFindByInitials(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe"},
+		{FirstName: "Jane", LastName: "Davis", Deactivated: true},
+		{FirstName: "Dave", LastName: "Johnson"},
+		{FirstName: "jake", LastName: "dunn"},
+		{FirstName: "Mary", LastName: "Smith"},
+	}
+
+	for _, query := range []string{"find j.d.", "who is j. d.?", "employees with initials jd", "initials j.d"} {
+		initials, ok := parseInitials(query)
+		if !ok || initials != "jd" {
+			t.Errorf("%q: expected initials jd, got %q (%v)", query, initials, ok)
+		}
+	}
+	for _, query := range []string{"find john doe", "list all employees", "email j.doe@example.com"} {
+		if initials, ok := parseInitials(query); ok {
+			t.Errorf("%q: unexpected initials %q", query, initials)
+		}
+	}
+
+	matches := findByInitials(employees, "JD")
+	if len(matches) != 3 {
+		t.Fatalf("Expected 3 matches, got %v", matches)
+	}
+
+	output, err := NewJSONQuery().ProcessQuery(mustMarshal(t, employees), "Find J.D.")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	for _, name := range []string{"John Doe", "Jane Davis", "jake dunn"} {
+		if !strings.Contains(output, name) {
+			t.Errorf("Expected %q in output:\n%s", name, output)
+		}
+	}
+	if strings.Contains(output, "Dave Johnson") || strings.Contains(output, "Mary Smith") {
+		t.Errorf("Unexpected match in output:\n%s", output)
+	}
+
+	// Initials search composes with the status filter
+	output, err = NewJSONQuery().ProcessQuery(mustMarshal(t, employees), "Deactivated employees with initials JD")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Jane Davis") || strings.Contains(output, "John Doe") {
+		t.Errorf("Expected only Jane Davis, got:\n%s", output)
+	}
+}
+
+func TestSeniorityFilter(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "Junior", LastName: "Engineer", Title: "Junior Software Engineer"},
+		{FirstName: "Senior", LastName: "Engineer", Title: "Sr. Software Engineer"},
+		{FirstName: "Staff", LastName: "Engineer", Title: "Staff Engineer"},
+		{FirstName: "Principal", LastName: "Engineer", Title: "Principal Engineer"},
+		{FirstName: "Staff", LastName: "Designer", Title: "Staff Product Designer"},
+		{FirstName: "Senior", LastName: "Manager", Title: "Senior Marketing Manager"},
+		{FirstName: "No", LastName: "Level", Title: "Software Engineer"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	output, err := q.ProcessQuery(data, "List staff+ engineers")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 2 employees") ||
+		!strings.Contains(output, "Staff Engineer") || !strings.Contains(output, "Principal Engineer") {
+		t.Errorf("Expected staff and principal engineers, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Show senior employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 2 employees") ||
+		!strings.Contains(output, "Sr. Software Engineer") || !strings.Contains(output, "Senior Marketing Manager") {
+		t.Errorf("Expected senior employees, got:\n%s", output)
+	}
+
+	// "staff" alone is not a seniority query
+	if _, ok := q.parseSeniorityFilter("list all staff"); ok {
+		t.Error("Expected no seniority filter for \"list all staff\"")
+	}
+
+	// Custom level ordering
+	custom := NewJSONQuery(WithSeniorityLevels([]string{"Junior", "Senior", "Principal", "Staff"}))
+	output, err = custom.ProcessQuery(data, "List principal+ engineers")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 2 employees") ||
+		!strings.Contains(output, "Staff Engineer") || !strings.Contains(output, "Principal Engineer") {
+		t.Errorf("Expected principal and staff engineers with custom levels, got:\n%s", output)
+	}
+}
+
+func TestDeactivatedOnSameDate(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2023-03-16"},
+		{FirstName: "Bob", LastName: "NoDate", Deactivated: true},
+		{FirstName: "Eve", LastName: "Active"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	output, err := q.ProcessQuery(data, "Employees deactivated on 2023-03-15")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 3 employees") || strings.Contains(output, "Ann Lee") {
+		t.Errorf("Expected the 3 employees deactivated on 2023-03-15, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Find employees deactivated on the same day as John Doe")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "the same day as John Doe") || !strings.Contains(output, "Found 2 employees") ||
+		!strings.Contains(output, "Jane Roe") || !strings.Contains(output, "Max Poe") || strings.Contains(output, "2. John Doe") {
+		t.Errorf("Expected Jane Roe and Max Poe, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Who was deactivated on the same day as Bob NoDate?")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Bob NoDate has no deactivation date") {
+		t.Errorf("Expected no date message, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Deactivated on the same day as Nobody Here")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "not found") {
+		t.Errorf("Expected not found message, got:\n%s", output)
+	}
+}
+
+func TestRegisterFilter(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@contractors.example.com", Title: "Senior Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@contractors.example.com", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Email: "max.poe@contractors.example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@example.com", Title: "Senior Engineer"},
+	}
+	data := mustMarshal(t, employees)
+
+	isContractor := func(emp model.EmployeeInfo) bool {
+		return strings.HasSuffix(emp.Email, "@contractors.example.com")
+	}
+	q := NewJSONQuery()
+	if err := q.RegisterFilter("Contractor", isContractor); err != nil {
+		t.Fatalf("Error registering filter: %v", err)
+	}
+
+	output, err := q.ProcessQuery(data, "Show active contractors")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 2 employees") || strings.Contains(output, "Ann Lee") || strings.Contains(output, "Max Poe") {
+		t.Errorf("Expected the 2 active contractors, got:\n%s", output)
+	}
+
+	// Custom filters are combined with built-in filters using AND
+	output, err = q.ProcessQuery(data, "List senior contractor employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 1 employees") || !strings.Contains(output, "John Doe") {
+		t.Errorf("Expected only John Doe, got:\n%s", output)
+	}
+
+	// Queries without the keyword are not filtered
+	output, err = q.ProcessQuery(data, "Show active employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Found 3 employees") {
+		t.Errorf("Expected 3 active employees, got:\n%s", output)
+	}
+
+	// Blank keywords would match every query
+	for _, keyword := range []string{"", "   "} {
+		if err := q.RegisterFilter(keyword, isContractor); !errors.Is(err, ErrEmptyFilterKeyword) {
+			t.Errorf("%q: expected ErrEmptyFilterKeyword, got %v", keyword, err)
+		}
+	}
+
+	// Same filter through the option of the tool (e.g. given to the agent), blank keywords being ignored
+	tool := NewJSONQueryTool(WithFilter("contractor", isContractor), WithFilter(" ", isContractor), WithLogOutput(nil))
+	output, err = tool.Call(context.Background(), fmt.Sprintf(`{"data": %s, "query": "Show active contractors"}`, data))
+	if err != nil {
+		t.Fatalf("Error calling tool: %v", err)
+	}
+	if !strings.Contains(output, "Found 2 employees") {
+		t.Errorf("Expected the 2 active contractors, got:\n%s", output)
+	}
+}
+
+func TestLastResultCount(t *testing.T) {
+	q := NewJSONQuery()
+	if _, known := q.LastResultCount(); known {
+		t.Errorf("Expected unknown count before any query")
+	}
+
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Roe"},
+		{FirstName: "Ann", LastName: "Lee"},
+	})
+
+	tests := []struct {
+		query    string
+		expected int
+		known    bool
+	}{
+		{"Show active employees", 2, true},
+		{"Show active employees as a table", 2, true},
+		{"Find employee John Doe", 1, true},
+		{"How many unique emails are there?", 0, false},
+	}
+
+	for _, tt := range tests {
+		if _, err := q.ProcessQuery(data, tt.query); err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		count, known := q.LastResultCount()
+		if known != tt.known || (known && count != tt.expected) {
+			t.Errorf("Query %q: expected count %d (known=%t), got %d (known=%t)", tt.query, tt.expected, tt.known, count, known)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Email: "john.smith@corp.com", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@corp.com", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@corp.com", Title: "Engineering Manager"},
+	}
+	original := slices.Clone(employees)
+	data := mustMarshal(t, employees)
+
+	// Same results as with the JSON data, without reading nor decoding any
+	for _, query := range []string{
+		"Show all employees sorted by last name",
+		"List deactivated employees as a table",
+		"Find Jane Roe",
+		"How many active employees have the role engineer?",
+	} {
+		q := NewJSONQuery()
+		output, err := q.Query(employees, query)
+		if err != nil {
+			t.Fatalf("Error querying %q: %v", query, err)
+		}
+		count, _ := q.LastResultCount()
+
+		expected, err := q.ProcessQuery(data, query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", query, err)
+		}
+		if expectedCount, _ := q.LastResultCount(); output != expected || count != expectedCount {
+			t.Errorf("Query %q: expected %q (count %d), got %q (count %d)", query, expected, expectedCount, output, count)
+		}
+	}
+
+	// The employees of the caller are left untouched by the sorting
+	if !reflect.DeepEqual(employees, original) {
+		t.Errorf("Expected the employees to be unchanged, got %v", employees)
+	}
+
+	q := NewJSONQuery()
+	output, err := q.Query(nil, "Show all employees")
+	if err != nil || output != NoEmployeesMessage {
+		t.Errorf("Expected the no employees message, got %q, %v", output, err)
+	}
+	if count, known := q.LastResultCount(); !known || count != 0 {
+		t.Errorf("Expected a result count of 0, got %d (known=%t)", count, known)
+	}
+}
+
+func TestFindByNameAndEmail(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@corp.com", Title: "Software Engineer"},
+		{FirstName: "John", LastName: "Doe", Email: "jdoe@corp.com", Title: "Sales Lead", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Doe", Email: "jane.doe@corp.com", Title: "Designer"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected string
+		excluded string
+	}{
+		{"Find John Doe john.doe@corp.com", "Title: Software Engineer", "Sales Lead"},
+		{"find john doe with email JDOE@corp.com", "Title: Sales Lead", "Software Engineer"},
+		{"Who is jane.doe@corp.com?", "Employee: Jane Doe", "John"},
+		{"Find Jane Doe john.doe@corp.com", `No employee named "jane doe" found with email john.doe@corp.com.`, "Employee:"},
+		{"Find John Doe johnny@corp.com", "No employee found with email johnny@corp.com.", "Employee:"},
+		// Email-only lookups, without any name given
+		{"Details about JDOE@corp.com", "Title: Sales Lead", "Software Engineer"},
+		{"Who is johnny@corp.com?", "No employee found with email johnny@corp.com.", "named"},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if !strings.Contains(output, tt.expected) || strings.Contains(output, tt.excluded) {
+			t.Errorf("Query %q: expected %q without %q, got:\n%s", tt.query, tt.expected, tt.excluded, output)
+		}
+	}
+}
+
+func TestFindSpecificEmployee(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Smith", Email: "john.smith@corp.com", Title: "Software Engineer"},
+		{FirstName: "John", LastName: "Smith", Email: "jsmith@corp.com", Title: "Sales Lead", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@corp.com"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@corp.com"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	// Every employee sharing the name is returned
+	output, err := q.ProcessQuery(data, "Find John Smith")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, `Found 2 employees matching "john smith"`) ||
+		!strings.Contains(output, "1. Employee: John Smith") || !strings.Contains(output, "2. Employee: John Smith") ||
+		!strings.Contains(output, "Software Engineer") || !strings.Contains(output, "Sales Lead") || strings.Contains(output, "John Doe") {
+		t.Errorf("Expected both John Smiths, got:\n%s", output)
+	}
+	if count, _ := q.LastResultCount(); count != 2 {
+		t.Errorf("Expected a result count of 2, got %d", count)
+	}
+
+	// A single match is returned on its own
+	output, err = q.ProcessQuery(data, "Find Jane Roe")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.HasPrefix(output, "Employee: Jane Roe\n") {
+		t.Errorf("Expected Jane Roe alone, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Find Bob Ray")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if output != "Employee not found in the dataset." {
+		t.Errorf("Expected not found message, got:\n%s", output)
+	}
+}
+
+func TestCountQuery(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: "Designer"},
+		{FirstName: "Max", LastName: "Poe", Title: "Data Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Ann", LastName: "Lee", Deactivated: true, DeactivatedDate: "2022-11-02"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected string
+		count    int
+	}{
+		{"How many employees are active?", "2 active employees.", 2},
+		{"How many deactivated employees?", "2 deactivated employees.", 2},
+		{"Count all employees", "4 employees.", 4},
+		{"What is the number of employees deactivated in 2023?", "1 deactivated employee deactivated in 2023.", 1},
+		{"How many active employees have the role engineer?", `1 active employee with role "engineer".`, 1},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		if output != tt.expected {
+			t.Errorf("Query %q: expected %q, got:\n%s", tt.query, tt.expected, output)
+		}
+		if count, _ := q.LastResultCount(); count != tt.count {
+			t.Errorf("Query %q: expected a result count of %d, got %d", tt.query, tt.count, count)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	var employees []model.EmployeeInfo
+	for i := 1; i <= 50; i++ {
+		employees = append(employees, model.EmployeeInfo{FirstName: fmt.Sprintf("Employee%02d", i), LastName: "Doe"})
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query string
+		first int // first employee number returned, 0 for none
+		count int
+	}{
+		{"Skip 20 employees", 21, 30},
+		{"Show employees from 45", 45, 6},
+		{"Skip 20 top 20", 21, 20},
+		{"Skip 40, top 20", 41, 10},
+		{"Show 21-40", 21, 20},
+		{"Show results 11 to 15", 11, 5},
+		{"Skip 10 take 10", 11, 10},
+		{"Show employees with offset 45", 46, 5},
+		{"Show employees with offset 10 take 5", 11, 5},
+		{"Skip 60 employees", 0, 0},
+		{"Offset 50 take 10", 0, 0},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+
+		if tt.count == 0 {
+			if !strings.Contains(output, "no more results, the offset") || !strings.Contains(output, "beyond the 50 matching employees") || !strings.Contains(output, NoResultsMessage) {
+				t.Errorf("Query %q: expected an empty result with a note, got:\n%s", tt.query, output)
+			}
+			continue
+		}
+
+		expectedFirst := fmt.Sprintf("1. Employee%02d Doe", tt.first)
+		if !strings.Contains(output, fmt.Sprintf("Found %d employees", tt.count)) || !strings.Contains(output, expectedFirst) {
+			t.Errorf("Query %q: expected %d employees starting with %q, got:\n%s", tt.query, tt.count, expectedFirst, output)
+		}
+	}
+}
+
+func TestFormatAsCSV(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer, Platform", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "O'Roe", Email: "jane.roe@example.com", Title: `Head of "Growth"`, Deactivated: true},
+		{FirstName: "Ann", LastName: "Lee", Email: "ann.lee@example.com", Title: "Designer"},
+	}
+
+	output, err := NewJSONQuery().ProcessQuery(mustMarshal(t, employees), "List all employees as CSV")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	expected := "First Name,Last Name,Email,Title,Status,Deactivation Date\n" +
+		"John,Doe,john.doe@example.com,\"Engineer, Platform\",Deactivated,2023-03-15\n" +
+		"Jane,O'Roe,jane.roe@example.com,\"Head of \"\"Growth\"\"\",Deactivated,\n" +
+		"Ann,Lee,ann.lee@example.com,Designer,Active,\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+
+	// The output can be read back as CSV
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("Error reading CSV output: %v", err)
+	}
+	if len(records) != 4 || records[1][3] != "Engineer, Platform" || records[2][3] != `Head of "Growth"` || records[2][5] != "" {
+		t.Errorf("Unexpected records: %q", records)
+	}
+}
+
+func TestCSVExport(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"}})
+	csvPath := filepath.Join(t.TempDir(), "export.csv")
+
+	output, err := NewJSONQuery(WithCSVExport(csvPath), WithLogOutput(nil)).ProcessQuery(data, "List all employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Exported 1 employees to CSV file: "+csvPath) {
+		t.Errorf("Expected the export note, got:\n%s", output)
+	}
+
+	// The exported file holds employee data, readable by its owner only
+	content, err := os.ReadFile(csvPath)
+	if err != nil || !strings.Contains(string(content), "John,Doe,john.doe@example.com") {
+		t.Errorf("Expected John Doe in the exported file, got %q, %v", content, err)
+	}
+	if info, err := os.Stat(csvPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the CSV file to be written with permissions 0600, got %v, %v", info, err)
+	}
+}
+
+func TestDefaultFormat(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+	})
+
+	tests := []struct {
+		format string
+		query  string
+		prefix string
+	}{
+		{"csv", "List all employees", "First Name,Last Name,"},
+		{"json", "List all employees", "["},
+		{"json", "List all employees as CSV", "First Name,Last Name,"}, // the format asked in the query wins
+		{"csv", "List all employees as a table", "| "},
+		{"xml", "List all employees", "Found 1 employees"},
+		{"", "List all employees", "Found 1 employees"},
+	}
+
+	for _, tt := range tests {
+		output, err := NewJSONQuery(WithDefaultFormat(tt.format)).ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("%s, %q: error processing query: %v", tt.format, tt.query, err)
+		}
+		if !strings.HasPrefix(output, tt.prefix) {
+			t.Errorf("%s, %q: expected an output starting with %q, got:\n%s", tt.format, tt.query, tt.prefix, output)
+		}
+	}
+}
+
+func TestTitlePresence(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Software Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Title: ""},
+		{FirstName: "Max", LastName: "Poe", Title: "   "},
+		{FirstName: "Ann", LastName: "Lee", Title: "Designer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Bob", LastName: "Ray", Deactivated: true, DeactivatedDate: "2023-04-01"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected []string
+		excluded []string
+		untitled int
+	}{
+		{"Show employees with no title", []string{"Jane Roe", "Max Poe", "Bob Ray"}, []string{"John Doe", "Ann Lee"}, 3},
+		{"List untitled employees", []string{"Jane Roe", "Max Poe", "Bob Ray"}, []string{"John Doe", "Ann Lee"}, 3},
+		{"Show employees with a title", []string{"John Doe", "Ann Lee"}, []string{"Jane Roe", "Max Poe", "Bob Ray"}, 3},
+		// Composes with the status filters
+		{"Show active employees without a title", []string{"Jane Roe", "Max Poe"}, []string{"John Doe", "Ann Lee", "Bob Ray"}, 2},
+		{"Show deactivated employees with a title", []string{"Ann Lee"}, []string{"John Doe", "Jane Roe", "Max Poe", "Bob Ray"}, 1},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		for _, name := range tt.expected {
+			if !strings.Contains(output, name) {
+				t.Errorf("Query %q: expected %s in output:\n%s", tt.query, name, output)
+			}
+		}
+		for _, name := range tt.excluded {
+			if strings.Contains(output, name) {
+				t.Errorf("Query %q: unexpected %s in output:\n%s", tt.query, name, output)
+			}
+		}
+		if note := fmt.Sprintf("Note: %d employees have no title.", tt.untitled); !strings.Contains(output, note) {
+			t.Errorf("Query %q: expected note %q in output:\n%s", tt.query, note, output)
+		}
+	}
+}
+
+func TestMalformedInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+		err      error
+	}{
+		{"empty file", "", "Error: file is empty", errEmptyInput},
+		{"blank file", " \n ", "Error: file is empty", errEmptyInput},
+		{"single object", `{}`, "Error: input file is not a JSON array of employees", errNotEmployeesArray},
+		{"array of strings", `["John Doe"]`, "Error: input file is not a JSON array of employees: item 1 is not an object", errNotEmployeesArray},
+		{"truncated JSON", `[{"first_name": "John", "last_na`, "Error: input file is not a JSON array of employees", errNotEmployeesArray},
+		{"empty array", `[]`, NoEmployeesMessage, nil},
+		{"valid data", `[{"first_name": "John", "last_name": "Doe"}]`, "1. John Doe", nil},
+	}
+
+	for _, tt := range tests {
+		q := NewJSONQuery()
+		output, err := q.ProcessQuery([]byte(tt.data), "List all employees")
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.err, err)
+		}
+		if !strings.Contains(output, tt.expected) {
+			t.Errorf("%s: expected %q in output, got:\n%s", tt.name, tt.expected, output)
+		}
+	}
+}
+
+func TestDataDirLookup(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "employees-all.json"), mustMarshal(t, []model.EmployeeInfo{{FirstName: "John", LastName: "Doe"}}), 0644); err != nil {
+		t.Fatalf("Error writing fixture file: %v", err)
+	}
+	input := `{"file_path": "employees-all.json", "query": "List all employees"}`
+
+	// The file name alone is not found from the working directory
+	if _, err := NewJSONQueryTool().Call(context.Background(), input); err == nil {
+		t.Error("Expected an error without a data directory")
+	}
+
+	output, err := NewJSONQueryTool(WithDataDir(dir)).Call(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Error calling tool: %v", err)
+	}
+	if !strings.Contains(output, "John Doe") {
+		t.Errorf("Expected John Doe in the output, got:\n%s", output)
+	}
+}
@@ -0,0 +1,67 @@
+package json
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timings holds the time spent in each stage of a query
+type Timings struct {
+	Filtering  time.Duration
+	Sorting    time.Duration
+	Limiting   time.Duration
+	Exporting  time.Duration
+	Formatting time.Duration
+}
+
+// Total returns the time spent in all the stages
+func (t Timings) Total() time.Duration {
+	return t.Filtering + t.Sorting + t.Limiting + t.Exporting + t.Formatting
+}
+
+// String describes the timings for logging
+func (t Timings) String() string {
+	return fmt.Sprintf("filtering=%s sorting=%s limiting=%s exporting=%s formatting=%s total=%s",
+		t.Filtering, t.Sorting, t.Limiting, t.Exporting, t.Formatting, t.Total())
+}
+
+// WithTimings records and logs the time spent in each stage of the queries (filtering, sorting, limiting, exporting, formatting)
+// When disabled, the clock is not read so that the overhead is negligible
+func WithTimings(enabled bool) Option {
+	return func(q *JSONQuery) {
+		q.timingsEnabled = enabled
+	}
+}
+
+// LastTimings returns the time spent in each stage of the last query listing employees
+// The timings are unknown (false) when disabled or when the last query did not go through all the stages
+func (q *JSONQuery) LastTimings() (Timings, bool) {
+	return q.lastTimings, q.lastTimingsKnown
+}
+
+// stageTimer measures the successive stages of a query
+type stageTimer struct {
+	enabled bool
+	start   time.Time
+}
+
+// newStageTimer starts measuring the first stage, if enabled
+func newStageTimer(enabled bool) *stageTimer {
+	timer := &stageTimer{enabled: enabled}
+	if enabled {
+		timer.start = time.Now()
+	}
+	return timer
+}
+
+// lap returns the duration of the current stage and starts measuring the next one
+// It always returns 0 when disabled
+func (s *stageTimer) lap() time.Duration {
+	if !s.enabled {
+		return 0
+	}
+	now := time.Now()
+	elapsed := now.Sub(s.start)
+	s.start = now
+	return elapsed
+}
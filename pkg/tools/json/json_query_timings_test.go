@@ -0,0 +1,103 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// generateEmployees generates a dataset of n employees, a third of them deactivated
+func generateEmployees(n int) []model.EmployeeInfo {
+	titles := []string{"Software Engineer", "Senior Software Engineer", "Marketing Manager", "Staff Designer", "Sales Lead"}
+	employees := make([]model.EmployeeInfo, n)
+	for i := range employees {
+		employees[i] = model.EmployeeInfo{
+			FirstName: fmt.Sprintf("First%05d", i),
+			LastName:  fmt.Sprintf("Last%05d", (i*7919)%n),
+			Email:     fmt.Sprintf("employee%05d@example.com", i),
+			Title:     titles[i%len(titles)],
+		}
+		if i%3 == 0 {
+			employees[i].Deactivated = true
+			employees[i].DeactivatedDate = fmt.Sprintf("20%02d-%02d-%02d", 15+i%10, 1+i%12, 1+i%28)
+		}
+	}
+	return employees
+}
+
+func TestLastTimings(t *testing.T) {
+	data := mustMarshal(t, generateEmployees(100))
+
+	q := NewJSONQuery()
+	if _, err := q.ProcessQuery(data, "Show the last 10 deactivated employees"); err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if _, known := q.LastTimings(); known {
+		t.Errorf("Expected unknown timings when disabled")
+	}
+
+	q = NewJSONQuery(WithTimings(true))
+	if _, err := q.ProcessQuery(data, "Show the last 10 deactivated employees"); err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	timings, known := q.LastTimings()
+	if !known {
+		t.Fatalf("Expected known timings when enabled")
+	}
+	if timings.Filtering <= 0 || timings.Total() < timings.Filtering {
+		t.Errorf("Unexpected timings: %s", timings)
+	}
+
+	// Queries that do not go through all the stages have no timings
+	if _, err := q.ProcessQuery(data, "Find employee First00001 Last00019"); err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if _, known := q.LastTimings(); known {
+		t.Errorf("Expected unknown timings for a specific employee search")
+	}
+}
+
+func BenchmarkProcessQuery(b *testing.B) {
+	data, err := json.Marshal(generateEmployees(20000))
+	if err != nil {
+		b.Fatalf("Error marshalling employees: %v", err)
+	}
+
+	queries := map[string]string{
+		"filter":     "Show the active employees",
+		"sort-limit": "Show the last 100 deactivated employees",
+		"multi-sort": "List employees sorted by title then by name",
+		"table":      "Show the last 100 deactivated employees as a table",
+	}
+
+	for name, query := range queries {
+		for _, timingsEnabled := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%s/timings=%t", name, timingsEnabled), func(b *testing.B) {
+				q := NewJSONQuery(WithTimings(timingsEnabled))
+				var total Timings
+
+				for b.Loop() {
+					if _, err := q.ProcessQuery(data, query); err != nil {
+						b.Fatalf("Error processing query: %v", err)
+					}
+					timings, _ := q.LastTimings()
+					total.Filtering += timings.Filtering
+					total.Sorting += timings.Sorting
+					total.Limiting += timings.Limiting
+					total.Exporting += timings.Exporting
+					total.Formatting += timings.Formatting
+				}
+
+				// Report the time spent in each stage to spot the bottlenecks
+				if timingsEnabled {
+					b.ReportMetric(float64(total.Filtering.Nanoseconds())/float64(b.N), "filtering-ns/op")
+					b.ReportMetric(float64(total.Sorting.Nanoseconds())/float64(b.N), "sorting-ns/op")
+					b.ReportMetric(float64(total.Limiting.Nanoseconds())/float64(b.N), "limiting-ns/op")
+					b.ReportMetric(float64(total.Formatting.Nanoseconds())/float64(b.N), "formatting-ns/op")
+				}
+			})
+		}
+	}
+}
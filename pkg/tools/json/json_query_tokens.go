@@ -0,0 +1,41 @@
+package json
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// queryTokenPattern matches the key:value tokens of a query, e.g. "deactivated:true"
+var queryTokenPattern = regexp.MustCompile(`\b([a-z][a-z0-9_]*):(\S+)`)
+
+// queryTokenKeys are the keys of the key:value tokens understood in queries, other tokens are left in the query
+var queryTokenKeys = map[string]bool{
+	"deactivated": true,
+}
+
+// parseQueryTokens extracts the key:value tokens with a known key from the lowercased query
+// It returns the tokens by key and the query without them, so that they do not trigger the keyword filters
+func parseQueryTokens(query string) (map[string]string, string) {
+	tokens := make(map[string]string)
+	rest := queryTokenPattern.ReplaceAllStringFunc(query, func(token string) string {
+		matches := queryTokenPattern.FindStringSubmatch(token)
+		if !queryTokenKeys[matches[1]] {
+			return token
+		}
+		tokens[matches[1]] = strings.TrimRight(matches[2], "?!.,;")
+		return ""
+	})
+
+	return tokens, strings.Join(strings.Fields(rest), " ")
+}
+
+// parseBoolToken parses the value of a boolean key:value token
+func parseBoolToken(key, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for %s, expected true or false", value, key)
+	}
+	return b, nil
+}
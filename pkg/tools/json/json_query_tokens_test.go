@@ -0,0 +1,58 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestDeactivatedToken(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Title: "Engineer"},
+		{FirstName: "Jane", LastName: "Roe", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Max", LastName: "Poe", Deactivated: true, DeactivatedDate: "2023-04-01"},
+	}
+	data := mustMarshal(t, employees)
+	q := NewJSONQuery()
+
+	tests := []struct {
+		query    string
+		expected []string
+		excluded []string
+	}{
+		{"list deactivated:true", []string{"Found 2 employees", "Jane Roe", "Max Poe"}, []string{"John Doe"}},
+		{"list deactivated:false", []string{"Found 1 employees", "John Doe"}, []string{"Jane Roe", "Max Poe"}},
+		// The token wins over the keywords of the query
+		{"List inactive employees deactivated:false", []string{"John Doe"}, []string{"Jane Roe", "Max Poe"}},
+		{"How many employees? DEACTIVATED:TRUE", []string{"2 deactivated employees."}, []string{"John Doe"}},
+		{"list deactivated:maybe", []string{`invalid value "maybe" for deactivated`}, []string{"John Doe", "Jane Roe"}},
+	}
+
+	for _, tt := range tests {
+		output, err := q.ProcessQuery(data, tt.query)
+		if err != nil {
+			t.Fatalf("Error processing query %q: %v", tt.query, err)
+		}
+		for _, value := range tt.expected {
+			if !strings.Contains(output, value) {
+				t.Errorf("Query %q: expected %q in output:\n%s", tt.query, value, output)
+			}
+		}
+		for _, value := range tt.excluded {
+			if strings.Contains(output, value) {
+				t.Errorf("Query %q: unexpected %q in output:\n%s", tt.query, value, output)
+			}
+		}
+	}
+}
+
+func TestParseQueryTokens(t *testing.T) {
+	tokens, rest := parseQueryTokens("show deactivated:true employees at https://example.com, sorted")
+	if len(tokens) != 1 || tokens["deactivated"] != "true" {
+		t.Errorf("Expected the deactivated token only, got %v", tokens)
+	}
+	if rest != "show employees at https://example.com, sorted" {
+		t.Errorf("Expected the query without the token, got %q", rest)
+	}
+}
@@ -17,12 +17,17 @@ type JSONQueryTool struct {
 }
 
 // NewJSONQueryTool creates a new instance of JSONQueryTool
-func NewJSONQueryTool() *JSONQueryTool {
+func NewJSONQueryTool(opts ...Option) *JSONQueryTool {
 	return &JSONQueryTool{
-		jsonQuery: NewJSONQuery(),
+		jsonQuery: NewJSONQuery(opts...),
 	}
 }
 
+// LastResultCount returns the number of employees returned by the last query, if known
+func (t *JSONQueryTool) LastResultCount() (int, bool) {
+	return t.jsonQuery.LastResultCount()
+}
+
 // Name returns the name of the tool
 func (t *JSONQueryTool) Name() string {
 	return "QueryJSON"
@@ -32,14 +37,42 @@ func (t *JSONQueryTool) Name() string {
 func (t *JSONQueryTool) Description() string {
 	return `Queries and manipulates JSON EmployeeInfo data to extract specific information.
 
-This tool accepts a file path to a JSON file containing an array of EmployeeInfo objects, along with a query operation.
+This tool accepts a file path to a JSON file containing an array of EmployeeInfo objects, or the array itself when
+it is small, along with a query operation.
 
 This tool can perform the following operations:
-- Filter data based on field values (active/deactivated status)
-- Sort data by deactivation date
-- Limit results to a specific number
-- Find specific employees by name
-- Format results as a markdown table or text list
+- Count the employees matching the filters instead of listing them (e.g. "how many employees are active?", "count deactivated employees with role engineer", "number of employees without a title")
+- Filter data based on field values (active/deactivated status), or exactly with a "deactivated:true" or "deactivated:false" token
+- Filter employees by two-factor authentication (2FA) status
+- Filter employees by presence, online or away, when it was fetched from Slack (e.g. "who is online")
+- Filter employees on whether they have a title at all (e.g. "employees with no title", "employees with a title")
+- Filter employees by role found in their title, given explicitly (e.g. "active employees with role engineer", "who are the deactivated marketing managers", "employees titled designer")
+- Filter employees by seniority level in their title (junior, mid, senior, staff, principal, lead), "+" meaning at or above a level
+- Find emails shared by multiple accounts (duplicate emails) or count unique emails
+- Show the active headcount trend over time from the previously fetched employees data files
+- Sort data by deactivation date (most recent first, or oldest first with "oldest"/"ascending"/"asc") or alphabetically by last name, first name or title (e.g. "sort employees by first name"), or on several keys (e.g. "sort by title then by deactivation date", "sort by status then name desc")
+- Limit results to a specific number ("last 10" for the most recent deactivations, "first 10" or "earliest 10" for the oldest ones), optionally skipping the first results for paging (e.g. "skip 20 top 20", "offset 10 take 10", "from 21", "show 21-40")
+- Find specific employees by full name or by a single first or last name (e.g. "find John"). When several employees match, they are listed with their title and email and the tool asks which one is meant: ask the user this clarifying question instead of picking one. Adding the email picks the right one among namesakes (e.g. "find John Doe john.doe@example.com")
+- Match names exactly rather than partially when the name is quoted or with the "exact" keyword (e.g. 'find "Ann Lee"' or "find exact Ann Lee" not matching Anne Leete), names containing the searched ones matching by default
+- Fall back to approximate name matches, closest first, when no employee matches exactly (e.g. typos such as "find Jon Smyth")
+- Find employees by email, matched exactly whatever the case (e.g. "find employee with email john.doe@acme.com"), or by email domain, subdomains included (e.g. "employees with email domain @contractor.com")
+- Find employees by their first and last name initials (e.g. "J.D." or "initials JD")
+- Find employees whose name or title matches a regular expression, case-insensitive unless "case sensitive" is asked for (e.g. 'regex title "Senior.*Engineer"' or 'regex name "^J"')
+- Combine conditions on the status, the title, the name and the email domain with "and" and "or", "and" taking precedence (e.g. "active and title contains engineer and email domain @acme.com" or 'title contains "product manager" or name contains jane')
+- Match names, titles and emails exactly as written, case included, when the query says "case sensitive" (e.g. "find John smith case sensitive")
+- Find employees deactivated on a given date (e.g. "deactivated on 2023-03-15") or on the same day as someone (e.g. "deactivated the same day as John Doe")
+- Find employees deactivated during a given year (e.g. "deactivated in 2023")
+- Find employees deactivated within a date range, bounds included (e.g. "between 2023-01-01 and 2023-06-30"), or after or before a date (e.g. "deactivated after 2023-01-01")
+- Find employees deactivated within a range relative to today: "last N days" (today included), "this week", "this month", "this quarter", "this year" (all up to today) or "yesterday" (e.g. "deactivated in the last 30 days")
+- Find deactivation dates in the future, either scheduled offboardings or bad data (e.g. "future deactivations")
+- Summarize the workforce: total, active and deactivated counts with their share, and the 5 most common titles, over all the employees whatever the filters (e.g. "summarize the workforce", "headcount summary", "overview of the workforce")
+- Count the employees of each title as a table, the most common first, untitled ones under "(no title)" (e.g. "group by title", "active employees per title")
+- Group the results under deactivation year headers, most recent first, with the count of each year (e.g. "deactivated employees by year")
+- Produce a deactivation audit report for compliance filings, with one section per deactivated employee (e.g. "audit report")
+- Add phone, timezone or Slack status text columns to markdown tables (e.g. "as a table with phone and timezone")
+- List the Slack user ID of each employee (e.g. "list deactivated employees with their slack id")
+- Format results as a markdown table, an HTML table to embed in web reports (e.g. "as html"), a text list, CSV with a header row (e.g. "as csv"), JSON (e.g. "as json") or NDJSON, one employee per line (e.g. "as ndjson"), or YAML (e.g. "as yaml")
+- Export results to an Excel file, whose path is returned (e.g. "export to excel")
 
 The input should be a JSON object with the following structure:
 {
@@ -47,11 +80,22 @@ The input should be a JSON object with the following structure:
   "query": "<query string describing the operation to perform>"
 }
 
+For a few employees, the array may be given inline in a "data" field instead of "file_path" (the data is used
+if both are given):
+{
+  "data": [{"first_name": "John", "last_name": "Doe", "deactivated": false}],
+  "query": "<query string describing the operation to perform>"
+}
+
 Example queries:
 - "Find the last 5 deactivated employees"
 - "When John Doe was deactivated?"
 - "List all deactivated engineering managers"
+- "List staff+ engineers"
 - "How many employees are active?"
+- "List active employees without 2FA"
+- "Find duplicate emails"
+- "Show the headcount trend"
 
 The tool will return the query results as a string, formatted appropriately for the query type.`
 }
@@ -76,8 +120,9 @@ func (t *JSONQueryTool) Call(ctx context.Context, input string) (string, error)
 
 	// Parse the input JSON
 	var queryInput struct {
-		FilePath string `json:"file_path"`
-		Query    string `json:"query"`
+		FilePath string          `json:"file_path"`
+		Data     json.RawMessage `json:"data"`
+		Query    string          `json:"query"`
 	}
 
 	err = json.Unmarshal([]byte(input), &queryInput)
@@ -86,14 +131,36 @@ func (t *JSONQueryTool) Call(ctx context.Context, input string) (string, error)
 		return "", fmt.Errorf("failed to parse input: %v", err)
 	}
 
+	// Query the employees given inline without reading any file, the inline data taking precedence over the file path
+	if data, ok := inlineData(queryInput.Data); ok {
+		if queryInput.FilePath != "" {
+			t.jsonQuery.logf("⚠️ Both data and file_path provided, querying the inline data\n")
+		}
+
+		// The headcount trend is computed over the data files only
+		if t.jsonQuery.isHeadcountTrendQuery(queryInput.Query) {
+			output = "Error: The headcount trend needs the data files, provide a file_path instead of data"
+			return "", fmt.Errorf("the headcount trend needs the data files, provide a file_path instead of data")
+		}
+
+		t.jsonQuery.logf("📄 Reading employee data from the input (%d bytes)\n", len(data))
+
+		output, err = t.jsonQuery.processQuery(data, "inline data", queryInput.Query)
+		if err != nil {
+			output = fmt.Sprintf("Error: %v", err)
+			return "", err
+		}
+		return output, nil
+	}
+
 	// Verify file path is provided
 	if queryInput.FilePath == "" {
-		output = "Error: No file path provided"
-		return "", fmt.Errorf("no file path provided")
+		output = "Error: No file path nor data provided"
+		return "", fmt.Errorf("no file path nor data provided")
 	}
 
 	// Clean up file path and ensure it exists
-	filePath := filepath.Clean(queryInput.FilePath)
+	filePath := t.resolveFilePath(filepath.Clean(queryInput.FilePath))
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		output = fmt.Sprintf("Error: Could not access file at %s: %v", filePath, err)
@@ -105,6 +172,16 @@ func (t *JSONQueryTool) Call(ctx context.Context, input string) (string, error)
 		return "", fmt.Errorf("%s is a directory, not a file", filePath)
 	}
 
+	// The headcount trend is computed over all the snapshots stored next to the file
+	if t.jsonQuery.isHeadcountTrendQuery(queryInput.Query) {
+		output, err = t.jsonQuery.HeadcountTrend(filepath.Dir(filePath))
+		if err != nil {
+			output = fmt.Sprintf("Error: %v", err)
+			return "", err
+		}
+		return output, nil
+	}
+
 	// Read the file contents
 	fileContents, err := os.ReadFile(filePath)
 	if err != nil {
@@ -112,14 +189,36 @@ func (t *JSONQueryTool) Call(ctx context.Context, input string) (string, error)
 		return "", fmt.Errorf("failed to read file %s: %v", filePath, err)
 	}
 
-	fmt.Printf("📄 Reading employee data from file: %s\n", filePath)
+	t.jsonQuery.logf("📄 Reading employee data from file: %s\n", filePath)
 
-	// Process the query using the gojsonq implementation
-	output, err = t.jsonQuery.ProcessQuery(fileContents, queryInput.Query)
+	// Process the query on the employees of the file
+	output, err = t.jsonQuery.processQuery(fileContents, "file "+filePath, queryInput.Query)
 	if err != nil {
 		output = fmt.Sprintf("Error: %v", err)
 		return "", err
 	}
 
+	// Warn when the data may be outdated
+	if warning, stale := t.jsonQuery.staleDataWarning(fileInfo.ModTime(), queryInput.Query); stale {
+		output = warning + "\n\n" + output
+	}
+
 	return output, nil
 }
+
+// resolveFilePath looks up a relative file path in the data directory when it is not found from the working directory
+func (t *JSONQueryTool) resolveFilePath(filePath string) string {
+	if t.jsonQuery.dataDir == "" || filepath.IsAbs(filePath) {
+		return filePath
+	}
+	if _, err := os.Stat(filePath); err == nil {
+		return filePath
+	}
+
+	for _, candidate := range []string{filepath.Join(t.jsonQuery.dataDir, filePath), filepath.Join(t.jsonQuery.dataDir, filepath.Base(filePath))} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return filePath
+}
@@ -0,0 +1,137 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotPattern matches the employees dumps written by the Slack tool that contain active employees
+var snapshotPattern = regexp.MustCompile(`^employees-(all|active)-(\d{8}-\d{6})\.json$`)
+
+// snapshotTimestampLayout is the layout of the timestamp in the dumps file names
+const snapshotTimestampLayout = "20060102-150405"
+
+// headcountPoint is the active headcount found in a snapshot
+type headcountPoint struct {
+	Timestamp time.Time
+	Active    int
+}
+
+// isHeadcountTrendQuery determines if the query asks for the active headcount over time
+func (q *JSONQuery) isHeadcountTrendQuery(query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(query, "headcount") &&
+		containsAny(query, "trend", "history", "over time", "evolution")
+}
+
+// HeadcountTrend reads the employees snapshots stored in dir and returns the active headcount over time
+// Only snapshots of all or active employees are used, unreadable snapshots are skipped. In strict mode, the
+// snapshots with fields unknown to this version fail the trend, see WithStrictDecoding
+func (q *JSONQuery) HeadcountTrend(dir string) (string, error) {
+	points, err := q.loadHeadcountPoints(dir)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), err
+	}
+
+	q.logf("📈 Found %d headcount snapshots in %s\n", len(points), dir)
+
+	if len(points) == 0 {
+		return "No employees snapshots found, fetch employees data to start tracking the headcount.", nil
+	}
+
+	var result strings.Builder
+
+	if len(points) == 1 {
+		result.WriteString("Only one snapshot available, fetch employees data again later to see a trend.\n\n")
+	}
+
+	result.WriteString("| Snapshot | Active | Change |\n")
+	result.WriteString("|----------|--------|--------|\n")
+
+	for i, point := range points {
+		change := ""
+		if i > 0 {
+			change = fmt.Sprintf("%+d", point.Active-points[i-1].Active)
+		}
+		result.WriteString(fmt.Sprintf("| %s | %d | %s |\n", point.Timestamp.Format("2006-01-02 15:04"), point.Active, change))
+	}
+
+	if len(points) > 1 {
+		first, last := points[0], points[len(points)-1]
+		result.WriteString(fmt.Sprintf("\nActive headcount went from %d to %d (%+d) between %s and %s.\n",
+			first.Active, last.Active, last.Active-first.Active,
+			first.Timestamp.Format("2006-01-02"), last.Timestamp.Format("2006-01-02")))
+	}
+
+	return result.String(), nil
+}
+
+// loadHeadcountPoints counts the active employees of every snapshot in dir, oldest first
+// When snapshots of all and active employees share a timestamp, only one point is kept
+func (q *JSONQuery) loadHeadcountPoints(dir string) ([]headcountPoint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read snapshots directory %s: %v", dir, err)
+	}
+
+	pointsByTime := make(map[time.Time]headcountPoint)
+
+	for _, entry := range entries {
+		matches := snapshotPattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		timestamp, err := time.ParseInLocation(snapshotTimestampLayout, matches[2], time.Local)
+		if err != nil {
+			continue
+		}
+
+		if _, exists := pointsByTime[timestamp]; exists {
+			continue
+		}
+
+		filePath := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			q.logf("⚠️ Skipping unreadable snapshot %s: %v\n", filePath, err)
+			continue
+		}
+
+		// The snapshots are decoded like the data files, a drift failing the trend in strict mode
+		employees, err := q.decodeEmployees(data, "snapshot "+filePath)
+		if errors.Is(err, errUnknownField) {
+			return nil, err
+		}
+		if err != nil {
+			q.logf("⚠️ Skipping malformed snapshot %s: %v\n", filePath, err)
+			continue
+		}
+
+		active := 0
+		for _, emp := range employees {
+			if !emp.Deactivated {
+				active++
+			}
+		}
+
+		pointsByTime[timestamp] = headcountPoint{Timestamp: timestamp, Active: active}
+	}
+
+	points := make([]headcountPoint, 0, len(pointsByTime))
+	for _, point := range pointsByTime {
+		points = append(points, point)
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+
+	return points, nil
+}
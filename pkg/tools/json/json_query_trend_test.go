@@ -0,0 +1,92 @@
+package json
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// writeSnapshot writes employees to a snapshot file named like the Slack tool dumps
+func writeSnapshot(t *testing.T, dir, name string, employees []model.EmployeeInfo) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), mustMarshal(t, employees), 0644); err != nil {
+		t.Fatalf("Error writing snapshot: %v", err)
+	}
+}
+
+// employeesFixture returns the given number of active and deactivated employees
+func employeesFixture(active, deactivated int) []model.EmployeeInfo {
+	var employees []model.EmployeeInfo
+	for i := 0; i < active; i++ {
+		employees = append(employees, model.EmployeeInfo{FirstName: "Active", LastName: "Employee"})
+	}
+	for i := 0; i < deactivated; i++ {
+		employees = append(employees, model.EmployeeInfo{FirstName: "Former", LastName: "Employee", Deactivated: true})
+	}
+	return employees
+}
+
+func TestHeadcountTrend(t *testing.T) {
+	dir := t.TempDir()
+
+	writeSnapshot(t, dir, "employees-all-20240101-090000.json", employeesFixture(10, 2))
+	writeSnapshot(t, dir, "employees-active-20240201-090000.json", employeesFixture(12, 0))
+	writeSnapshot(t, dir, "employees-all-20240301-090000.json", employeesFixture(9, 5))
+	// Deactivated-only snapshots carry no active headcount and unrelated files are ignored
+	writeSnapshot(t, dir, "employees-deactivated-20240215-090000.json", employeesFixture(0, 3))
+	writeSnapshot(t, dir, "notes.json", employeesFixture(100, 0))
+	// Malformed snapshots are skipped
+	if err := os.WriteFile(filepath.Join(dir, "employees-all-20240401-090000.json"), []byte("{"), 0644); err != nil {
+		t.Fatalf("Error writing snapshot: %v", err)
+	}
+
+	q := NewJSONQuery()
+	if !q.isHeadcountTrendQuery("Show the headcount trend") {
+		t.Fatal("Expected headcount trend query to be detected")
+	}
+
+	output, err := q.HeadcountTrend(dir)
+	if err != nil {
+		t.Fatalf("Error computing headcount trend: %v", err)
+	}
+
+	for _, expected := range []string{
+		"| 2024-01-01 09:00 | 10 |  |",
+		"| 2024-02-01 09:00 | 12 | +2 |",
+		"| 2024-03-01 09:00 | 9 | -3 |",
+		"from 10 to 9 (-1)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "| 100 |") || strings.Contains(output, "2024-02-15") || strings.Contains(output, "2024-04-01") {
+		t.Errorf("Unexpected snapshot in output:\n%s", output)
+	}
+}
+
+func TestHeadcountTrendSparseHistory(t *testing.T) {
+	q := NewJSONQuery()
+
+	output, err := q.HeadcountTrend(t.TempDir())
+	if err != nil {
+		t.Fatalf("Error computing headcount trend: %v", err)
+	}
+	if !strings.Contains(output, "No employees snapshots found") {
+		t.Errorf("Expected no snapshots message, got:\n%s", output)
+	}
+
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "employees-all-20240101-090000.json", employeesFixture(3, 1))
+
+	output, err = q.HeadcountTrend(dir)
+	if err != nil {
+		t.Fatalf("Error computing headcount trend: %v", err)
+	}
+	if !strings.Contains(output, "Only one snapshot available") || !strings.Contains(output, "| 2024-01-01 09:00 | 3 |  |") {
+		t.Errorf("Expected single snapshot output, got:\n%s", output)
+	}
+}
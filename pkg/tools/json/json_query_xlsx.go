@@ -0,0 +1,92 @@
+package json
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/export"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// excelPattern matches the queries asking for an Excel file, e.g. "export active employees to excel" or "as a spreadsheet"
+var excelPattern = regexp.MustCompile(`\b(?:excel|xlsx|spreadsheets?)\b`)
+
+// WithXLSXExport writes the results of the first query to the Excel workbook at path, replacing it if it exists,
+// and those of the next ones to workbooks numbered after it (e.g. employees-2.xlsx), so that a session does not
+// overwrite them. Without it, the queries asking for an Excel file write it to the data directory, numbered likewise
+func WithXLSXExport(path string) Option {
+	return func(q *JSONQuery) {
+		q.xlsxPath = path
+	}
+}
+
+// isExcelQuery determines if the lowercased query asks for an Excel file
+func isExcelQuery(query string) bool {
+	return excelPattern.MatchString(query)
+}
+
+// exportXLSX writes the employees to the configured Excel workbook, or to a new timestamped one in the data
+// directory, numbered after the first query of the session, and returns its absolute path. The columns are those of
+// the markdown tables, with the given optional columns, and the deactivation dates are written as Excel dates when
+// they can be parsed
+func (q *JSONQuery) exportXLSX(employees []model.EmployeeInfo, extraColumns []optionalColumn) (string, error) {
+	path := q.xlsxPath
+	if path == "" {
+		dir := q.dataDir
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		// Created like the data directory by the Slack tool, as the workbooks hold employee data
+		if err := os.MkdirAll(dir, dataDirPerm); err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "employees-export-"+q.now().Format("20060102-150405")+".xlsx")
+	}
+
+	// The queries of a session write their own workbooks, even within the same second
+	q.xlsxExports++
+	if q.xlsxExports > 1 {
+		ext := filepath.Ext(path)
+		path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), q.xlsxExports, ext)
+	}
+
+	withDepartment := hasDepartment(employees)
+
+	headers := []string{"Name", "Title"}
+	if withDepartment {
+		headers = append(headers, "Department")
+	}
+	headers = append(headers, "Email")
+	for _, column := range extraColumns {
+		headers = append(headers, column.header)
+	}
+	headers = append(headers, "Status", "Deactivation Date")
+
+	rows := make([][]any, 0, len(employees))
+	for _, emp := range employees {
+		row := []any{emp.FirstName + " " + emp.LastName, emp.Title}
+		if withDepartment {
+			row = append(row, emp.Department)
+		}
+		row = append(row, emp.Email)
+		for _, column := range extraColumns {
+			row = append(row, column.value(emp))
+		}
+
+		var deactivationDate any = ""
+		status := "Active"
+		if emp.Deactivated {
+			status = "Deactivated"
+			deactivationDate = emp.DeactivatedDate
+			if day, ok := q.deactivationDay(emp); ok {
+				deactivationDate = day
+			}
+		}
+		rows = append(rows, append(row, status, deactivationDate))
+	}
+
+	return export.ToXLSX(path, headers, rows)
+}
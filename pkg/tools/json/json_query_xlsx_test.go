@@ -0,0 +1,126 @@
+package json
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// readSheetXML returns the XML of the first sheet of the workbook at path
+func readSheetXML(t *testing.T, path string) string {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Error opening workbook: %v", err)
+	}
+	defer archive.Close()
+
+	r, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("Error opening sheet: %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error reading sheet: %v", err)
+	}
+	return string(data)
+}
+
+func TestExcelExport(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15"},
+		{FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Designer", Department: "Design", Deactivated: true, DeactivatedDate: "2024-01-10"},
+		{FirstName: "Max", LastName: "Poe", Email: "max.poe@example.com", Title: "Engineer"},
+	}
+	data := mustMarshal(t, employees)
+	dataDir := t.TempDir()
+
+	// Queries asking for an Excel file return its path, in the data directory
+	q := NewJSONQuery(WithDataDir(dataDir))
+	output, err := q.ProcessQuery(data, "Export deactivated employees to Excel")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	prefix := "Exported 2 employees to Excel file: " + filepath.Join(dataDir, "employees-export-")
+	if !strings.HasPrefix(output, prefix) || !strings.HasSuffix(output, ".xlsx") {
+		t.Fatalf("Expected the path of the Excel file, got:\n%s", output)
+	}
+	if count, _ := q.LastResultCount(); count != 2 {
+		t.Errorf("Expected a result count of 2, got %d", count)
+	}
+
+	sheet := readSheetXML(t, strings.TrimPrefix(output, "Exported 2 employees to Excel file: "))
+	for _, expected := range []string{
+		// Header row matching the markdown table columns
+		`<t xml:space="preserve">Name</t>`, `<t xml:space="preserve">Department</t>`, `<t xml:space="preserve">Deactivation Date</t>`,
+		`<t xml:space="preserve">John Doe</t>`, `<t xml:space="preserve">Jane Roe</t>`,
+		`<v>45000</v>`, // 2023-03-15 as an Excel date
+	} {
+		if !strings.Contains(sheet, expected) {
+			t.Errorf("Expected %q in the sheet, got:\n%s", expected, sheet)
+		}
+	}
+	if rows := strings.Count(sheet, "<row "); rows != 3 {
+		t.Errorf("Expected a header row and 2 employee rows, got %d rows", rows)
+	}
+
+	// The configured workbook is written on each query, the results being formatted as usual
+	xlsxPath := filepath.Join(t.TempDir(), "report.xlsx")
+	q = NewJSONQuery(WithXLSXExport(xlsxPath))
+	output, err = q.ProcessQuery(data, "Show active employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	if !strings.Contains(output, "Max Poe") || !strings.Contains(output, "Exported 1 employees to Excel file: "+xlsxPath) {
+		t.Errorf("Expected the results and the export note, got:\n%s", output)
+	}
+	if sheet := readSheetXML(t, xlsxPath); strings.Count(sheet, "<row ") != 2 || strings.Contains(sheet, "Department") {
+		t.Errorf("Expected a header row without department and 1 employee row, got:\n%s", sheet)
+	}
+
+	// The next queries of the session write numbered workbooks, keeping the previous ones
+	output, err = q.ProcessQuery(data, "Show deactivated employees")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+	nextPath := strings.TrimSuffix(xlsxPath, ".xlsx") + "-2.xlsx"
+	if !strings.Contains(output, "Exported 2 employees to Excel file: "+nextPath) {
+		t.Errorf("Expected the export note of %s, got:\n%s", nextPath, output)
+	}
+	if sheet := readSheetXML(t, nextPath); !strings.Contains(sheet, "Jane Roe") {
+		t.Errorf("Expected the deactivated employee in the second workbook, got:\n%s", sheet)
+	}
+	if sheet := readSheetXML(t, xlsxPath); !strings.Contains(sheet, "Max Poe") {
+		t.Errorf("Expected the first workbook to be kept, got:\n%s", sheet)
+	}
+}
+
+func TestExcelExportToDataDir(t *testing.T) {
+	data := mustMarshal(t, []model.EmployeeInfo{{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"}})
+	dataDir := filepath.Join(t.TempDir(), "data")
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	q := NewJSONQuery(WithDataDir(dataDir), WithClock(func() time.Time { return now }))
+
+	// Two queries within the same second write their own workbooks
+	for _, name := range []string{"employees-export-20240301-090000.xlsx", "employees-export-20240301-090000-2.xlsx"} {
+		output, err := q.ProcessQuery(data, "Export all employees to Excel")
+		if err != nil {
+			t.Fatalf("Error processing query: %v", err)
+		}
+		if expected := "Exported 1 employees to Excel file: " + filepath.Join(dataDir, name); output != expected {
+			t.Errorf("Expected %q, got:\n%s", expected, output)
+		}
+	}
+
+	// The data directory holds employee data, readable by its owner only
+	if info, err := os.Stat(dataDir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected the data directory to be created with permissions 0700, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,39 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// yamlPattern matches the queries asking for YAML, e.g. "list active employees as yaml"
+var yamlPattern = regexp.MustCompile(`\bya?ml\b`)
+
+// isYAMLQuery determines if the lowercased query asks for YAML
+func isYAMLQuery(query string) bool {
+	return yamlPattern.MatchString(query)
+}
+
+// FormatAsYAML formats the employee data as a YAML sequence, with the keys of the JSON output
+// Fields omitted from the data files when empty (e.g. deactivated_date) are omitted too
+func (q *JSONQuery) FormatAsYAML(employees []model.EmployeeInfo) (string, error) {
+	if employees == nil {
+		employees = []model.EmployeeInfo{} // An empty sequence rather than null
+	}
+
+	var result bytes.Buffer
+	encoder := yaml.NewEncoder(&result)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(employees); err != nil {
+		return fmt.Sprintf("Error: %v", err), err
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Sprintf("Error: %v", err), err
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,52 @@
+package json
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+func TestFormatAsYAML(t *testing.T) {
+	employees := []model.EmployeeInfo{
+		{SlackID: "U001", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Engineer", Deactivated: true, DeactivatedDate: "2023-03-15", Has2FA: boolPtr(true)},
+		{SlackID: "U002", FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Designer: UX"},
+		{SlackID: "U003", FirstName: "Max", LastName: "Poe", Email: "max.poe@example.com", Title: "Engineer"},
+	}
+	data := mustMarshal(t, employees)
+
+	// Filtered, sorted and limited like the other formats
+	q := NewJSONQuery()
+	output, err := q.ProcessQuery(data, "Show the top 2 employees sorted by last name as yaml")
+	if err != nil {
+		t.Fatalf("Error processing query: %v", err)
+	}
+
+	var decoded []model.EmployeeInfo
+	if err := yaml.Unmarshal([]byte(output), &decoded); err != nil {
+		t.Fatalf("Error decoding YAML output: %v\n%s", err, output)
+	}
+	if expected := []model.EmployeeInfo{employees[0], employees[2]}; !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected the round-trip to give back %v, got %v", expected, decoded)
+	}
+	if count, _ := q.LastResultCount(); count != 2 {
+		t.Errorf("Expected a result count of 2, got %d", count)
+	}
+
+	// Keys of the JSON output, in the same order, without the empty fields
+	if !strings.HasPrefix(output, "- slack_id: U001\n  first_name: John\n  last_name: Doe\n") ||
+		!strings.Contains(output, "  deactivated_date: \"2023-03-15\"\n") || !strings.Contains(output, "  has_2fa: true\n") {
+		t.Errorf("Expected the keys of the JSON output, got:\n%s", output)
+	}
+	if strings.Count(output, "deactivated_date") != 1 {
+		t.Errorf("Expected the empty deactivation dates to be omitted, got:\n%s", output)
+	}
+
+	output, err = q.ProcessQuery(data, "Show employees deactivated in 2020 as yml")
+	if err != nil || output != "[]\n" {
+		t.Errorf("Expected an empty sequence, got %q, %v", output, err)
+	}
+}
@@ -2,8 +2,14 @@ package slack
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 
@@ -12,22 +18,241 @@ import (
 )
 
 const (
-	maxUsersPerPage       = 500 // Recommended by Slack for optimal performance
-	maxPaginationAttempts = 10  // Prevent infinite loops but allow up to 4000 users (20 * 200)
+	// DefaultPageSize is the default number of users fetched per page, recommended by Slack for optimal performance
+	DefaultPageSize = 500
+	// DefaultMaxPages is the default maximum number of pages fetched, preventing infinite loops
+	// but allowing up to 5000 users with the default page size
+	DefaultMaxPages = 10
+	// DefaultImageSize is the default size in pixels of the profile pictures, see WithImageSize
+	DefaultImageSize = 192
+	// DefaultCacheTTL is the default age under which the data file of a previous search is reused
+	DefaultCacheTTL = 15 * time.Minute
 )
 
+// ErrNoUsers is returned when Slack authenticates the token but lists no users, which usually means that
+// the token lacks the users:read scope rather than an empty workspace
+var ErrNoUsers = errors.New("no users returned by Slack although the token is valid, check that the token has the users:read scope (and users:read.email for the emails)")
+
 // SlackTool handles interactions with Slack API
 type SlackTool struct {
-	client *slack.Client
-	token  string
+	client        *slack.Client
+	token         string
+	userToken     string // Used by the operations needing a user token (admin API, presence), token if empty
+	apiURL        string
+	visibility    *visibilityRecorder
+	pathRedaction bool
+	progressBar   bool
+	quiet         bool      // No spinners nor progress bars, for scripting
+	logOutput     io.Writer // Progress messages, see WithLogOutput
+	nameParticles []string
+	dateFormat    string
+	departmentID  string
+	imageSize     int
+	pageSize      int
+	maxPages      int
+	cacheTTL      time.Duration
+	dataDir       string
+	retention     time.Duration
+	maxDataFiles  int
+	admin         adminClient
+	enterprise    enterpriseClient
+	presence      presenceClient
+	orgWide       bool                 // Lists the users of all the workspaces of the organization (Enterprise Grid)
+	deactivations map[string]time.Time // Actual deactivation times from the admin API, nil when not available
+	dryRun        bool                 // Returns a fixture file instead of calling Slack, see WithDryRun
+	dryRunFixture string
+}
+
+// DefaultNameParticles are the particles kept with the last name when splitting full names (e.g. "van Gogh")
+var DefaultNameParticles = []string{"van", "von", "der", "den", "de", "la", "le", "del", "della", "da", "di", "du", "bin", "ibn"}
+
+// Option configures the Slack tools
+type Option func(*SlackTool)
+
+// WithPathRedaction makes the Slack tool return the data file path relative to the working directory
+// (or only the file name) instead of its absolute path, the file is still written at the same location
+func WithPathRedaction(enabled bool) Option {
+	return func(s *SlackTool) {
+		s.pathRedaction = enabled
+	}
+}
+
+// WithNameParticles sets the particles kept with the last name when splitting full names (e.g. "van", "de")
+func WithNameParticles(particles []string) Option {
+	return func(s *SlackTool) {
+		s.nameParticles = make([]string, 0, len(particles))
+		for _, particle := range particles {
+			if particle = strings.TrimSpace(particle); particle != "" {
+				s.nameParticles = append(s.nameParticles, particle)
+			}
+		}
+	}
+}
+
+// WithProgressBar shows a progress bar instead of a spinner while fetching users, when their number can be
+// estimated from a previous fetch of all employees and the output is a terminal
+func WithProgressBar(enabled bool) Option {
+	return func(s *SlackTool) {
+		s.progressBar = enabled
+	}
+}
+
+// WithQuiet suppresses the spinners and the progress bar while fetching users, e.g. in scripting mode so that
+// captured output has no control characters
+func WithQuiet(enabled bool) Option {
+	return func(s *SlackTool) {
+		s.quiet = enabled
+	}
+}
+
+// WithDateFormat sets the Go layout of the deactivation dates written to the data files (e.g. "02/01/2006" or time.RFC3339)
+func WithDateFormat(layout string) Option {
+	return func(s *SlackTool) {
+		s.dateFormat = layout
+	}
+}
+
+// WithDepartmentField sets the ID of the custom profile field holding the department (e.g. "Xf01234ABCD")
+// Custom field IDs vary per workspace, the department is left empty when no ID is set
+func WithDepartmentField(fieldID string) Option {
+	return func(s *SlackTool) {
+		s.departmentID = strings.TrimSpace(fieldID)
+	}
+}
+
+// WithImageSize sets the size in pixels of the profile pictures: 24, 32, 48, 72, 192 or 512, other values are ignored
+func WithImageSize(size int) Option {
+	return func(s *SlackTool) {
+		if slices.Contains(imageSizes, size) {
+			s.imageSize = size
+		}
+	}
+}
+
+// WithPageSize sets the number of users fetched per page, values below 1 are ignored
+func WithPageSize(size int) Option {
+	return func(s *SlackTool) {
+		if size > 0 {
+			s.pageSize = size
+		}
+	}
+}
+
+// WithMaxPages sets the maximum number of pages of users fetched, values below 1 are ignored
+// Users beyond the last page are missing from the results, a warning is shown when it happens
+func WithMaxPages(pages int) Option {
+	return func(s *SlackTool) {
+		if pages > 0 {
+			s.maxPages = pages
+		}
+	}
+}
+
+// WithCacheTTL sets the age under which the data file of a previous search is reused instead of fetching the
+// employees from Slack again. A TTL of 0 disables the cache
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *SlackTool) {
+		s.cacheTTL = ttl
+	}
+}
+
+// DefaultDataDir returns the default directory of the employees data files, under the cache directory of the user
+// (e.g. ~/.cache/ama-employees on Linux) so that the agent can run from any working directory, read-only ones
+// included, without sharing the employees data with the other users. The temporary directory is used if the user
+// has no cache directory
+func DefaultDataDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "ama-employees")
+}
+
+// WithDataDir sets the directory where the employees data files are written, DefaultDataDir if empty
+func WithDataDir(dir string) Option {
+	return func(s *SlackTool) {
+		if dir != "" {
+			s.dataDir = dir
+		}
+	}
+}
+
+// WithAPIURL sets the base URL of the Slack API (e.g. a proxy or a fake server in tests), it must end with a slash
+func WithAPIURL(url string) Option {
+	return func(s *SlackTool) {
+		s.apiURL = url
+	}
+}
+
+// WithUserToken sets the user token of the operations needing one, i.e. the admin API lookups of the deactivation
+// dates and the presence fetches, the token given to NewSlackTool (e.g. a bot token) listing the users
+// The token given to NewSlackTool is used for all the operations if empty
+func WithUserToken(token string) Option {
+	return func(s *SlackTool) {
+		s.userToken = strings.TrimSpace(token)
+	}
 }
 
 // NewSlackTool creates a new instance of the Slack tool
-func NewSlackTool(token string) *SlackTool {
-	return &SlackTool{
-		client: slack.New(token),
-		token:  token,
+func NewSlackTool(token string, opts ...Option) *SlackTool {
+	s := &SlackTool{
+		token:         token,
+		visibility:    newVisibilityRecorder(),
+		nameParticles: DefaultNameParticles,
+		dateFormat:    model.DefaultDateFormat,
+		imageSize:     DefaultImageSize,
+		pageSize:      DefaultPageSize,
+		maxPages:      DefaultMaxPages,
+		cacheTTL:      DefaultCacheTTL,
+		dataDir:       DefaultDataDir(),
+		retention:     DefaultRetention,
+		logOutput:     os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	clientOpts := []slack.Option{slack.OptionHTTPClient(s.visibility)}
+	if s.apiURL != "" {
+		clientOpts = append(clientOpts, slack.OptionAPIURL(s.apiURL))
+	}
+	s.client = slack.New(token, clientOpts...)
+
+	// The user token is only used by the admin and presence clients
+	userClient := s.client
+	if s.userToken == "" {
+		s.userToken = token
+	} else if s.userToken != token {
+		userClient = slack.New(s.userToken, clientOpts...)
+	}
+
+	s.admin = &httpAdminClient{token: s.userToken, apiURL: s.baseURL(), client: s.visibility, pageSize: s.pageSize, maxPages: s.maxPages}
+	s.presence = userClient
+	s.enterprise = &slackEnterpriseClient{client: s.client, pageSize: s.pageSize, maxPages: s.maxPages}
+
+	return s
+}
+
+// baseURL returns the base URL of the Slack API, the one set with WithAPIURL or the Slack Web API
+func (s *SlackTool) baseURL() string {
+	if s.apiURL == "" {
+		return defaultAPIURL
+	}
+	return s.apiURL
+}
+
+// startSpinner starts a spinner with the message, the stub of misc.StartSpinner when the tool is quiet
+func (s *SlackTool) startSpinner(message string) misc.Spinner {
+	if s.quiet {
+		return nil
 	}
+	return misc.StartSpinner(message)
+}
+
+// RestrictedVisibility returns whether Slack reported users not visible to the token during the last search
+// Such users are silently omitted from the results, skewing counts
+func (s *SlackTool) RestrictedVisibility() bool {
+	return s.visibility.Restricted()
 }
 
 // FilterType defines the type of employee filter
@@ -45,49 +270,195 @@ const (
 // SearchAMAEmployees searches for employees on Slack
 // filter parameter can be "all", "active", or "deactivated"
 func (s *SlackTool) SearchAMAEmployees(filter FilterType) ([]model.EmployeeInfo, error) {
-	spinner := misc.StartSpinner("🔌 Connecting to Slack workspace...")
+	return s.SearchAMAEmployeesContext(context.Background(), filter)
+}
+
+// SearchAMAEmployeesContext searches for employees on Slack, stopping when the context is done
+func (s *SlackTool) SearchAMAEmployeesContext(ctx context.Context, filter FilterType) ([]model.EmployeeInfo, error) {
+	return s.searchEmployees(ctx, filter, false)
+}
+
+// searchEmployees searches for employees on Slack, bot accounts included if includeBots is true
+func (s *SlackTool) searchEmployees(ctx context.Context, filter FilterType, includeBots bool) ([]model.EmployeeInfo, error) {
+	employees, err := s.fetchEmployees(ctx, includeBots)
+	if err != nil {
+		return nil, err
+	}
+	return FilterEmployees(employees, filter), nil
+}
+
+// FetchAllEmployees fetches all the employees, active and deactivated, in a single pass over the Slack users
+// Callers needing several subsets (e.g. a status breakdown) derive them with FilterEmployees or
+// PartitionEmployees rather than fetching the users again
+func (s *SlackTool) FetchAllEmployees() ([]model.EmployeeInfo, error) {
+	return s.FetchAllEmployeesContext(context.Background())
+}
+
+// FetchAllEmployeesContext fetches all the employees like FetchAllEmployees, stopping when the context is done
+// (e.g. on timeout), without partial results
+func (s *SlackTool) FetchAllEmployeesContext(ctx context.Context) ([]model.EmployeeInfo, error) {
+	return s.fetchEmployees(ctx, false)
+}
+
+// fetchEmployees fetches all the employees, bot accounts included if includeBots is true (e.g. to audit service accounts)
+func (s *SlackTool) fetchEmployees(ctx context.Context, includeBots bool) ([]model.EmployeeInfo, error) {
+	s.visibility.Reset()
+
+	spinner := s.startSpinner("🔌 Connecting to Slack workspace...")
 
 	// Test the authentication
-	authTest, err := s.client.AuthTest()
+	authTest, err := s.client.AuthTestContext(ctx)
 
 	misc.StopSpinner(spinner)
 
 	if err != nil {
-		return nil, fmt.Errorf("slack authentication failed: %v", err)
+		return nil, fmt.Errorf("slack authentication failed: %w", err)
 	}
 
 	// Print success message after spinner is cleared
-	fmt.Printf("✅ Successfully authenticated to Slack as %s in team %s\n", authTest.User, authTest.Team)
+	s.logf("✅ Successfully authenticated to Slack as %s in team %s\n", authTest.User, authTest.Team)
+
+	// Two-factor status is only returned by Slack when the token belongs to an admin
+	twoFactorVisible := s.isTwoFactorVisible(ctx, authTest.UserID)
+	if !twoFactorVisible {
+		s.logf("⚠️ Two-factor status not visible to this token (requires an admin or owner token)\n")
+	}
+
+	// The actual deactivation dates are only available to admins and owners, through the admin API called with the
+	// user token, whatever the role of the bot token checked above. The dates are estimated if the API is unavailable
+	// On Enterprise Grid, they are read per workspace once the workspaces of the organization are listed
+	s.deactivations = nil
+	if !s.orgWide {
+		s.loadActualDeactivationDates(ctx, authTest.TeamID)
+	}
+
+	// On Enterprise Grid, the users of the other workspaces of the organization are only listed on demand
+	if s.orgWide {
+		return s.fetchEnterpriseEmployees(ctx, twoFactorVisible, includeBots)
+	}
+	if authTest.EnterpriseID != "" {
+		s.logf("ℹ️ Enterprise Grid organization detected: only the users of this workspace are listed, the other workspaces require the org-wide listing\n")
+	}
+
+	// Show the progress against the number of employees of the previous fetch if possible
+	var progress *misc.ProgressBar
+	if s.progressBar && !s.quiet {
+		progress = misc.StartProgressBar("📥 Fetching users", estimateUserCount(s.dataDir))
+	}
 
 	var employees []model.EmployeeInfo
-	fetchSpinner := misc.StartSpinner("🔍 Fetching employees data...")
-	employees, err = s.searchAMAEmployeesUsingStandardAPI(filter)
-	misc.StopSpinner(fetchSpinner)
+	if progress != nil {
+		employees, err = s.searchAMAEmployeesUsingStandardAPI(ctx, twoFactorVisible, includeBots, progress)
+		progress.Stop()
+	} else {
+		fetchSpinner := s.startSpinner("🔍 Fetching employees data...")
+		employees, err = s.searchAMAEmployeesUsingStandardAPI(ctx, twoFactorVisible, includeBots, nil)
+		misc.StopSpinner(fetchSpinner)
+	}
 
 	// Handle the result
 	if err != nil {
-		return nil, fmt.Errorf("error searching for employees: %v", err)
+		return nil, fmt.Errorf("error searching for employees: %w", err)
+	}
+
+	// Slack lists at least the user of the token, no users at all points to a missing scope
+	if len(employees) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	s.logf("👤 Found %d employees\n", len(employees))
+
+	if s.RestrictedVisibility() {
+		s.logf("⚠️ Slack reported users not visible to this token, they are missing from the results\n")
 	}
 
-	fmt.Printf("👤 Found %d employees\n", len(employees))
 	return employees, nil
 }
 
+// fetchEnterpriseEmployees fetches the employees of all the workspaces of the organization
+func (s *SlackTool) fetchEnterpriseEmployees(ctx context.Context, twoFactorVisible, includeBots bool) ([]model.EmployeeInfo, error) {
+	employees, err := s.searchAMAEmployeesUsingEnterpriseAPI(ctx, twoFactorVisible, includeBots)
+	if err != nil {
+		return nil, fmt.Errorf("error searching for employees of the organization: %w", err)
+	}
+	if len(employees) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	s.logf("👤 Found %d employees\n", len(employees))
+
+	if s.RestrictedVisibility() {
+		s.logf("⚠️ Slack reported users not visible to this token, they are missing from the results\n")
+	}
+
+	return employees, nil
+}
+
+// FilterEmployees returns the employees matching the filter, without fetching them again
+func FilterEmployees(employees []model.EmployeeInfo, filter FilterType) []model.EmployeeInfo {
+	if filter == FilterAll {
+		return employees
+	}
+
+	active, deactivated := PartitionEmployees(employees)
+	if filter == FilterDeactivated {
+		return deactivated
+	}
+	return active
+}
+
+// PartitionEmployees splits the employees into the active and the deactivated ones, keeping their order
+func PartitionEmployees(employees []model.EmployeeInfo) (active, deactivated []model.EmployeeInfo) {
+	active, deactivated = []model.EmployeeInfo{}, []model.EmployeeInfo{}
+	for _, emp := range employees {
+		if emp.Deactivated {
+			deactivated = append(deactivated, emp)
+		} else {
+			active = append(active, emp)
+		}
+	}
+	return active, deactivated
+}
+
+// isTwoFactorVisible checks whether the authenticated user can see the two-factor status of other users
+func (s *SlackTool) isTwoFactorVisible(ctx context.Context, userID string) bool {
+	user, err := s.client.GetUserInfoContext(ctx, userID)
+	if err != nil {
+		return false
+	}
+	return user.IsAdmin || user.IsOwner || user.IsPrimaryOwner
+}
+
 // searchAMAEmployeesUsingStandardAPI uses the standard Slack API to search for employees
 // Uses GetUsersPaginated for efficient pagination
-func (s *SlackTool) searchAMAEmployeesUsingStandardAPI(filter FilterType) ([]model.EmployeeInfo, error) {
+// Bots are skipped unless includeBots is true
+// The progress bar, if any, is updated after each page with the number of users that are not bots
+// The pagination stops as soon as the context is done, the context error is then returned
+func (s *SlackTool) searchAMAEmployeesUsingStandardAPI(ctx context.Context, twoFactorVisible, includeBots bool, progress *misc.ProgressBar) ([]model.EmployeeInfo, error) {
 	employees := []model.EmployeeInfo{}
 	paginationCount := 0 // Start at 0 since the first page is just initialization
 	totalUsers := 0
-	ctx := context.Background()
+	humanUsers := 0
 
-	standardApiSpinner := misc.StartSpinner("📥 Fetching users with pagination...")
+	var standardApiSpinner misc.Spinner
+	if progress == nil {
+		standardApiSpinner = s.startSpinner("📥 Fetching users with pagination...")
+	}
 
 	// Get paginated users - this just initializes the pagination structure
-	pagination := s.client.GetUsersPaginated(slack.GetUsersOptionLimit(maxUsersPerPage))
+	pagination := s.client.GetUsersPaginated(slack.GetUsersOptionLimit(s.pageSize))
 
 	// Process pages with actual fetching
-	for paginationCount < maxPaginationAttempts {
+	missing := 0 // Users of the first page beyond the maximum number of pages
+	for {
+		// Stop at the maximum number of pages, checking whether Slack has more users
+		if paginationCount >= s.maxPages {
+			if next, err := pagination.Next(ctx); !next.Done(err) && next.Failure(err) == nil {
+				missing = len(next.Users)
+			}
+			break
+		}
+
 		var err error
 		pagination, err = pagination.Next(ctx)
 
@@ -98,8 +469,16 @@ func (s *SlackTool) searchAMAEmployeesUsingStandardAPI(filter FilterType) ([]mod
 
 		paginationCount++
 
+		// Cancelled or timed out, the results would be partial
+		if ctx.Err() != nil {
+			if standardApiSpinner != nil {
+				misc.StopSpinner(standardApiSpinner)
+			}
+			return nil, fmt.Errorf("fetching users interrupted after %d pages: %w", paginationCount-1, ctx.Err())
+		}
+
 		if pagination.Failure(err) != nil {
-			fmt.Printf("❌ Error fetching next page: %v\n", pagination.Failure(err))
+			s.logf("❌ Error fetching next page: %v\n", pagination.Failure(err))
 			break
 		}
 
@@ -109,64 +488,150 @@ func (s *SlackTool) searchAMAEmployeesUsingStandardAPI(filter FilterType) ([]mod
 		// Process users from this page
 		for _, user := range pagination.Users {
 			if !user.IsBot {
-				processUser(&employees, user, filter)
+				humanUsers++
+				s.processUser(&employees, user, twoFactorVisible)
+			} else if includeBots {
+				s.processUser(&employees, user, twoFactorVisible)
 			}
 		}
+
+		if progress != nil {
+			progress.Update(humanUsers)
+		}
 	}
 
-	if paginationCount >= maxPaginationAttempts {
-		fmt.Printf("⚠️ Reached maximum pagination attempts (%d), stopping\n", maxPaginationAttempts)
+	if standardApiSpinner != nil {
+		misc.StopSpinner(standardApiSpinner)
 	}
 
-	misc.StopSpinner(standardApiSpinner)
-	fmt.Printf("✅ Completed fetching users via standard API (total: %d users)\n", totalUsers)
+	if missing > 0 {
+		s.logf("⚠️ Reached the maximum of %d pages of %d users before the last page: %s may be missing from the results "+
+			"(increase the maximum number of pages or the page size)\n", s.maxPages, s.pageSize, s.estimateMissingUsers(humanUsers, missing))
+	}
+	s.logf("✅ Completed fetching users via standard API (total: %d users)\n", totalUsers)
 	return employees, nil
 }
 
+// estimateMissingUsers estimates the number of users left unfetched when the maximum number of pages is reached,
+// knowing that at least nextPage users follow. The number of employees of the previous fetch is used when it
+// leaves more users missing
+func (s *SlackTool) estimateMissingUsers(fetched, nextPage int) string {
+	if previous := estimateUserCount(s.dataDir); previous-fetched > nextPage {
+		return fmt.Sprintf("about %d users", previous-fetched)
+	}
+	return fmt.Sprintf("%d or more users", nextPage)
+}
+
 // processUser extracts information from a user and adds it to the employees slice
-func processUser(employees *[]model.EmployeeInfo, user slack.User, filter FilterType) {
+// The two-factor status is only recorded when it is visible to the token, otherwise it is left unknown
+func (s *SlackTool) processUser(employees *[]model.EmployeeInfo, user slack.User, twoFactorVisible bool) {
 	// Parse the name parts
-	nameParts := strings.Split(user.RealName, " ")
-	firstName := user.Profile.FirstName
-	lastName := user.Profile.LastName
+	realFirstName, realMiddleName, realLastName := splitName(user.RealName, s.nameParticles)
+	firstName := strings.TrimSpace(user.Profile.FirstName)
+	lastName := strings.TrimSpace(user.Profile.LastName)
 
 	// If the profile doesn't have the parts populated, try to extract from real name
-	if firstName == "" && len(nameParts) > 0 {
-		firstName = nameParts[0]
+	if firstName == "" {
+		firstName = realFirstName
 	}
 
-	if lastName == "" && len(nameParts) > 1 {
-		lastName = nameParts[len(nameParts)-1]
+	if lastName == "" {
+		lastName = realLastName
+	}
+
+	// The middle names only come from the real name, when it agrees with the names of the profile
+	middleName := ""
+	if strings.EqualFold(firstName, realFirstName) && strings.EqualFold(lastName, realLastName) {
+		middleName = realMiddleName
 	}
 
 	deactivatedDate := ""
+	deactivatedDateSource := ""
 
 	if user.Deleted {
-		// Generate a deactivated date from the user's last update time
-		deactivatedDate = estimateDeactivatedDateFromJSON(user.Updated)
+		if date, err := s.getActualDeactivationDate(user.ID); err == nil {
+			deactivatedDate = date
+			deactivatedDateSource = model.DateSourceVerified
+		} else {
+			// Generate a deactivated date from the user's last update time, which a later profile edit may shift
+			deactivatedDate = estimateDeactivatedDateFromJSON(user.Updated, s.dateFormat)
+			deactivatedDateSource = model.DateSourceEstimated
+		}
 	}
 
 	employee := model.EmployeeInfo{
-		FirstName:       firstName,
-		LastName:        lastName,
-		Email:           user.Profile.Email,
-		Title:           user.Profile.Title,
-		Deactivated:     user.Deleted,
-		DeactivatedDate: deactivatedDate,
+		SlackID:               user.ID,
+		FirstName:             firstName,
+		MiddleName:            middleName,
+		LastName:              lastName,
+		Email:                 user.Profile.Email,
+		Title:                 user.Profile.Title,
+		Phone:                 user.Profile.Phone,
+		Timezone:              user.TZ,
+		StatusText:            user.Profile.StatusText,
+		ImageURL:              profileImage(user.Profile, s.imageSize),
+		Deactivated:           user.Deleted,
+		DeactivatedDate:       deactivatedDate,
+		DeactivatedDateSource: deactivatedDateSource,
+		IsBot:                 user.IsBot,
+	}
+
+	if s.departmentID != "" {
+		if field, ok := user.Profile.Fields.ToMap()[s.departmentID]; ok {
+			employee.Department = strings.TrimSpace(field.Value)
+		}
+	}
+
+	if twoFactorVisible {
+		has2FA := user.Has2FA
+		employee.Has2FA = &has2FA
+	}
+
+	*employees = append(*employees, employee)
+}
+
+// splitName splits a full name into its first name, middle names and last name
+// The last name is the last word along with the particles preceding it (e.g. "van Gogh", "de la Cruz"),
+// the words in between are the middle names. Hyphenated names are kept whole (e.g. "Jean-Luc", "Watson-Parker"),
+// spaces around their hyphens included (e.g. "Watson - Parker")
+func splitName(fullName string, particles []string) (string, string, string) {
+	words := nameWords(fullName)
+	if len(words) == 0 {
+		return "", "", ""
+	}
+	if len(words) == 1 {
+		return words[0], "", ""
 	}
 
-	switch filter {
-	case FilterAll:
-		*employees = append(*employees, employee)
-	case FilterDeactivated:
-		if user.Deleted {
-			*employees = append(*employees, employee)
+	start := len(words) - 1
+	for start > 1 && isNameParticle(words[start-1], particles) {
+		start--
+	}
+
+	return words[0], strings.Join(words[1:start], " "), strings.Join(words[start:], " ")
+}
+
+// nameWords splits a full name into words, joining the parts of hyphenated names written with spaces
+func nameWords(fullName string) []string {
+	var words []string
+	for _, word := range strings.Fields(fullName) {
+		if len(words) > 0 && (strings.HasPrefix(word, "-") || strings.HasSuffix(words[len(words)-1], "-")) {
+			words[len(words)-1] += word
+			continue
 		}
-	case FilterActive:
-		if !user.Deleted {
-			*employees = append(*employees, employee)
+		words = append(words, word)
+	}
+	return words
+}
+
+// isNameParticle determines if word is one of the name particles (case-insensitive)
+func isNameParticle(word string, particles []string) bool {
+	for _, particle := range particles {
+		if strings.EqualFold(word, particle) {
+			return true
 		}
 	}
+	return false
 }
 
 // sortEmployeesByDeactivatedDateDesc sorts the given employees slice by deactivated date in descending order
@@ -192,12 +657,32 @@ func processUser(employees *[]model.EmployeeInfo, user slack.User, filter Filter
 // 	})
 // }
 
-// estimateDeactivatedDateFromJSON generates a deactivated date based on Slack's JSONTime
+// imageSizes are the sizes in pixels of the profile pictures provided by Slack
+var imageSizes = []int{24, 32, 48, 72, 192, 512}
+
+// profileImage returns the URL of the profile picture of the given size, empty when the user has none
+func profileImage(profile slack.UserProfile, size int) string {
+	switch size {
+	case 24:
+		return profile.Image24
+	case 32:
+		return profile.Image32
+	case 48:
+		return profile.Image48
+	case 72:
+		return profile.Image72
+	case 512:
+		return profile.Image512
+	default:
+		return profile.Image192
+	}
+}
+
+// estimateDeactivatedDateFromJSON generates a deactivated date based on Slack's JSONTime, formatted with layout
 // In a real implementation with admin access, we would get the actual deactivation date
-func estimateDeactivatedDateFromJSON(jsonTime slack.JSONTime) string {
+func estimateDeactivatedDateFromJSON(jsonTime slack.JSONTime, layout string) string {
 	// Use the Time() method to convert JSONTime to time.Time
 	t := jsonTime.Time()
 
-	// Format as YYYY-MM-DD
-	return t.Format("2006-01-02")
+	return t.Format(layout)
 }
@@ -0,0 +1,148 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultAPIURL is the URL of the Slack Web API, used when no other URL is set with WithAPIURL
+const defaultAPIURL = "https://slack.com/api/"
+
+// errAdminAPIUnavailable is returned when the deactivation times could not be read from the admin API
+var errAdminAPIUnavailable = errors.New("admin API not available to this token")
+
+// adminClient reads the actual deactivation times of the users from the Slack admin API
+type adminClient interface {
+	// Deactivations returns the deactivation times of the deactivated users of the team, keyed by user ID, and
+	// whether they were truncated at the maximum number of pages
+	Deactivations(ctx context.Context, teamID string) (map[string]time.Time, bool, error)
+}
+
+// httpDoer sends HTTP requests, e.g. *http.Client or the visibility recorder
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// httpAdminClient calls the admin.users.list method of the Slack admin API
+// It requires an org-level token of an admin or owner with the admin.users:read scope
+type httpAdminClient struct {
+	token    string
+	apiURL   string
+	client   httpDoer
+	pageSize int
+	maxPages int
+}
+
+// adminUsersListResponse is the response of the admin.users.list method
+type adminUsersListResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	Users []struct {
+		ID            string `json:"id"`
+		DeactivatedTS int64  `json:"deactivated_ts"`
+	} `json:"users"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// Deactivations lists the users of the team, page by page, and returns the deactivation times of the deactivated ones
+// They are truncated if users are left after the maximum number of pages
+func (c *httpAdminClient) Deactivations(ctx context.Context, teamID string) (map[string]time.Time, bool, error) {
+	deactivations := make(map[string]time.Time)
+	cursor := ""
+
+	for page := 0; page < c.maxPages; page++ {
+		form := url.Values{"limit": {fmt.Sprint(c.pageSize)}}
+		if teamID != "" {
+			form.Set("team_id", teamID)
+		}
+		if cursor != "" {
+			form.Set("cursor", cursor)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"admin.users.list", strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, false, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var response adminUsersListResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid admin.users.list response: %v", err)
+		}
+		if !response.OK {
+			// e.g. missing_scope, not_allowed_token_type or not_an_admin
+			return nil, false, fmt.Errorf("%w: %s", errAdminAPIUnavailable, response.Error)
+		}
+
+		for _, user := range response.Users {
+			if user.DeactivatedTS > 0 {
+				deactivations[user.ID] = time.Unix(user.DeactivatedTS, 0)
+			}
+		}
+
+		if cursor = response.ResponseMetadata.NextCursor; cursor == "" {
+			break
+		}
+	}
+
+	// The loop only ends with a cursor when the maximum number of pages is reached
+	return deactivations, cursor != "", nil
+}
+
+// loadActualDeactivationDates reads the actual deactivation times of the users of the teams from the admin API, for
+// getActualDeactivationDate. The estimated dates are used instead when the token cannot use the admin API, and for
+// the users left out of the pages read
+func (s *SlackTool) loadActualDeactivationDates(ctx context.Context, teamIDs ...string) {
+	s.deactivations = nil
+	if s.admin == nil {
+		return
+	}
+
+	deactivations := make(map[string]time.Time)
+	for _, teamID := range teamIDs {
+		teamDeactivations, truncated, err := s.admin.Deactivations(ctx, teamID)
+		if err != nil {
+			s.logf("⚠️ Deactivation dates estimated from the last profile updates (%v)\n", err)
+			return
+		}
+		if truncated {
+			s.logf("⚠️ Deactivation dates of team %s truncated at %d pages of %d users, the others are estimated "+
+				"(increase the maximum number of pages or the page size)\n", teamID, s.maxPages, s.pageSize)
+		}
+		maps.Copy(deactivations, teamDeactivations)
+	}
+
+	s.deactivations = deactivations
+	s.logf("📅 Deactivation dates read from the admin API (%d deactivated users)\n", len(deactivations))
+}
+
+// getActualDeactivationDate returns the deactivation date of the user as reported by the admin API
+func (s *SlackTool) getActualDeactivationDate(userID string) (string, error) {
+	if s.deactivations == nil {
+		return "", errAdminAPIUnavailable
+	}
+
+	deactivatedAt, ok := s.deactivations[userID]
+	if !ok {
+		return "", fmt.Errorf("no deactivation time reported for user %s", userID)
+	}
+
+	return deactivatedAt.Format(s.dateFormat), nil
+}
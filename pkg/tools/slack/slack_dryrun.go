@@ -0,0 +1,58 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// dryRunFileName is the name of the sample data file written to the data directory in dry-run mode
+// It does not start with "employees-", so that it is never reused nor cleaned up as a data file
+const dryRunFileName = "dry-run-employees.json"
+
+// dryRunEmployees is the sample dataset returned in dry-run mode when no fixture file is given
+var dryRunEmployees = []model.EmployeeInfo{
+	{SlackID: "U0DRY001", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Title: "Software Engineer"},
+	{SlackID: "U0DRY002", FirstName: "Jane", LastName: "Roe", Email: "jane.roe@example.com", Title: "Marketing Manager"},
+	{SlackID: "U0DRY003", FirstName: "Max", LastName: "Poe", Email: "max.poe@example.com", Title: "Senior Software Engineer",
+		Deactivated: true, DeactivatedDate: "2023-03-15", DeactivatedDateSource: model.DateSourceEstimated},
+	{SlackID: "U0DRY004", FirstName: "Ann", LastName: "Lee", Email: "ann.lee@example.com", Title: "Product Manager",
+		Deactivated: true, DeactivatedDate: "2024-01-31", DeactivatedDateSource: model.DateSourceEstimated},
+}
+
+// WithDryRun makes the tool return the path of the fixture file instead of fetching the employees from Slack, after
+// logging the search it would run. A sample dataset written to the data directory is returned if fixture is empty
+// No Slack API is called, so that the queries can be developed against a fixed dataset
+func WithDryRun(fixture string) Option {
+	return func(s *SlackTool) {
+		s.dryRun = true
+		s.dryRunFixture = fixture
+	}
+}
+
+// dryRunResult logs the search the tool would run and returns the path of the fixture file, whatever the filter
+func (t *SlackAMAEmployeesTool) dryRunResult(filter FilterType, includeBots, withPresence bool) (string, error) {
+	t.slackTool.logf("🧪 Dry run: would fetch %s employees from Slack (bots: %t, presence: %t)\n", filter, includeBots, withPresence)
+
+	filePath := t.slackTool.dryRunFixture
+	if filePath == "" {
+		data, err := json.Marshal(dryRunEmployees)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling sample employees data: %v", err)
+		}
+		if err := os.MkdirAll(t.slackTool.dataDir, dataDirPerm); err != nil {
+			return "", fmt.Errorf("error creating data directory: %v", err)
+		}
+		filePath = filepath.Join(t.slackTool.dataDir, dryRunFileName)
+		if err := os.WriteFile(filePath, data, dataFilePerm); err != nil {
+			return "", fmt.Errorf("error writing sample employees data to file: %v", err)
+		}
+	}
+
+	returnedPath := t.returnedPath(filePath)
+	t.slackTool.logf("🧪 Dry run: returning fixture file %s\n", returnedPath)
+	return returnedPath, nil
+}
@@ -0,0 +1,135 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/misc"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// enterpriseClient lists the users of the workspaces of a Slack Enterprise Grid organization
+type enterpriseClient interface {
+	// Workspaces returns the IDs of the workspaces of the organization visible to the token, and whether they were
+	// truncated at the maximum number of pages
+	Workspaces(ctx context.Context) ([]string, bool, error)
+	// WorkspaceUsers returns the users of a workspace of the organization, and whether they were truncated at the
+	// maximum number of pages
+	WorkspaceUsers(ctx context.Context, teamID string) ([]slack.User, bool, error)
+}
+
+// slackEnterpriseClient calls the auth.teams.list and users.list methods with an org-level token
+type slackEnterpriseClient struct {
+	client   *slack.Client
+	pageSize int
+	maxPages int
+}
+
+// Workspaces lists the workspaces of the organization, page by page, up to the maximum number of pages
+func (c *slackEnterpriseClient) Workspaces(ctx context.Context) ([]string, bool, error) {
+	var workspaces []string
+	cursor := ""
+
+	for page := 0; page < c.maxPages; page++ {
+		teams, next, err := c.client.ListTeamsContext(ctx, slack.ListTeamsParameters{Limit: c.pageSize, Cursor: cursor})
+		if err != nil {
+			return nil, false, fmt.Errorf("listing the workspaces of the organization failed: %w", err)
+		}
+		for _, team := range teams {
+			workspaces = append(workspaces, team.ID)
+		}
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+
+	// The loop only ends with a cursor when the maximum number of pages is reached
+	return workspaces, cursor != "", nil
+}
+
+// WorkspaceUsers lists the users of the workspace, page by page, up to the maximum number of pages
+func (c *slackEnterpriseClient) WorkspaceUsers(ctx context.Context, teamID string) ([]slack.User, bool, error) {
+	var users []slack.User
+	pagination := c.client.GetUsersPaginated(slack.GetUsersOptionLimit(c.pageSize), slack.GetUsersOptionTeamID(teamID))
+
+	for page := 0; ; page++ {
+		// Stop at the maximum number of pages, checking whether the workspace has more users
+		if page >= c.maxPages {
+			next, err := pagination.Next(ctx)
+			return users, !next.Done(err) && next.Failure(err) == nil, nil
+		}
+
+		var err error
+		pagination, err = pagination.Next(ctx)
+		if pagination.Done(err) {
+			break
+		}
+		if err = pagination.Failure(err); err != nil {
+			return nil, false, fmt.Errorf("fetching the users of workspace %s failed: %w", teamID, err)
+		}
+		users = append(users, pagination.Users...)
+	}
+
+	return users, false, nil
+}
+
+// WithEnterprise lists the users of all the workspaces of an Enterprise Grid organization instead of those of a
+// single workspace. It requires an org-level token, users member of several workspaces are only listed once
+func WithEnterprise(enabled bool) Option {
+	return func(s *SlackTool) {
+		s.orgWide = enabled
+	}
+}
+
+// searchAMAEmployeesUsingEnterpriseAPI lists the users of every workspace of the organization, merged by user ID
+// Bots are skipped unless includeBots is true. The actual deactivation dates are read for every workspace
+func (s *SlackTool) searchAMAEmployeesUsingEnterpriseAPI(ctx context.Context, twoFactorVisible, includeBots bool) ([]model.EmployeeInfo, error) {
+	workspaces, truncated, err := s.enterprise.Workspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		s.logf("⚠️ Reached the maximum of %d pages of %d workspaces before the last page: the users of the other workspaces "+
+			"are missing from the results (increase the maximum number of pages or the page size)\n", s.maxPages, s.pageSize)
+	}
+	s.loadActualDeactivationDates(ctx, workspaces...)
+
+	spinner := s.startSpinner("🏢 Fetching users of the organization workspaces...")
+
+	employees := []model.EmployeeInfo{}
+	seen := make(map[string]bool)
+	totalUsers := 0
+	var truncatedWorkspaces []string
+
+	for _, teamID := range workspaces {
+		users, truncated, err := s.enterprise.WorkspaceUsers(ctx, teamID)
+		if err != nil {
+			misc.StopSpinner(spinner)
+			return nil, err
+		}
+		if truncated {
+			truncatedWorkspaces = append(truncatedWorkspaces, teamID)
+		}
+		totalUsers += len(users)
+
+		for _, user := range users {
+			// Members of several workspaces are listed by each of them
+			if seen[user.ID] || (user.IsBot && !includeBots) {
+				continue
+			}
+			seen[user.ID] = true
+			s.processUser(&employees, user, twoFactorVisible)
+		}
+	}
+
+	misc.StopSpinner(spinner)
+	if len(truncatedWorkspaces) > 0 {
+		s.logf("⚠️ Reached the maximum of %d pages of %d users before the last page of workspaces %s: users may be missing "+
+			"from the results (increase the maximum number of pages or the page size)\n", s.maxPages, s.pageSize, strings.Join(truncatedWorkspaces, ", "))
+	}
+	s.logf("✅ Completed fetching users of %d workspaces (total: %d users, %d distinct)\n", len(workspaces), totalUsers, len(seen))
+	return employees, nil
+}
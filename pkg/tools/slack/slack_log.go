@@ -0,0 +1,22 @@
+package slack
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithLogOutput sets where the progress messages of the Slack fetches are written (e.g. "💾 Saved 42 employees"),
+// the standard output by default. A nil writer suppresses them, e.g. in quiet mode
+func WithLogOutput(w io.Writer) Option {
+	return func(s *SlackTool) {
+		s.logOutput = w
+		if w == nil {
+			s.logOutput = io.Discard
+		}
+	}
+}
+
+// logf writes a progress message to the log output
+func (s *SlackTool) logf(format string, args ...any) {
+	fmt.Fprintf(s.logOutput, format, args...)
+}
@@ -0,0 +1,69 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/misc"
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// presenceLatencyWarningThreshold is the number of users above which the latency of the presence fetch is warned about
+const presenceLatencyWarningThreshold = 100
+
+// presencePattern matches the inputs asking for the presence of the users, e.g. "who is online", "away" or
+// "with presence"
+var presencePattern = regexp.MustCompile(`\b(?:` + strings.Join(model.PresenceWords, "|") + `|presence)\b`)
+
+// presenceClient reads the presence of a user, e.g. *slack.Client
+type presenceClient interface {
+	GetUserPresenceContext(ctx context.Context, user string) (*slack.UserPresence, error)
+}
+
+// isPresenceQuery determines if the lowercased input asks for the presence of the users
+func isPresenceQuery(input string) bool {
+	return presencePattern.MatchString(input)
+}
+
+// loadPresence sets the presence ("active" or "away") of the active employees, with one Slack call per employee
+// The presence of an employee is left empty when Slack fails to return it. The fetch stops when the context is done
+func (s *SlackTool) loadPresence(ctx context.Context, employees []model.EmployeeInfo) error {
+	var pending []int
+	for i, emp := range employees {
+		if !emp.Deactivated && emp.SlackID != "" {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if len(pending) > presenceLatencyWarningThreshold {
+		s.logf("⚠️ Fetching the presence of %d active users, one Slack call per user: this may take a while\n", len(pending))
+	}
+
+	spinner := s.startSpinner("🟢 Fetching users presence...")
+	failed := 0
+	for _, i := range pending {
+		if ctx.Err() != nil {
+			misc.StopSpinner(spinner)
+			return fmt.Errorf("fetching users presence interrupted: %w", ctx.Err())
+		}
+
+		presence, err := s.presence.GetUserPresenceContext(ctx, employees[i].SlackID)
+		if err != nil {
+			failed++
+			continue
+		}
+		employees[i].Presence = presence.Presence
+	}
+	misc.StopSpinner(spinner)
+
+	if failed > 0 {
+		s.logf("⚠️ Presence unknown for %d of %d active users (Slack errors)\n", failed, len(pending))
+	}
+	return nil
+}
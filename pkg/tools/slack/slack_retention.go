@@ -0,0 +1,100 @@
+package slack
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// DefaultRetention is the default age after which the employees data files are removed
+const DefaultRetention = 24 * time.Hour
+
+// dataFilePattern matches the names of the data files written by the Slack tool, other files are never removed
+var dataFilePattern = regexp.MustCompile(`^employees-(all|active|deactivated)-\d{8}-\d{6}(` + botsSuffix + `)?\.json$`)
+
+// WithRetention sets the age after which the data files are removed, when a new data file is written
+// A retention of 0 keeps the data files whatever their age (e.g. to keep the history of the headcount trend)
+func WithRetention(retention time.Duration) Option {
+	return func(s *SlackTool) {
+		s.retention = retention
+	}
+}
+
+// WithMaxDataFiles keeps only the n most recent data files of each filter type, when a new data file is written
+// A maximum of 0 keeps them all
+func WithMaxDataFiles(n int) Option {
+	return func(s *SlackTool) {
+		s.maxDataFiles = max(n, 0)
+	}
+}
+
+// cleanupDataFiles removes the data files older than the retention and those beyond the maximum number per filter
+// type. The file just written (keep) is never removed, errors are reported as warnings only
+func (s *SlackTool) cleanupDataFiles(keep string) {
+	if s.retention <= 0 && s.maxDataFiles <= 0 {
+		return
+	}
+
+	expired, err := expiredDataFiles(s.dataDir, s.retention, s.maxDataFiles, time.Now())
+	if err != nil {
+		s.logf("⚠️ Could not clean up old data files: %v\n", err)
+		return
+	}
+
+	removed := 0
+	for _, filePath := range expired {
+		if filePath == keep {
+			continue
+		}
+		if err := os.Remove(filePath); err != nil {
+			s.logf("⚠️ Could not remove old data file %s: %v\n", filepath.Base(filePath), err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		s.logf("🧹 Removed %d old data files\n", removed)
+	}
+}
+
+// expiredDataFiles returns the data files of the directory to remove: those last modified more than retention ago,
+// and those beyond the maxFiles most recent ones of their filter type (0 disabling either rule)
+func expiredDataFiles(dataDir string, retention time.Duration, maxFiles int, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the data files by filter type, those holding bots apart, the most recent first (file names are timestamped)
+	byFilter := make(map[string][]string)
+	for _, entry := range entries {
+		match := dataFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		byFilter[match[1]+match[2]] = append(byFilter[match[1]+match[2]], entry.Name())
+	}
+
+	var expired []string
+	for _, names := range byFilter {
+		sort.Sort(sort.Reverse(sort.StringSlice(names)))
+		for i, name := range names {
+			filePath := filepath.Join(dataDir, name)
+			if maxFiles > 0 && i >= maxFiles {
+				expired = append(expired, filePath)
+				continue
+			}
+			if retention <= 0 {
+				continue
+			}
+			if info, err := os.Stat(filePath); err == nil && now.Sub(info.ModTime()) > retention {
+				expired = append(expired, filePath)
+			}
+		}
+	}
+
+	sort.Strings(expired)
+	return expired, nil
+}
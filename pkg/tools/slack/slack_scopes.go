@@ -0,0 +1,85 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RequiredScopes are the scopes the Slack token needs to list the users and read their emails
+var RequiredScopes = []string{"users:read", "users:read.email"}
+
+// ErrMissingScopes is returned when the Slack token lacks some of the RequiredScopes
+var ErrMissingScopes = errors.New("the Slack token lacks required scopes")
+
+// authTestResponse is the response of the auth.test method
+type authTestResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// grantedScopes calls the auth.test method and returns the scopes of the token, read from the X-OAuth-Scopes header
+// The scopes are nil when Slack does not return the header (e.g. through a proxy or with some token types)
+func (s *SlackTool) grantedScopes(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL()+"auth.test", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.visibility.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack authentication failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response authTestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid auth.test response: %v", err)
+	}
+	if !response.OK {
+		return nil, fmt.Errorf("slack authentication failed: %s", response.Error)
+	}
+
+	header, found := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !found {
+		return nil, nil
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// CheckScopes verifies that the Slack token has the RequiredScopes, so that a misconfigured token fails at startup
+// rather than with empty results. It returns the scopes of the token, and an error wrapping ErrMissingScopes
+// listing the missing ones. The check passes when Slack does not report the scopes of the token
+func (s *SlackTool) CheckScopes(ctx context.Context) ([]string, error) {
+	scopes, err := s.grantedScopes(ctx)
+	if err != nil || scopes == nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range RequiredScopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return scopes, fmt.Errorf("%w: %s", ErrMissingScopes, strings.Join(missing, ", "))
+	}
+	return scopes, nil
+}
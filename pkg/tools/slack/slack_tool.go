@@ -6,12 +6,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/tmc/langchaingo/callbacks"
+
+	"github.com/asaintsever/ama-employees-ai-agent/pkg/model"
+)
+
+// botsSuffix ends the names of the data files holding bots, before their extension
+const botsSuffix = "-bots"
+
+// Permissions of the data directory and of the data files, readable by their owner only as they hold employees PII
+const (
+	dataDirPerm  = 0700
+	dataFilePerm = 0600
 )
 
+// RestrictedVisibilityNote is added to the tool output when Slack reported users not visible to the token
+const RestrictedVisibilityNote = "Note: Slack reported users not visible to this token (info barriers or enterprise settings), results may exclude them."
+
 // SlackAMAEmployeesTool implements the langchaingo Tool interface
 type SlackAMAEmployeesTool struct {
 	CallbacksHandler callbacks.Handler
@@ -19,12 +36,17 @@ type SlackAMAEmployeesTool struct {
 }
 
 // NewSlackAMAEmployeesTool creates a new instance of SlackAMAEmployeesTool
-func NewSlackAMAEmployeesTool(token string) *SlackAMAEmployeesTool {
+func NewSlackAMAEmployeesTool(token string, opts ...Option) *SlackAMAEmployeesTool {
 	return &SlackAMAEmployeesTool{
-		slackTool: NewSlackTool(token),
+		slackTool: NewSlackTool(token, opts...),
 	}
 }
 
+// CheckScopes verifies that the Slack token has the RequiredScopes (see SlackTool.CheckScopes)
+func (t *SlackAMAEmployeesTool) CheckScopes(ctx context.Context) ([]string, error) {
+	return t.slackTool.CheckScopes(ctx)
+}
+
 // Name returns the name of the tool
 func (t *SlackAMAEmployeesTool) Name() string {
 	return "SearchAMAEmployees"
@@ -38,26 +60,52 @@ The input to this tool should specify which type of employees you want to retrie
 - For all employees, use "all" or leave input empty
 - For active employees only, include the word "active" in your input
 - For deactivated/terminated/deleted employees only, include the word "deactivated" in your input
+- For a breakdown of active and deactivated employees, include the word "breakdown" in your input: all employees
+  are fetched once and the counts of active and deactivated employees follow the file path
+- To fetch the employees from Slack again instead of reusing a recent file, include the words "force refresh" in your input
+  (only when the user asks for up-to-date data)
+- To also fetch the bot and service accounts, excluded by default, include the words "include bots" or "with bots" in
+  your input (only when the user asks for them, e.g. to audit service accounts). Bots have "is_bot": true in the file
+- To know who is currently online, away or offline, include the words "online", "away", "offline" or "with presence"
+  in your input: the presence of the active employees ("active" or "away") is fetched too, with one call per employee
+  (only when the user asks for it)
 
 The tool returns a file path to a JSON file containing the employee data.
+The file path may be followed by a note when some users are not visible to the token, mention it with the results,
+or by a note when the file of a recent search is reused.
 
-The JSON file contains an array of employee objects with the following structure:
+The JSON file contains an array of employee objects with the following structure
+(has_2fa is only present when the token is allowed to see two-factor status, deactivated_date_source tells
+whether the deactivation date is "estimated" from the last profile update or "verified", department is only
+present when the custom profile field holding it is configured, phone, timezone and status_text are only present
+when set in Slack, middle_name is only present when the full name has middle names, presence is only present
+when asked for):
 
 [
     {
+        "slack_id": "U012ABC",
         "first_name": "John",
+        "middle_name": "Ronald",
         "last_name": "Doe",
 		"email": "john.doe@example.com",
 		"deactivated": true,
         "deactivated_date": "2021-01-01",
-        "title": "Software Engineer"
+        "deactivated_date_source": "estimated",
+        "title": "Software Engineer",
+        "department": "Engineering",
+        "phone": "+1 555 0100",
+        "timezone": "America/New_York",
+        "status_text": "In a meeting",
+        "has_2fa": false
     },
 	{
+        "slack_id": "U034DEF",
         "first_name": "Jane",
         "last_name": "Doe",
 		"email": "jane.doe@example.com",
 		"deactivated": false,
-        "title": "Marketing Manager"
+        "title": "Marketing Manager",
+        "presence": "active"
     }
 ]
 `
@@ -87,32 +135,165 @@ func (t *SlackAMAEmployeesTool) Call(ctx context.Context, input string) (string,
 	// Convert input to lowercase for case-insensitive comparison
 	inputLower := strings.ToLower(input)
 
+	// A status breakdown needs both subsets, derived from a single fetch of all employees
+	breakdown := isStatusBreakdown(inputLower)
+
 	// Check if input contains specific filter keywords
-	if strings.Contains(inputLower, "active") && !strings.Contains(inputLower, "deactivated") {
+	if breakdown {
+		filter = FilterAll
+	} else if strings.Contains(inputLower, "active") && !strings.Contains(inputLower, "deactivated") {
 		filter = FilterActive
 	} else if strings.Contains(inputLower, "deactivated") {
 		filter = FilterDeactivated
 	}
 
+	// Bots are only fetched when explicitly asked for
+	includeBots := isIncludeBots(inputLower)
+
+	// The presence of the users is only fetched when explicitly asked for, as it takes one call per user
+	withPresence := isPresenceQuery(inputLower)
+
+	// Neither the cache nor Slack are used in dry-run mode
+	if t.slackTool.dryRun {
+		output, err = t.dryRunResult(filter, includeBots, withPresence)
+		if err != nil {
+			output = fmt.Sprintf("Error: %v", err)
+		}
+		return output, err
+	}
+
+	// Reuse the data file of a recent search with the same filter, unless a refresh, the bots or the presence are asked for
+	if !isForceRefresh(inputLower) && !includeBots && !withPresence {
+		if filePath, age, ok := t.cachedDataFile(filter); ok {
+			result, err := t.cachedResult(filePath, age, breakdown)
+			if err == nil {
+				output = result
+				return result, nil
+			}
+			t.slackTool.logf("⚠️ Cannot reuse data file %s: %v\n", filePath, err)
+		}
+	}
+
 	// Search for employees information with the determined filter
-	employees, err := t.slackTool.SearchAMAEmployees(filter)
+	employees, err := t.slackTool.searchEmployees(ctx, filter, includeBots)
 	if err != nil {
 		output = fmt.Sprintf("Error: %v", err)
-		return output, fmt.Errorf("error searching for employees information: %v", err)
+		return output, fmt.Errorf("error searching for employees information: %w", err)
+	}
+
+	if withPresence {
+		if err := t.slackTool.loadPresence(ctx, employees); err != nil {
+			output = fmt.Sprintf("Error: %v", err)
+			return output, fmt.Errorf("error fetching users presence: %w", err)
+		}
+	}
+
+	// Save the employees data to a file
+	filePath, err := t.saveEmployees(employees, filter)
+	if err != nil {
+		output = fmt.Sprintf("Error: %v", err)
+		return output, err
+	}
+
+	output = fmt.Sprintf("Saved %d employees to file: %s", len(employees), filePath)
+
+	result := filePath
+	if breakdown {
+		active, deactivated := PartitionEmployees(employees)
+		result += fmt.Sprintf("\n\nStatus breakdown: %d active, %d deactivated (%d in total)", len(active), len(deactivated), len(employees))
+	}
+
+	// Warn that counts may be skewed by users hidden from the token (info barriers, enterprise settings)
+	if t.slackTool.RestrictedVisibility() {
+		result += "\n\n" + RestrictedVisibilityNote
+	}
+
+	return result, nil
+}
+
+// cachedDataFile returns the most recent data file of the filter and its age, if younger than the cache TTL
+func (t *SlackAMAEmployeesTool) cachedDataFile(filter FilterType) (string, time.Duration, bool) {
+	if t.slackTool.cacheTTL <= 0 {
+		return "", 0, false
+	}
+
+	filePath, ok := latestDataFile(t.slackTool.dataDir, filter)
+	if !ok {
+		return "", 0, false
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", 0, false
+	}
+
+	age := time.Since(info.ModTime())
+	if age >= t.slackTool.cacheTTL {
+		return "", 0, false
+	}
+	return filePath, age, true
+}
+
+// cachedResult returns the tool result for a reused data file, with the status breakdown if asked for
+func (t *SlackAMAEmployeesTool) cachedResult(filePath string, age time.Duration, breakdown bool) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	var employees []model.EmployeeInfo
+	if err := json.Unmarshal(data, &employees); err != nil {
+		return "", err
 	}
 
+	returnedPath := t.returnedPath(filePath)
+	t.slackTool.logf("♻️ Reusing %d employees from file: %s (%s old)\n", len(employees), returnedPath, age.Round(time.Second))
+
+	result := returnedPath
+	if breakdown {
+		active, deactivated := PartitionEmployees(employees)
+		result += fmt.Sprintf("\n\nStatus breakdown: %d active, %d deactivated (%d in total)", len(active), len(deactivated), len(employees))
+	}
+	result += fmt.Sprintf("\n\nNote: data fetched from Slack %s ago, include \"force refresh\" in the input to fetch it again.", age.Round(time.Minute))
+
+	return result, nil
+}
+
+// isStatusBreakdown determines if the lowercased input asks for both the active and the deactivated employees
+func isStatusBreakdown(input string) bool {
+	return strings.Contains(input, "breakdown") || strings.Contains(input, "by status")
+}
+
+// forceRefreshPattern matches the inputs asking to fetch the employees from Slack again, e.g. "force refresh" or
+// "refreshed data", but not the words merely containing it (e.g. "refreshment")
+var forceRefreshPattern = regexp.MustCompile(`\b(?:force[\s-]?)?refresh(?:ed|ing)?\b`)
+
+// isForceRefresh determines if the lowercased input asks for fresh data rather than a recent data file
+func isForceRefresh(input string) bool {
+	return forceRefreshPattern.MatchString(input)
+}
+
+// includeBotsPattern matches the inputs asking for the bot accounts too, e.g. "include bots" or "with bots"
+var includeBotsPattern = regexp.MustCompile(`\b(?:include|including|with) (?:the )?bots?\b`)
+
+// isIncludeBots determines if the lowercased input asks for the bot accounts too
+func isIncludeBots(input string) bool {
+	return includeBotsPattern.MatchString(input)
+}
+
+// saveEmployees writes the employees to a timestamped JSON file in the data directory
+// Files holding bots are suffixed with "-bots", so that they are never reused for searches without bots
+// It returns the path of the file, relative to the working directory when path redaction is enabled
+func (t *SlackAMAEmployeesTool) saveEmployees(employees []model.EmployeeInfo, filter FilterType) (string, error) {
 	// Convert the employees to JSON for writing to file
 	employeesJSON, err := json.Marshal(employees)
 	if err != nil {
-		output = fmt.Sprintf("Error: %v", err)
-		return output, fmt.Errorf("error marshalling employees data: %v", err)
+		return "", fmt.Errorf("error marshalling employees data: %v", err)
 	}
 
 	// Create data directory if it doesn't exist
-	dataDir := "data"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		output = fmt.Sprintf("Error creating data directory: %v", err)
-		return output, fmt.Errorf("error creating data directory: %v", err)
+	if err := os.MkdirAll(t.slackTool.dataDir, dataDirPerm); err != nil {
+		return "", fmt.Errorf("error creating data directory: %v", err)
 	}
 
 	// Create a timestamped filename to avoid overwrites
@@ -126,23 +307,81 @@ func (t *SlackAMAEmployeesTool) Call(ctx context.Context, input string) (string,
 	}
 
 	fileName := fmt.Sprintf("employees-%s-%s.json", filterType, timestamp)
-	filePath := filepath.Join(dataDir, fileName)
+	if slices.ContainsFunc(employees, func(emp model.EmployeeInfo) bool { return emp.IsBot }) {
+		fileName = fmt.Sprintf("employees-%s-%s%s.json", filterType, timestamp, botsSuffix)
+	}
+	filePath := filepath.Join(t.slackTool.dataDir, fileName)
 
 	// Write the JSON data to the file
-	if err := os.WriteFile(filePath, employeesJSON, 0644); err != nil {
-		output = fmt.Sprintf("Error writing employees data to file: %v", err)
-		return output, fmt.Errorf("error writing employees data to file: %v", err)
+	if err := os.WriteFile(filePath, employeesJSON, dataFilePerm); err != nil {
+		return "", fmt.Errorf("error writing employees data to file: %v", err)
 	}
 
+	// Remove the previous data files that are no longer needed
+	t.slackTool.cleanupDataFiles(filePath)
+
+	returnedPath := t.returnedPath(filePath)
+
+	t.slackTool.logf("💾 Saved %d employees to file: %s\n", len(employees), returnedPath)
+
+	return returnedPath, nil
+}
+
+// returnedPath returns the absolute path of a data file, relative to the working directory when path redaction is enabled
+func (t *SlackAMAEmployeesTool) returnedPath(filePath string) string {
 	// Get absolute path for better clarity
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
 		absPath = filePath // Fall back to relative path if absolute fails
 	}
 
-	employeeCount := len(employees)
-	output = fmt.Sprintf("Saved %d employees to file: %s", employeeCount, absPath)
-	fmt.Printf("💾 Saved %d employees to file: %s\n", employeeCount, absPath)
+	// Avoid leaking the directory structure (e.g. user names) when redaction is enabled
+	if t.slackTool.pathRedaction {
+		return redactPath(absPath)
+	}
+	return absPath
+}
+
+// redactPath returns the path relative to the working directory, or only the file name if it is outside of it
+func redactPath(absPath string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if relPath, err := filepath.Rel(wd, absPath); err == nil && !strings.HasPrefix(relPath, "..") {
+			return relPath
+		}
+	}
+	return filepath.Base(absPath)
+}
+
+// latestDataFile returns the most recent data file of the filter in the data directory, files holding bots excluded
+// Data file names are timestamped, so the most recent one is the last in lexical order
+func latestDataFile(dataDir string, filter FilterType) (string, bool) {
+	files, err := filepath.Glob(filepath.Join(dataDir, fmt.Sprintf("employees-%s-*.json", filter)))
+	if err != nil {
+		return "", false
+	}
+	files = slices.DeleteFunc(files, func(file string) bool { return strings.HasSuffix(file, botsSuffix+".json") })
+	if len(files) == 0 {
+		return "", false
+	}
+	sort.Strings(files)
+	return files[len(files)-1], true
+}
+
+// estimateUserCount returns the number of employees in the most recent data file of all employees, 0 if there is none
+func estimateUserCount(dataDir string) int {
+	filePath, ok := latestDataFile(dataDir, FilterAll)
+	if !ok {
+		return 0
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0
+	}
 
-	return absPath, nil
+	var employees []model.EmployeeInfo
+	if err := json.Unmarshal(data, &employees); err != nil {
+		return 0
+	}
+	return len(employees)
 }
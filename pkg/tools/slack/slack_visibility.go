@@ -0,0 +1,92 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// userNotVisible is reported by Slack when info barriers or enterprise settings hide users from the token
+const userNotVisible = "user_not_visible"
+
+// visibilityRecorder is the HTTP client of the Slack API client
+// It records whether Slack reported users that are not visible to the token, as they are silently omitted from the lists
+type visibilityRecorder struct {
+	client *http.Client
+
+	mu         sync.Mutex
+	restricted bool
+}
+
+// newVisibilityRecorder creates a recorder sending the requests with the default HTTP client
+func newVisibilityRecorder() *visibilityRecorder {
+	return &visibilityRecorder{client: http.DefaultClient}
+}
+
+// Do sends the request and inspects the response for visibility restrictions
+func (r *visibilityRecorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if reportsUserNotVisible(body) {
+		r.mu.Lock()
+		r.restricted = true
+		r.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// Reset forgets the restrictions recorded so far
+func (r *visibilityRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.restricted = false
+}
+
+// Restricted returns whether Slack reported users not visible to the token since the last reset
+func (r *visibilityRecorder) Restricted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.restricted
+}
+
+// reportsUserNotVisible determines if a Slack API response reports users not visible to the token,
+// either as an error, a warning or a response metadata warning
+func reportsUserNotVisible(body []byte) bool {
+	var response struct {
+		Error            string `json:"error"`
+		Warning          string `json:"warning"`
+		ResponseMetadata struct {
+			Warnings []string `json:"warnings"`
+		} `json:"response_metadata"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false
+	}
+
+	if response.Error == userNotVisible || strings.Contains(response.Warning, userNotVisible) {
+		return true
+	}
+
+	for _, warning := range response.ResponseMetadata.Warnings {
+		if warning == userNotVisible {
+			return true
+		}
+	}
+
+	return false
+}
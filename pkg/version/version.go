@@ -0,0 +1,20 @@
+package version
+
+import "fmt"
+
+// Build metadata, stamped at build time with -ldflags (see the build target of the Makefile), e.g.
+//
+//	go build -ldflags "-X github.com/asaintsever/ama-employees-ai-agent/pkg/version.Version=v1.2.0" ./cmd/agent
+var (
+	// Version is the version of the agent
+	Version = "dev"
+	// Commit is the git commit the agent was built from
+	Commit = "unknown"
+	// Date is the build date
+	Date = "unknown"
+)
+
+// String returns the build metadata on a single plain line, e.g. "version=v1.2.0 commit=4b3eff4 date=2024-05-01T10:30:00Z"
+func String() string {
+	return fmt.Sprintf("version=%s commit=%s date=%s", Version, Commit, Date)
+}
@@ -0,0 +1,18 @@
+package version
+
+import "testing"
+
+func TestString(t *testing.T) {
+	if got := String(); got != "version=dev commit=unknown date=unknown" {
+		t.Errorf("Expected the default build metadata, got %q", got)
+	}
+
+	defer func(version, commit, date string) {
+		Version, Commit, Date = version, commit, date
+	}(Version, Commit, Date)
+
+	Version, Commit, Date = "v1.2.0", "4b3eff4", "2024-05-01T10:30:00Z"
+	if got := String(); got != "version=v1.2.0 commit=4b3eff4 date=2024-05-01T10:30:00Z" {
+		t.Errorf("Expected the stamped build metadata, got %q", got)
+	}
+}